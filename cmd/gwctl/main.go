@@ -6,7 +6,10 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"path"
+	"sort"
+	"time"
 
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
@@ -17,6 +20,10 @@ import (
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	"github.com/gauravkghildiyal/gwctl/pkg/cmd"
+	"github.com/gauravkghildiyal/gwctl/pkg/config"
+	"github.com/gauravkghildiyal/gwctl/pkg/defaults"
+	"github.com/gauravkghildiyal/gwctl/pkg/fetch"
+	"github.com/gauravkghildiyal/gwctl/pkg/gatewayapi"
 	"github.com/gauravkghildiyal/gwctl/pkg/policymanager"
 	"github.com/gauravkghildiyal/gwctl/pkg/types"
 	"github.com/spf13/cobra"
@@ -33,40 +40,155 @@ func main() {
 		kubeconfig = path.Join(os.Getenv("HOME"), ".kube/config")
 	}
 
-	restConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	cfg, err := config.Load(config.DefaultPath())
 	if err != nil {
-		panic(fmt.Sprintf("Failed to get restConfig from BuildConfigFromFlags: %v", err))
-	}
-
-	client, err := client.New(restConfig, client.Options{})
-	if err != nil {
-		panic(fmt.Sprintf("Error initializing Kubernetes client: %v", err))
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
 	}
-	gatewayv1alpha2.AddToScheme(client.Scheme())
-	gatewayv1beta1.AddToScheme(client.Scheme())
-
-	dc := dynamic.NewForConfigOrDie(restConfig)
 
-	policyManager := policymanager.New(dc)
-	if err := policyManager.Init(context.Background()); err != nil {
-		panic(err)
-	}
+	loader := &kubeconfigLoader{kubeconfig: kubeconfig, cfg: cfg}
 
+	// The clients of the current context are only created once the command is
+	// known, since commands running across clusters do not need them.
 	params := &types.Params{
-		Client:          client,
-		DC:              dc,
-		DiscoveryClient: discovery.NewDiscoveryClientForConfigOrDie(restConfig),
-		PolicyManager:   policyManager,
-		Out:             os.Stdout,
+		Out:      os.Stdout,
+		Config:   cfg,
+		Clusters: loader,
 	}
 
+	var requestTimeout time.Duration
 	rootCmd := &cobra.Command{
 		Use: "gwctl",
+		PersistentPreRun: func(c *cobra.Command, args []string) {
+			loader.timeout = requestTimeout
+			// Requests for completions by the shell bound every request to
+			// the cluster, including discovery.
+			if cmd.IsCompletionRequest(c) {
+				loader.timeout = cmd.CompletionTimeout
+			}
+			if cmd.RunsAcrossClusters(c) || c.Annotations[cmd.SkipClusterSetupAnnotation] == "true" {
+				return
+			}
+			current, err := loader.newParams("")
+			if err != nil {
+				panic(err)
+			}
+			*params = *current
+			if c.Annotations[cmd.SkipPolicyManagerInitAnnotation] == "true" || cmd.IsCompletionRequest(c) {
+				return
+			}
+			if err := params.PolicyManager.Init(c.Context()); err != nil {
+				panic(err)
+			}
+			if err := cmd.ApplyConfig(params); err != nil {
+				panic(err)
+			}
+		},
 	}
+	rootCmd.PersistentFlags().DurationVar(&requestTimeout, "request-timeout", 0, "The maximum duration of each request to the cluster, like 10s. Zero means no timeout.")
 	rootCmd.AddCommand(cmd.NewGetCommand(params))
 	rootCmd.AddCommand(cmd.NewDescribeCommand(params))
+	rootCmd.AddCommand(cmd.NewVersionCommand(params))
+	rootCmd.AddCommand(cmd.NewDoctorCommand(params))
+	rootCmd.AddCommand(cmd.NewValidateCommand(params))
+	rootCmd.AddCommand(cmd.NewWhatIfCommand(params))
+	rootCmd.AddCommand(cmd.NewSnapshotCommand(params))
+	rootCmd.AddCommand(cmd.NewDiffCommand(params))
+	rootCmd.AddCommand(cmd.NewDriftCommand(params))
+	rootCmd.AddCommand(cmd.NewCompletionCommand(params))
+	// The completion command above replaces the default one of cobra.
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
 
-	if err := rootCmd.Execute(); err != nil {
+	// Interrupting gwctl cancels the requests in flight.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		os.Exit(1)
 	}
 }
+
+// kubeconfigLoader creates the Params of the contexts of the kubeconfig.
+type kubeconfigLoader struct {
+	kubeconfig string
+	cfg        config.Config
+	// timeout bounds every request to the clusters, unless zero.
+	timeout time.Duration
+}
+
+func (l *kubeconfigLoader) Contexts() ([]string, error) {
+	kubeconfig, err := clientcmd.LoadFromFile(l.kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for name := range kubeconfig.Contexts {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func (l *kubeconfigLoader) Load(ctx context.Context, name string) (*types.Params, error) {
+	params, err := l.newParams(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := params.PolicyManager.Init(ctx); err != nil {
+		return nil, err
+	}
+	if err := cmd.ApplyConfig(params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// newParams creates the Params of the context, or of the current context if
+// name is empty, without initializing the PolicyManager.
+func (l *kubeconfigLoader) newParams(name string) (*types.Params, error) {
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: l.kubeconfig},
+		&clientcmd.ConfigOverrides{CurrentContext: name},
+	).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restConfig from kubeconfig: %v", err)
+	}
+	restConfig.Timeout = l.timeout
+
+	client, err := client.New(restConfig, client.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("error initializing Kubernetes client: %v", err)
+	}
+	gatewayv1alpha2.AddToScheme(client.Scheme())
+	gatewayv1beta1.AddToScheme(client.Scheme())
+
+	dc, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// The cluster may serve the Gateway API resources in versions other than
+	// the one used by the typed client, in which case they are read through the
+	// dynamic client instead.
+	servedVersions, err := gatewayapi.DiscoverServedVersions(discoveryClient)
+	if err != nil {
+		return nil, err
+	}
+
+	c := fetch.NewClient(defaults.NewClient(gatewayapi.NewClient(client, dc, servedVersions)))
+	policyManager := policymanager.New(dc, c)
+	l.cfg.RegisterPolicyCRDs(policyManager)
+
+	return &types.Params{
+		Client:          c,
+		DC:              dc,
+		DiscoveryClient: discoveryClient,
+		PolicyManager:   policyManager,
+		Out:             os.Stdout,
+		Config:          l.cfg,
+		Clusters:        l,
+	}, nil
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+	"github.com/gauravkghildiyal/gwctl/pkg/multicluster"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+)
+
+// clusterFlags are the flags of the commands which run across the clusters of
+// multiple kubeconfig contexts.
+type clusterFlags struct {
+	contexts    []string
+	allContexts bool
+}
+
+func (f *clusterFlags) addFlags(cmd *cobra.Command, params *types.Params) {
+	cmd.Flags().StringSliceVar(&f.contexts, "contexts", nil, "Run across the clusters of these kubeconfig contexts, tagging the output with the CLUSTER.")
+	cmd.Flags().BoolVar(&f.allContexts, "all-contexts", false, "If present, run across the clusters of all kubeconfig contexts.")
+	cmd.RegisterFlagCompletionFunc("contexts", completeContexts(params))
+}
+
+func (f *clusterFlags) enabled() bool {
+	return len(f.contexts) != 0 || f.allContexts
+}
+
+// RunsAcrossClusters returns whether the command runs across the clusters of
+// other kubeconfig contexts, in which case the clients of the current context
+// are not needed.
+func RunsAcrossClusters(cmd *cobra.Command) bool {
+	contexts, _ := cmd.Flags().GetStringSlice("contexts")
+	allContexts, _ := cmd.Flags().GetBool("all-contexts")
+	return len(contexts) != 0 || allContexts
+}
+
+// loadClusters loads the clusters of the contexts given by the flags, exiting
+// if none of them could be loaded. The errors of the clusters which failed to
+// load are returned, such that the command can still run across the others.
+func loadClusters(ctx context.Context, params *types.Params, flags *clusterFlags) ([]multicluster.Cluster, error) {
+	contexts, err := multicluster.Contexts(params, flags.contexts, flags.allContexts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	clusters, err := multicluster.Load(ctx, params, contexts)
+	if len(clusters) == 0 {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	return clusters, err
+}
+
+// exitIfClusterErrors reports the errors of the clusters once the output of the
+// others has been printed.
+func exitIfClusterErrors(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runGetAcrossClusters prints the table of every cluster as a single table,
+// with the name of the cluster as the first column.
+func runGetAcrossClusters(ctx context.Context, params *types.Params, flags *getFlags, table string, listRows func(context.Context, *types.Params) ([]string, [][]string, error)) {
+	clusters, loadErr := loadClusters(ctx, params, &flags.clusters)
+
+	var mu sync.Mutex
+	var header []string
+	rowsByCluster := make(map[string][][]string)
+	err := multicluster.ForEach(ctx, clusters, func(ctx context.Context, cluster multicluster.Cluster) error {
+		clusterHeader, rows, err := listRows(ctx, cluster.Params)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		header = clusterHeader
+		rowsByCluster[cluster.Name] = rows
+		return nil
+	})
+
+	var rows [][]string
+	for _, cluster := range clusters {
+		for _, row := range rowsByCluster[cluster.Name] {
+			rows = append(rows, append([]string{cluster.Name}, row...))
+		}
+	}
+	// The CLUSTER column is added to the columns of the config file, unless
+	// they already place it.
+	columns := params.Config.TableColumns(table)
+	if len(columns) != 0 && !containsFold(columns, "CLUSTER") {
+		columns = append([]string{"CLUSTER"}, columns...)
+	}
+	if header != nil {
+		if err := common.WriteTable(params.Out, append([]string{"CLUSTER"}, header...), rows, columns); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	}
+	exitIfClusterErrors(errors.Join(loadErr, err))
+}
+
+// runDescribeAcrossClusters describes the resources within every cluster,
+// printing the output of each cluster after the name of the cluster.
+func runDescribeAcrossClusters(ctx context.Context, args []string, params *types.Params, flags *describeFlags) {
+	clusters, loadErr := loadClusters(ctx, params, &flags.clusters)
+
+	outputs := make(map[string]*bytes.Buffer)
+	for _, cluster := range clusters {
+		outputs[cluster.Name] = &bytes.Buffer{}
+	}
+	var mu sync.Mutex
+	failed := make(map[string]bool)
+	err := multicluster.ForEach(ctx, clusters, func(ctx context.Context, cluster multicluster.Cluster) error {
+		clusterParams := *cluster.Params
+		clusterParams.Out = outputs[cluster.Name]
+		clusterParams.Output = params.Output
+		if err := runDescribe(ctx, args, &clusterParams, flags); err != nil {
+			mu.Lock()
+			defer mu.Unlock()
+			failed[cluster.Name] = true
+			return err
+		}
+		return nil
+	})
+
+	// The clusters which failed are left out, and reported once the output
+	// of the others is printed.
+	var printed int
+	for _, cluster := range clusters {
+		if failed[cluster.Name] {
+			continue
+		}
+		if printed > 0 {
+			fmt.Fprintf(params.Out, "\n\n")
+		}
+		fmt.Fprintf(params.Out, "Cluster: %v\n", cluster.Name)
+		fmt.Fprint(params.Out, outputs[cluster.Name].String())
+		printed++
+	}
+	exitIfClusterErrors(errors.Join(loadErr, err))
+}
+
+// completeContexts completes the --contexts flag with the kubeconfig contexts.
+func completeContexts(params *types.Params) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if params.Clusters == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		contexts, err := params.Clusters.Contexts()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return contexts, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/resources/backends"
+	"github.com/gauravkghildiyal/gwctl/pkg/resources/gatewayclasses"
+	"github.com/gauravkghildiyal/gwctl/pkg/resources/gateways"
+	"github.com/gauravkghildiyal/gwctl/pkg/resources/httproutes"
+	"github.com/gauravkghildiyal/gwctl/pkg/resources/namespaces"
+	"github.com/gauravkghildiyal/gwctl/pkg/resources/policies"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+)
+
+// CompletionTimeout bounds the requests to the cluster while completing, such
+// that an unreachable cluster does not block the shell.
+const CompletionTimeout = 2 * time.Second
+
+var (
+	getKinds      = []string{"policies", "policycrds", "httproutes"}
+	describeKinds = []string{"policies", "httproutes", "gateways", "gatewayclasses", "backends", "namespaces"}
+	validateKinds = []string{"policycrds"}
+	targetKinds   = []string{"GatewayClass", "Gateway", "HTTPRoute", "Namespace", "Service"}
+)
+
+func NewCompletionCommand(params *types.Params) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "completion {bash|zsh|fish|powershell}",
+		Short: "Generate the autocompletion script for the specified shell",
+		Long: `Generate the autocompletion script for the specified shell.
+
+The script completes the commands and flags of gwctl, along with the kinds and
+names of resources, which are read from the cluster. For example, to load the
+completions in the current bash session:
+
+  source <(gwctl completion bash)`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
+		// The script is static, so the cluster is not needed.
+		Annotations: map[string]string{
+			SkipClusterSetupAnnotation: "true",
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			runCompletion(cmd.Root(), args, params)
+		},
+	}
+	return cmd
+}
+
+func runCompletion(root *cobra.Command, args []string, params *types.Params) {
+	var err error
+	switch args[0] {
+	case "bash":
+		err = root.GenBashCompletionV2(params.Out, true)
+	case "zsh":
+		err = root.GenZshCompletion(params.Out)
+	case "fish":
+		err = root.GenFishCompletion(params.Out, true)
+	case "powershell":
+		err = root.GenPowerShellCompletionWithDesc(params.Out)
+	default:
+		fmt.Fprintf(os.Stderr, "Unrecognized shell %q\n", args[0])
+		os.Exit(1)
+	}
+	if err != nil {
+		panic(err)
+	}
+}
+
+// IsCompletionRequest returns whether the command is the hidden command through
+// which the shell requests completions. The PolicyManager is initialized by
+// the completion functions which need it, with a short timeout.
+func IsCompletionRequest(cmd *cobra.Command) bool {
+	return cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd
+}
+
+// completeKinds completes the first argument with the kinds, along with the
+// aliases of the config file for any of the kinds.
+func completeKinds(params *types.Params, kinds []string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		result := append([]string{}, kinds...)
+		for alias := range params.Config.Aliases {
+			if contains(kinds, params.Config.ResolveAlias(alias)) {
+				result = append(result, alias)
+			}
+		}
+		sort.Strings(result)
+		return result, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeNamespaces completes the --namespace flag.
+func completeNamespaces(params *types.Params) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		ctx, cancel := context.WithTimeout(cmd.Context(), CompletionTimeout)
+		defer cancel()
+		nsList, err := namespaces.List(ctx, params)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		var result []string
+		for _, ns := range nsList {
+			result = append(result, ns.Name)
+		}
+		return result, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completePolicyKinds completes flags which take the kind of a Policy CRD.
+func completePolicyKinds(params *types.Params) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		ctx, cancel := context.WithTimeout(cmd.Context(), CompletionTimeout)
+		defer cancel()
+		if err := params.PolicyManager.Init(ctx); err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return policyKinds(params), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeTargetKinds completes the --target-kind flag.
+func completeTargetKinds(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return targetKinds, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeResourceNames completes the name of the resource of the kind given
+// as the first argument, within namespace (where "" is all namespaces).
+func completeResourceNames(ctx context.Context, params *types.Params, kind, namespace, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var result []string
+	switch params.Config.ResolveAlias(kind) {
+	case "policy", "policies":
+		if err := params.PolicyManager.Init(ctx); err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		// Policies can also be named as "<kind>/<name>".
+		for _, policy := range (policies.Filter{Namespace: namespace}).Apply(params, params.PolicyManager.GetPolicies()) {
+			name := policy.Unstructured().GetName()
+			result = append(result, name, policy.Unstructured().GetKind()+"/"+name)
+		}
+	case "httproute", "httproutes":
+		httpRoutes, err := httproutes.List(ctx, params, namespace)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		for _, httpRoute := range httpRoutes {
+			result = append(result, httpRoute.Name)
+		}
+	case "gateway", "gateways":
+		gws, err := gateways.List(ctx, params, namespace)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		for _, gw := range gws {
+			result = append(result, gw.Name)
+		}
+	case "gatewayclass", "gatewayclasses":
+		gwClasses, err := gatewayclasses.List(ctx, params)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		for _, gwc := range gwClasses {
+			result = append(result, gwc.Name)
+		}
+	case "namespace", "namespaces", "ns":
+		nsList, err := namespaces.List(ctx, params)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		for _, ns := range nsList {
+			result = append(result, ns.Name)
+		}
+	case "backend", "backends":
+		// Backends are named as "<type>/<name>", where the type defaults to
+		// service.
+		resourceType, prefix := "service", ""
+		if before, _, ok := strings.Cut(toComplete, "/"); ok {
+			resourceType, prefix = before, before+"/"
+		}
+		backendsList, err := backends.List(ctx, params, resourceType, namespace)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		for _, backend := range backendsList {
+			result = append(result, prefix+backend.GetName())
+		}
+	case "policycrd", "policycrds":
+		if err := params.PolicyManager.Init(ctx); err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		for _, policyCRD := range params.PolicyManager.GetCRDs() {
+			result = append(result, policyCRD.CRD().GetName())
+		}
+	}
+	sort.Strings(result)
+	return result, cobra.ShellCompDirectiveNoFileComp
+}
+
+// policyKinds returns the kinds and "<kind>.<group>" of all Policy CRDs.
+func policyKinds(params *types.Params) []string {
+	var result []string
+	for _, policyCRD := range params.PolicyManager.GetCRDs() {
+		result = append(result, policyCRD.CRD().Spec.Names.Kind, string(policyCRD.ID()))
+	}
+	sort.Strings(result)
+	return result
+}
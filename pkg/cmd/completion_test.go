@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+)
+
+func TestCompletion(t *testing.T) {
+	objects := []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1"}},
+		&gatewayv1beta1.GatewayClass{ObjectMeta: metav1.ObjectMeta{Name: "foo-gatewayclass"}},
+		&gatewayv1beta1.Gateway{ObjectMeta: metav1.ObjectMeta{Name: "foo-gateway", Namespace: "default"}},
+		&gatewayv1beta1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Name: "foo-httproute", Namespace: "default"}},
+		&gatewayv1beta1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Name: "bar-httproute", Namespace: "ns1"}},
+
+		&apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "timeoutpolicies.foo.com",
+				Labels: map[string]string{
+					common.GatewayPolicyLabelKey: "direct",
+				},
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Scope:    apiextensionsv1.NamespaceScoped,
+				Group:    "foo.com",
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1"}},
+				Names: apiextensionsv1.CustomResourceDefinitionNames{
+					Plural: "timeoutpolicies",
+					Kind:   "TimeoutPolicy",
+				},
+			},
+		},
+		&unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "foo.com/v1",
+				"kind":       "TimeoutPolicy",
+				"metadata": map[string]interface{}{
+					"name":      "timeout-policy",
+					"namespace": "ns1",
+				},
+				"spec": map[string]interface{}{
+					"targetRef": map[string]interface{}{"kind": "Namespace", "name": "ns1"},
+				},
+			},
+		},
+	}
+	params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
+	params.Config.Aliases = map[string]string{"hr": "httproutes", "crd": "policycrds"}
+
+	testCases := []struct {
+		name      string
+		kind      string
+		namespace string
+		want      []string
+	}{
+		{
+			name:      "httproutes within namespace",
+			kind:      "httproutes",
+			namespace: "default",
+			want:      []string{"foo-httproute"},
+		},
+		{
+			name: "httproutes across namespaces through alias",
+			kind: "hr",
+			want: []string{"bar-httproute", "foo-httproute"},
+		},
+		{
+			name:      "gateways",
+			kind:      "gateways",
+			namespace: "default",
+			want:      []string{"foo-gateway"},
+		},
+		{
+			name: "gatewayclasses",
+			kind: "gatewayclasses",
+			want: []string{"foo-gatewayclass"},
+		},
+		{
+			name: "namespaces",
+			kind: "ns",
+			want: []string{"default", "ns1"},
+		},
+		{
+			name:      "policies by name and kind/name",
+			kind:      "policies",
+			namespace: "ns1",
+			want:      []string{"TimeoutPolicy/timeout-policy", "timeout-policy"},
+		},
+		{
+			name:      "no policies within other namespace",
+			kind:      "policies",
+			namespace: "default",
+		},
+		{
+			name: "policycrds",
+			kind: "crd",
+			want: []string{"timeoutpolicies.foo.com"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, directive := completeResourceNames(context.Background(), params, tc.kind, tc.namespace, "")
+			if directive != cobra.ShellCompDirectiveNoFileComp {
+				t.Errorf("completeResourceNames() returned directive %v; want %v", directive, cobra.ShellCompDirectiveNoFileComp)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("completeResourceNames() returned unexpected diff (-want +got)=\n%v", diff)
+			}
+		})
+	}
+
+	t.Run("kinds with aliases", func(t *testing.T) {
+		got, _ := completeKinds(params, getKinds)(nil, nil, "")
+		want := []string{"crd", "hr", "httproutes", "policies", "policycrds"}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("completeKinds() returned unexpected diff (-want +got)=\n%v", diff)
+		}
+	})
+
+	t.Run("policy kinds", func(t *testing.T) {
+		cmd := &cobra.Command{}
+		cmd.SetContext(context.Background())
+		got, _ := completePolicyKinds(params)(cmd, nil, "")
+		want := []string{"TimeoutPolicy", "TimeoutPolicy.foo.com"}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("completePolicyKinds() returned unexpected diff (-want +got)=\n%v", diff)
+		}
+	})
+}
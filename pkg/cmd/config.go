@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"github.com/gauravkghildiyal/gwctl/pkg/renderers"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+)
+
+// ApplyConfig applies the policy settings of the config file, once the
+// PolicyManager is initialized.
+func ApplyConfig(params *types.Params) error {
+	if err := params.Config.ApplyMergeStrategies(params.PolicyManager); err != nil {
+		return err
+	}
+	for _, kind := range params.Config.GenericRendererKinds() {
+		renderers.Register(kind, renderers.Generic)
+	}
+	return nil
+}
@@ -6,13 +6,16 @@ import (
 	"os"
 	"strings"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
+	"github.com/gauravkghildiyal/gwctl/pkg/policymanager"
 	"github.com/gauravkghildiyal/gwctl/pkg/resources/backends"
 	"github.com/gauravkghildiyal/gwctl/pkg/resources/gatewayclasses"
 	"github.com/gauravkghildiyal/gwctl/pkg/resources/gateways"
 	"github.com/gauravkghildiyal/gwctl/pkg/resources/httproutes"
+	"github.com/gauravkghildiyal/gwctl/pkg/resources/namespaces"
 	"github.com/gauravkghildiyal/gwctl/pkg/resources/policies"
 	"github.com/gauravkghildiyal/gwctl/pkg/types"
 	"github.com/spf13/cobra"
@@ -21,27 +24,64 @@ import (
 type describeFlags struct {
 	namespace     string
 	allNamespaces bool
+	affected      bool
+	clusters      clusterFlags
+	output        outputFlags
 }
 
 func NewDescribeCommand(params *types.Params) *cobra.Command {
 	flags := &describeFlags{}
 
 	cmd := &cobra.Command{
-		Use:   "describe {policies|httproutes|gateways|gatewayclasses|backends} RESOURCE_NAME",
+		Use:   "describe {policies|httproutes|gateways|gatewayclasses|backends|namespaces} RESOURCE_NAME",
 		Short: "Show details of a specific resource or group of resources",
-		Args:  cobra.RangeArgs(1, 2),
+		Long: `Show details of a specific resource or group of resources.
+
+Policies can be named as NAME, NAMESPACE/NAME, KIND/NAME or KIND/NAMESPACE/NAME,
+where KIND is the kind, plural or KIND.GROUP of the policy CRD.`,
+		Args: cobra.RangeArgs(1, 2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return completeKinds(params, describeKinds)(cmd, args, toComplete)
+			}
+			if len(args) > 1 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			ns := flags.namespace
+			if listsAllNamespaces(cmd, flags.allNamespaces) {
+				ns = ""
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), CompletionTimeout)
+			defer cancel()
+			return completeResourceNames(ctx, params, args[0], ns, toComplete)
+		},
 		Run: func(cmd *cobra.Command, args []string) {
-			runDescribe(args, params, flags)
+			flags.output.apply(params)
+			flags.allNamespaces = listsAllNamespaces(cmd, flags.allNamespaces)
+			if flags.clusters.enabled() {
+				runDescribeAcrossClusters(cmd.Context(), args, params, flags)
+				return
+			}
+			if err := runDescribe(cmd.Context(), args, params, flags); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
 		},
 	}
-	cmd.Flags().StringVarP(&flags.namespace, "namespace", "n", "default", "")
-	cmd.Flags().BoolVarP(&flags.allNamespaces, "all-namespaces", "A", false, "If present, list requested resources from all namespaces.")
+	cmd.Flags().StringVarP(&flags.namespace, "namespace", "n", params.Config.DefaultNamespace(), "")
+	cmd.Flags().BoolVarP(&flags.allNamespaces, "all-namespaces", "A", params.Config.AllNamespaces, "If present, list requested resources from all namespaces.")
+	cmd.RegisterFlagCompletionFunc("namespace", completeNamespaces(params))
+	cmd.Flags().BoolVar(&flags.affected, "affected", false, "If present, also list the Gateways, HTTPRoutes and Backends affected by each policy. Only applies to policies.")
+	flags.clusters.addFlags(cmd, params)
+	flags.output.addFlags(cmd, params, "yaml")
 
 	return cmd
 }
 
-func runDescribe(args []string, params *types.Params, flags *describeFlags) {
-	kind := args[0]
+// runDescribe describes the resources, returning an error instead of exiting
+// such that it can run for multiple clusters.
+func runDescribe(ctx context.Context, args []string, params *types.Params, flags *describeFlags) error {
+	kind := params.Config.ResolveAlias(args[0])
 	ns := flags.namespace
 	if flags.allNamespaces {
 		ns = ""
@@ -49,56 +89,85 @@ func runDescribe(args []string, params *types.Params, flags *describeFlags) {
 
 	switch kind {
 	case "policy", "policies":
-		policyList := params.PolicyManager.GetPolicies()
-		policies.PrintDescribeView(params, policyList)
+		var policyList []policymanager.Policy
+		if len(args) == 1 {
+			// Like get policies, the namespace selects the policies within
+			// it along with the cluster scoped ones.
+			policyList = policies.Filter{Namespace: ns}.Apply(params, params.PolicyManager.GetPolicies())
+		} else {
+			policyList = policies.Select(params, args[1], ns)
+			if len(policyList) == 0 {
+				return fmt.Errorf("policies %q not found", args[1])
+			}
+		}
+		if flags.affected {
+			return policies.PrintDescribeViewWithAffected(ctx, params, policyList)
+		}
+		return policies.PrintDescribeView(ctx, params, policyList)
 	case "httproute", "httproutes":
 		var httpRoutes []gatewayv1beta1.HTTPRoute
 		if len(args) == 1 {
 			var err error
-			httpRoutes, err = httproutes.List(context.TODO(), params, ns)
+			httpRoutes, err = httproutes.List(ctx, params, ns)
 			if err != nil {
-				panic(err)
+				return err
 			}
 		} else {
-			httpRoute, err := httproutes.Get(context.TODO(), params, ns, args[1])
+			httpRoute, err := httproutes.Get(ctx, params, ns, args[1])
 			if err != nil {
-				panic(err)
+				return err
 			}
 			httpRoutes = []gatewayv1beta1.HTTPRoute{httpRoute}
 		}
-		httproutes.PrintDescribeView(context.TODO(), params, httpRoutes)
+		return httproutes.PrintDescribeView(ctx, params, httpRoutes)
 	case "gateway", "gateways":
 		var gws []gatewayv1beta1.Gateway
 		if len(args) == 1 {
 			var err error
-			gws, err = gateways.List(context.TODO(), params, ns)
+			gws, err = gateways.List(ctx, params, ns)
 			if err != nil {
-				panic(err)
+				return err
 			}
 		} else {
-			gw, err := gateways.Get(context.TODO(), params, ns, args[1])
+			gw, err := gateways.Get(ctx, params, ns, args[1])
 			if err != nil {
-				panic(err)
+				return err
 			}
 			gws = []gatewayv1beta1.Gateway{gw}
 		}
-		gateways.PrintDescribeView(context.TODO(), params, gws)
+		return gateways.PrintDescribeView(ctx, params, gws)
 	case "gatewayclass", "gatewayclasses":
 		var gwClasses []gatewayv1beta1.GatewayClass
 		if len(args) == 1 {
 			var err error
-			gwClasses, err = gatewayclasses.List(context.TODO(), params)
+			gwClasses, err = gatewayclasses.List(ctx, params)
 			if err != nil {
-				panic(err)
+				return err
 			}
 		} else {
-			gwc, err := gatewayclasses.Get(context.TODO(), params, args[1])
+			gwc, err := gatewayclasses.Get(ctx, params, args[1])
 			if err != nil {
-				panic(err)
+				return err
 			}
 			gwClasses = []gatewayv1beta1.GatewayClass{gwc}
 		}
-		gatewayclasses.PrintDescribeView(context.TODO(), params, gwClasses)
+		return gatewayclasses.PrintDescribeView(ctx, params, gwClasses)
+	case "namespace", "namespaces", "ns":
+		var nsList []corev1.Namespace
+		if len(args) == 1 {
+			var err error
+			nsList, err = namespaces.List(ctx, params)
+			if err != nil {
+				return err
+			}
+		} else {
+			namespace, err := namespaces.Get(ctx, params, args[1])
+			if err != nil {
+				return err
+			}
+			nsList = []corev1.Namespace{namespace}
+		}
+		return namespaces.PrintDescribeView(ctx, params, nsList)
 	case "backend", "backends":
 		var backendsList []unstructured.Unstructured
 
@@ -114,19 +183,19 @@ func runDescribe(args []string, params *types.Params, flags *describeFlags) {
 
 		if resourceName == "" {
 			var err error
-			backendsList, err = backends.List(context.TODO(), params, resourceType, ns)
+			backendsList, err = backends.List(ctx, params, resourceType, ns)
 			if err != nil {
-				panic(err)
+				return err
 			}
 		} else {
-			backend, err := backends.Get(context.TODO(), params, resourceType, ns, resourceName)
+			backend, err := backends.Get(ctx, params, resourceType, ns, resourceName)
 			if err != nil {
-				panic(err)
+				return err
 			}
 			backendsList = []unstructured.Unstructured{backend}
 		}
-		backends.PrintDescribeView(context.TODO(), params, backendsList)
+		return backends.PrintDescribeView(ctx, params, backendsList)
 	default:
-		fmt.Fprintf(os.Stderr, "Unrecognized RESOURCE_TYPE\n")
+		return fmt.Errorf("Unrecognized RESOURCE_TYPE")
 	}
 }
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/strings/slices"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+)
+
+func TestRunDescribe_PoliciesWithinNamespace(t *testing.T) {
+	timeoutPolicy := func(namespace string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "foo.com/v1",
+				"kind":       "TimeoutPolicy",
+				"metadata": map[string]interface{}{
+					"name":      "timeout-" + namespace,
+					"namespace": namespace,
+				},
+				"spec": map[string]interface{}{
+					"targetRef": map[string]interface{}{"kind": "Namespace", "name": namespace},
+				},
+			},
+		}
+	}
+	objects := []runtime.Object{
+		&apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "timeoutpolicies.foo.com",
+				Labels: map[string]string{
+					common.GatewayPolicyLabelKey: "direct",
+				},
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Scope:    apiextensionsv1.NamespaceScoped,
+				Group:    "foo.com",
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1"}},
+				Names: apiextensionsv1.CustomResourceDefinitionNames{
+					Plural: "timeoutpolicies",
+					Kind:   "TimeoutPolicy",
+				},
+			},
+		},
+		timeoutPolicy("ns1"),
+		timeoutPolicy("ns2"),
+	}
+	params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
+
+	testCases := []struct {
+		name  string
+		flags describeFlags
+		want  []string
+	}{
+		{
+			name:  "within namespace",
+			flags: describeFlags{namespace: "ns1"},
+			want:  []string{"timeout-ns1"},
+		},
+		{
+			name:  "across namespaces",
+			flags: describeFlags{namespace: "ns1", allNamespaces: true},
+			want:  []string{"timeout-ns1", "timeout-ns2"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			params.Out = out
+			if err := runDescribe(context.Background(), []string{"policies"}, params, &tc.flags); err != nil {
+				t.Fatalf("runDescribe() failed: %v", err)
+			}
+			for _, name := range []string{"timeout-ns1", "timeout-ns2"} {
+				want := slices.Contains(tc.want, name)
+				if got := strings.Contains(out.String(), name); got != want {
+					t.Errorf("runDescribe() described %v = %v; want %v\n%v", name, got, want, out.String())
+				}
+			}
+		})
+	}
+}
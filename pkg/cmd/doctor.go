@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/doctor"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+)
+
+// SkipPolicyManagerInitAnnotation is set on commands which must run without
+// first initializing the PolicyManager, like the commands which diagnose why
+// the initialization fails.
+const SkipPolicyManagerInitAnnotation = "gwctl.skip-policymanager-init"
+
+// SkipClusterSetupAnnotation is set on commands which do not always need the
+// cluster, such that the Params of the current context are not created before
+// running them. Such commands load the Params themselves through
+// Params.Clusters when needed.
+const SkipClusterSetupAnnotation = "gwctl.skip-cluster-setup"
+
+func NewDoctorCommand(params *types.Params) *cobra.Command {
+	output := &outputFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check whether the cluster is ready for use with the Gateway API and policies",
+		Long: `Check whether the cluster is ready for use with the Gateway API and policies.
+
+The checks verify that the Gateway API CRDs are installed at versions readable
+by gwctl, each GatewayClass is Accepted by its controller, the policy CRDs are
+labelled and structured as expected, and that the user has the access needed by
+gwctl. Exits with a non-zero status if any check fails.`,
+		Args: cobra.NoArgs,
+		Annotations: map[string]string{
+			SkipPolicyManagerInitAnnotation: "true",
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			output.apply(params)
+			runDoctor(cmd.Context(), params)
+		},
+	}
+	// Without an output format, the checks are printed as a checklist.
+	output.addFlags(cmd, params, "")
+	return cmd
+}
+
+func runDoctor(ctx context.Context, params *types.Params) {
+	checks := doctor.Run(ctx, params)
+	doctor.Print(params, checks)
+	if doctor.HasFailures(checks) {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/multicluster"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+)
+
+type driftFlags struct {
+	namespace     string
+	allNamespaces bool
+	clusters      clusterFlags
+	output        outputFlags
+}
+
+func NewDriftCommand(params *types.Params) *cobra.Command {
+	flags := &driftFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "drift [HTTPROUTE_NAME] {--contexts CONTEXTS|--all-contexts}",
+		Short: "Compare the effective policies of the same HTTPRoutes across clusters",
+		Long: `Compare the effective policies of the same HTTPRoutes across clusters.
+
+HTTPRoutes with the same namespace and name are compared across the clusters of
+the kubeconfig contexts which have them. For every effective policy which
+differs, the effective spec within each cluster is printed, along with the
+clusters to which the policy does not apply.`,
+		Args: cobra.MaximumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			ns := flags.namespace
+			if listsAllNamespaces(cmd, flags.allNamespaces) {
+				ns = ""
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), CompletionTimeout)
+			defer cancel()
+			return completeResourceNames(ctx, params, "httproutes", ns, toComplete)
+		},
+		Annotations: map[string]string{
+			// The PolicyManager of every cluster is initialized when it is
+			// loaded.
+			SkipPolicyManagerInitAnnotation: "true",
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			flags.output.apply(params)
+			flags.allNamespaces = listsAllNamespaces(cmd, flags.allNamespaces)
+			runDrift(cmd.Context(), args, params, flags)
+		},
+	}
+	cmd.Flags().StringVarP(&flags.namespace, "namespace", "n", params.Config.DefaultNamespace(), "")
+	cmd.Flags().BoolVarP(&flags.allNamespaces, "all-namespaces", "A", params.Config.AllNamespaces, "If present, compare HTTPRoutes from all namespaces.")
+	cmd.RegisterFlagCompletionFunc("namespace", completeNamespaces(params))
+	flags.clusters.addFlags(cmd, params)
+	flags.output.addFlags(cmd, params, "yaml")
+
+	return cmd
+}
+
+func runDrift(ctx context.Context, args []string, params *types.Params, flags *driftFlags) {
+	if !flags.clusters.enabled() {
+		fmt.Fprintf(os.Stderr, "One of --contexts or --all-contexts is required\n")
+		os.Exit(1)
+	}
+	ns := flags.namespace
+	if flags.allNamespaces {
+		ns = ""
+	}
+	var name string
+	if len(args) != 0 {
+		name = args[0]
+	}
+
+	clusters, loadErr := loadClusters(ctx, params, &flags.clusters)
+	if len(clusters) < 2 {
+		fmt.Fprintf(os.Stderr, "At least two clusters are needed to compare, got %v\n", len(clusters))
+		exitIfClusterErrors(loadErr)
+		os.Exit(1)
+	}
+	drifts, err := multicluster.CompareHTTPRoutes(ctx, clusters, ns, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	multicluster.PrintDrifts(params, drifts)
+	exitIfClusterErrors(loadErr)
+}
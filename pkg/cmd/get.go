@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
 	"github.com/gauravkghildiyal/gwctl/pkg/resources/httproutes"
 	"github.com/gauravkghildiyal/gwctl/pkg/resources/policies"
 	"github.com/gauravkghildiyal/gwctl/pkg/types"
@@ -14,46 +17,115 @@ import (
 type getFlags struct {
 	namespace     string
 	allNamespaces bool
+	kind          string
+	targetKind    string
+	targetName    string
+	selector      string
+	fieldSelector string
+	clusters      clusterFlags
 }
 
 func NewGetCommand(params *types.Params) *cobra.Command {
 	flags := &getFlags{}
 
 	cmd := &cobra.Command{
-		Use:   "get {policies|policycrds|httproutes}",
-		Short: "Display one or many resources",
-		Args:  cobra.ExactArgs(1),
+		Use:               "get {policies|policycrds|httproutes}",
+		Short:             "Display one or many resources",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeKinds(params, getKinds),
 		Run: func(cmd *cobra.Command, args []string) {
-			runGet(args, params, flags)
+			flags.allNamespaces = listsAllNamespaces(cmd, flags.allNamespaces)
+			runGet(cmd.Context(), args, params, flags)
 		},
 	}
-	cmd.Flags().StringVarP(&flags.namespace, "namespace", "n", "default", "")
-	cmd.Flags().BoolVarP(&flags.allNamespaces, "all-namespaces", "A", false, "If present, list requested resources from all namespaces.")
+	cmd.Flags().StringVarP(&flags.namespace, "namespace", "n", params.Config.DefaultNamespace(), "")
+	cmd.Flags().BoolVarP(&flags.allNamespaces, "all-namespaces", "A", params.Config.AllNamespaces, "If present, list requested resources from all namespaces.")
+	cmd.RegisterFlagCompletionFunc("namespace", completeNamespaces(params))
+	cmd.Flags().StringVar(&flags.kind, "kind", "", "Only list policies of this kind (kind, plural or KIND.GROUP of the policy CRD).")
+	cmd.Flags().StringVar(&flags.targetKind, "target-kind", "", "Only list policies whose targetRef has this kind.")
+	cmd.Flags().StringVar(&flags.targetName, "target-name", "", "Only list policies whose targetRef has this name.")
+	cmd.Flags().StringVarP(&flags.selector, "selector", "l", "", "Label selector to filter policies on, supports '=', '==', '!=', 'in' and 'notin'.")
+	cmd.Flags().StringVar(&flags.fieldSelector, "field-selector", "", "Field selector to filter policies on, supports '=', '==' and '!=' over metadata.name, metadata.namespace, kind and spec.targetRef.{group,kind,name,namespace}.")
+	cmd.RegisterFlagCompletionFunc("kind", completePolicyKinds(params))
+	cmd.RegisterFlagCompletionFunc("target-kind", completeTargetKinds)
+	flags.clusters.addFlags(cmd, params)
 
 	return cmd
 }
 
-func runGet(args []string, params *types.Params, flags *getFlags) {
-	kind := args[0]
+// listsAllNamespaces returns whether the command lists all namespaces, where an
+// explicit namespace takes precedence over listing all namespaces by default
+// through the config file.
+func listsAllNamespaces(cmd *cobra.Command, allNamespaces bool) bool {
+	if cmd.Flags().Changed("namespace") && !cmd.Flags().Changed("all-namespaces") {
+		return false
+	}
+	return allNamespaces
+}
+
+func runGet(ctx context.Context, args []string, params *types.Params, flags *getFlags) {
+	kind := params.Config.ResolveAlias(args[0])
 	ns := flags.namespace
 	if flags.allNamespaces {
 		ns = ""
 	}
 
+	// table names the table within the config file, while listRows lists the
+	// resources of a cluster as rows of the table.
+	var table string
+	var listRows func(context.Context, *types.Params) ([]string, [][]string, error)
 	switch kind {
 	case "policy", "policies":
-		list := params.PolicyManager.GetPolicies()
-		policies.Print(params, list)
+		filter := policies.Filter{
+			Namespace:  ns,
+			Kind:       flags.kind,
+			TargetKind: flags.targetKind,
+			TargetName: flags.targetName,
+		}
+		var err error
+		if filter.Labels, err = labels.Parse(flags.selector); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid selector: %v\n", err)
+			os.Exit(1)
+		}
+		if filter.Fields, err = policies.ParseFieldSelector(flags.fieldSelector); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid field selector: %v\n", err)
+			os.Exit(1)
+		}
+		table = "policies"
+		listRows = func(ctx context.Context, params *types.Params) ([]string, [][]string, error) {
+			header, rows := policies.Table(filter.Apply(params, params.PolicyManager.GetPolicies()), flags.allNamespaces)
+			return header, rows, nil
+		}
 	case "policycrds":
-		list := params.PolicyManager.GetCRDs()
-		policies.PrintCRDs(params, list)
+		table = "policycrds"
+		listRows = func(ctx context.Context, params *types.Params) ([]string, [][]string, error) {
+			header, rows := policies.CRDTable(params.PolicyManager.GetCRDs())
+			return header, rows, nil
+		}
 	case "httproute", "httproutes":
-		list, err := httproutes.List(context.TODO(), params, ns)
-		if err != nil {
-			panic(err)
+		table = "httproutes"
+		listRows = func(ctx context.Context, params *types.Params) ([]string, [][]string, error) {
+			list, err := httproutes.List(ctx, params, ns)
+			if err != nil {
+				return nil, nil, err
+			}
+			header, rows := httproutes.Table(list)
+			return header, rows, nil
 		}
-		httproutes.Print(list)
 	default:
 		fmt.Fprintf(os.Stderr, "Unrecognized RESOURCE_TYPE\n")
+		return
+	}
+
+	if flags.clusters.enabled() {
+		runGetAcrossClusters(ctx, params, flags, table, listRows)
+		return
+	}
+	header, rows, err := listRows(ctx, params)
+	if err != nil {
+		panic(err)
+	}
+	if err := common.WriteTable(params.Out, header, rows, params.Config.TableColumns(table)); err != nil {
+		panic(err)
 	}
 }
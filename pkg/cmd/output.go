@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+)
+
+// outputFlags are the flags of the commands which print YAML or JSON.
+type outputFlags struct {
+	output string
+}
+
+// addFlags adds the --output flag, which defaults to the output format of the
+// config file, or fallback if unset.
+func (f *outputFlags) addFlags(cmd *cobra.Command, params *types.Params, fallback string) {
+	cmd.Flags().StringVarP(&f.output, "output", "o", params.Config.OutputFormat(fallback), "Output format. One of: yaml|json.")
+	cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(common.OutputFormats, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// apply sets the output format as params.Output, exiting on unknown formats.
+// An empty output format leaves the default of the command.
+func (f *outputFlags) apply(params *types.Params) {
+	if f.output != "" {
+		if err := common.ValidateOutputFormat(f.output); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	}
+	params.Output = f.output
+}
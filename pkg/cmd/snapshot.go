@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/snapshot"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+)
+
+func NewSnapshotCommand(params *types.Params) *cobra.Command {
+	output := &outputFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Capture the Gateway API objects, policies and effective policies as JSON",
+		Long: `Capture the Gateway API objects, policies and effective policies as JSON.
+
+The snapshot is printed to stdout, as YAML with --output yaml, and can be
+compared against another snapshot (or the live cluster) with "gwctl diff".`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			output.apply(params)
+			runSnapshot(cmd.Context(), params)
+		},
+	}
+	output.addFlags(cmd, params, "json")
+	return cmd
+}
+
+func runSnapshot(ctx context.Context, params *types.Params) {
+	s, err := snapshot.Take(ctx, params)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if err := snapshot.Write(params.Out, s, params.Output); err != nil {
+		panic(err)
+	}
+}
+
+func NewDiffCommand(params *types.Params) *cobra.Command {
+	output := &outputFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "diff BEFORE [AFTER]",
+		Short: "Show how the effective policies changed between two snapshots",
+		Long: `Show how the effective policies changed between two snapshots.
+
+BEFORE and AFTER are files written by "gwctl snapshot". Without AFTER, BEFORE
+is compared against the live cluster. For every Gateway, HTTPRoute and Backend
+whose effective policies changed, the changed fields are printed along with
+their values before and after.`,
+		Args: cobra.RangeArgs(1, 2),
+		Annotations: map[string]string{
+			// The cluster is only needed to compare against the live cluster,
+			// in which case it is set up by takeLiveSnapshot.
+			SkipClusterSetupAnnotation: "true",
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			output.apply(params)
+			runDiff(cmd.Context(), args, params)
+		},
+	}
+	output.addFlags(cmd, params, "yaml")
+	return cmd
+}
+
+func runDiff(ctx context.Context, args []string, params *types.Params) {
+	before, err := readSnapshot(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	var after snapshot.Snapshot
+	if len(args) > 1 {
+		after, err = readSnapshot(args[1])
+	} else {
+		after, err = takeLiveSnapshot(ctx, params)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	snapshot.Print(params, snapshot.Compare(before, after))
+}
+
+func readSnapshot(filename string) (snapshot.Snapshot, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return snapshot.Snapshot{}, err
+	}
+	defer f.Close()
+	result, err := snapshot.Read(f)
+	if err != nil {
+		return snapshot.Snapshot{}, fmt.Errorf("%v: %v", filename, err)
+	}
+	return result, nil
+}
+
+// takeLiveSnapshot takes a snapshot of the current context.
+func takeLiveSnapshot(ctx context.Context, params *types.Params) (snapshot.Snapshot, error) {
+	current, err := params.Clusters.Load(ctx, "")
+	if err != nil {
+		return snapshot.Snapshot{}, err
+	}
+	return snapshot.Take(ctx, current)
+}
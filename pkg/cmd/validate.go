@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/policymanager"
+	"github.com/gauravkghildiyal/gwctl/pkg/resources/policies"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+)
+
+func NewValidateCommand(params *types.Params) *cobra.Command {
+	output := &outputFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "validate policycrds [NAME...]",
+		Short: "Validate resources against the Gateway API conventions",
+		Long: `Validate resources against the Gateway API conventions.
+
+For policycrds, the schema of each served version is checked against GEP-713:
+spec.targetRef (or spec.targetRefs) must exist, inherited policies must define
+spec.default and/or spec.override, the status should follow
+PolicyAncestorStatus, and the CRD scope should match the kinds it targets.
+Exits with a non-zero status if any CRD has errors.`,
+		Args: cobra.MinimumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return completeKinds(params, validateKinds)(cmd, args, toComplete)
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), CompletionTimeout)
+			defer cancel()
+			return completeResourceNames(ctx, params, args[0], "", toComplete)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			output.apply(params)
+			runValidate(args, params)
+		},
+	}
+	output.addFlags(cmd, params, "yaml")
+	return cmd
+}
+
+func runValidate(args []string, params *types.Params) {
+	kind, names := params.Config.ResolveAlias(args[0]), args[1:]
+
+	switch kind {
+	case "policycrd", "policycrds":
+		var list []policymanager.PolicyCRD
+		for _, policyCRD := range params.PolicyManager.GetCRDs() {
+			if len(names) == 0 || contains(names, policyCRD.CRD().GetName()) {
+				list = append(list, policyCRD)
+			}
+		}
+		found := make(map[string]bool)
+		for _, policyCRD := range list {
+			found[policyCRD.CRD().GetName()] = true
+		}
+		var unknown bool
+		for _, name := range names {
+			if !found[name] {
+				fmt.Fprintf(os.Stderr, "Policy CRD %q not found\n", name)
+				unknown = true
+			}
+		}
+
+		if len(list) != 0 {
+			policies.PrintCRDValidation(params, list)
+		}
+		if unknown {
+			os.Exit(1)
+		}
+		for _, policyCRD := range list {
+			for _, issue := range policyCRD.Validate() {
+				if issue.Severity == policymanager.SchemaIssueError {
+					os.Exit(1)
+				}
+			}
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Unrecognized RESOURCE_TYPE\n")
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
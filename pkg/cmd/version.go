@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+	"github.com/gauravkghildiyal/gwctl/pkg/gatewayapi"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+	"github.com/gauravkghildiyal/gwctl/pkg/version"
+)
+
+type versionFlags struct {
+	server bool
+	output outputFlags
+}
+
+func NewVersionCommand(params *types.Params) *cobra.Command {
+	flags := &versionFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print the version information",
+		Args:  cobra.NoArgs,
+		// The clients are only needed for --server.
+		Annotations: map[string]string{
+			SkipClusterSetupAnnotation: "true",
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			flags.output.apply(params)
+			runVersion(cmd.Context(), params, flags)
+		},
+	}
+	cmd.Flags().BoolVar(&flags.server, "server", false, "If present, also print the Kubernetes version, Gateway API versions and policy CRDs installed in the cluster.")
+	flags.output.addFlags(cmd, params, "yaml")
+
+	return cmd
+}
+
+type versionView struct {
+	Client *clientVersionView `json:",omitempty"`
+	Server *serverVersionView `json:",omitempty"`
+}
+
+type clientVersionView struct {
+	version.Info `json:",inline"`
+	// GatewayAPIModel is the Gateway API version used as the internal model,
+	// into which all other versions are converted.
+	GatewayAPIModel string `json:",omitempty"`
+	// GatewayAPIReadableVersions are the Gateway API versions which can be read.
+	GatewayAPIReadableVersions []string `json:",omitempty"`
+}
+
+type serverVersionView struct {
+	KubernetesVersion        string   `json:",omitempty"`
+	GatewayAPIServedVersions []string `json:",omitempty"`
+	// GatewayAPIReadVersions maps each Gateway API resource to the version in
+	// which it is read.
+	GatewayAPIReadVersions  map[string]string `json:",omitempty"`
+	GatewayAPIBundleVersion string            `json:",omitempty"`
+	GatewayAPIChannel       string            `json:",omitempty"`
+	PolicyCRDs              []policyCRDView   `json:",omitempty"`
+}
+
+type policyCRDView struct {
+	Name      string `json:",omitempty"`
+	Inherited bool   `json:""`
+	Scope     string `json:",omitempty"`
+}
+
+func runVersion(ctx context.Context, params *types.Params, flags *versionFlags) {
+	view := versionView{
+		Client: &clientVersionView{
+			Info:                       version.Get(),
+			GatewayAPIModel:            fmt.Sprintf("gateway.networking.k8s.io/%v", gatewayapi.InternalVersion),
+			GatewayAPIReadableVersions: gatewayapi.KnownVersions,
+		},
+	}
+
+	if flags.server {
+		current, err := params.Clusters.Load(ctx, "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		view.Server = newServerVersionView(ctx, current)
+	}
+
+	b, err := common.Marshal(params.Output, view)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Fprint(params.Out, string(b))
+}
+
+func newServerVersionView(ctx context.Context, params *types.Params) *serverVersionView {
+	kubernetesVersion, err := params.DiscoveryClient.ServerVersion()
+	if err != nil {
+		panic(err)
+	}
+	served, err := gatewayapi.DiscoverServedVersions(params.DiscoveryClient)
+	if err != nil {
+		panic(err)
+	}
+	crds, err := gatewayapi.GetInstalledCRDs(ctx, params.DC)
+	if err != nil {
+		panic(err)
+	}
+	bundleVersion, channel := gatewayapi.BundleVersionAndChannel(crds)
+
+	view := &serverVersionView{
+		KubernetesVersion:        kubernetesVersion.GitVersion,
+		GatewayAPIServedVersions: served.Versions,
+		GatewayAPIReadVersions:   served.ResourceVersions,
+		GatewayAPIBundleVersion:  bundleVersion,
+		GatewayAPIChannel:        channel,
+	}
+	for _, policyCRD := range params.PolicyManager.GetCRDs() {
+		view.PolicyCRDs = append(view.PolicyCRDs, policyCRDView{
+			Name:      policyCRD.CRD().GetName(),
+			Inherited: policyCRD.IsInherited(),
+			Scope:     string(policyCRD.CRD().Spec.Scope),
+		})
+	}
+	sort.Slice(view.PolicyCRDs, func(i, j int) bool {
+		return view.PolicyCRDs[i].Name < view.PolicyCRDs[j].Name
+	})
+	return view
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+	"github.com/gauravkghildiyal/gwctl/pkg/whatif"
+)
+
+type whatIfFlags struct {
+	filename  string
+	namespace string
+	delete    bool
+	output    outputFlags
+}
+
+func NewWhatIfCommand(params *types.Params) *cobra.Command {
+	flags := &whatIfFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "what-if -f FILENAME",
+		Short: "Show how policy changes would affect the effective policies, without applying them",
+		Long: `Show how policy changes would affect the effective policies, without applying them.
+
+The policies within the file are created (or replace the existing policies with
+the same kind, namespace and name), or deleted with --delete. For every Gateway, HTTPRoute and
+Backend whose effective policies change, the effective policies before and
+after the change are printed.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			flags.output.apply(params)
+			runWhatIf(cmd.Context(), params, flags)
+		},
+	}
+	cmd.Flags().StringVarP(&flags.filename, "filename", "f", "", "File containing the policies, or - for stdin.")
+	cmd.Flags().StringVarP(&flags.namespace, "namespace", "n", params.Config.DefaultNamespace(), "Namespace of the namespaced policies which do not specify one.")
+	cmd.Flags().BoolVar(&flags.delete, "delete", false, "If present, simulate deleting the policies instead of creating them.")
+	cmd.RegisterFlagCompletionFunc("namespace", completeNamespaces(params))
+	flags.output.addFlags(cmd, params, "yaml")
+	cmd.MarkFlagRequired("filename")
+
+	return cmd
+}
+
+func runWhatIf(ctx context.Context, params *types.Params, flags *whatIfFlags) {
+	var r io.Reader = os.Stdin
+	if flags.filename != "-" {
+		f, err := os.Open(flags.filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	changes, err := whatif.ReadChanges(r, params, flags.namespace, flags.delete)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	diffs, err := whatif.Run(ctx, params, changes)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	whatif.Print(params, diffs)
+}
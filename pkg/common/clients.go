@@ -1,6 +1,10 @@
 package common
 
 import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
 
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -39,6 +43,62 @@ func MustClientsForTest(t *testing.T, initRuntimeObjects ...runtime.Object) *Fak
 	}
 }
 
+// CountingClient counts the Gets and Lists issued through it, by the type and
+// key of the object or the type and options of the list.
+type CountingClient struct {
+	client.Client
+	// Release, unless nil, blocks every Get until it is closed.
+	Release chan struct{}
+
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+func NewCountingClient(c client.Client) *CountingClient {
+	return &CountingClient{Client: c, requests: make(map[string]int)}
+}
+
+func (c *CountingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	c.count(fmt.Sprintf("get %T %v", obj, key))
+	if c.Release != nil {
+		<-c.Release
+	}
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
+func (c *CountingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	c.count(fmt.Sprintf("list %T %+v", list, opts))
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c *CountingClient) count(request string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests[request]++
+}
+
+// Requests returns the number of times each request was issued.
+func (c *CountingClient) Requests() map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result := make(map[string]int, len(c.requests))
+	for request, count := range c.requests {
+		result[request] = count
+	}
+	return result
+}
+
+// Count returns the number of requests issued with the verb, get or list.
+func (c *CountingClient) Count(verb string) int {
+	var result int
+	for request, count := range c.Requests() {
+		if strings.HasPrefix(request, verb+" ") {
+			result += count
+		}
+	}
+	return result
+}
+
 func PtrTo[T any](a T) *T {
 	return &a
 }
@@ -0,0 +1,32 @@
+package common
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// FuzzSeed is an input added to the corpus of FuzzObjects.
+type FuzzSeed struct {
+	Mask  uint8
+	Value string
+}
+
+// FuzzObjects fuzzes fn with a mask and a value, starting from the seeds along
+// with the zero input. Each bit of the mask decides whether some optional part
+// of the objects built by fn is set (as reported by isSet), in which case it is
+// usually set to value. Values with control characters are skipped, since the
+// API server validation rejects them.
+func FuzzObjects(f *testing.F, seeds []FuzzSeed, fn func(t *testing.T, isSet func(bit int) bool, value string)) {
+	f.Add(uint8(0), "")
+	for _, seed := range seeds {
+		f.Add(seed.Mask, seed.Value)
+	}
+
+	f.Fuzz(func(t *testing.T, mask uint8, value string) {
+		if strings.IndexFunc(value, unicode.IsControl) != -1 {
+			t.Skip("control characters are rejected by the API server validation")
+		}
+		fn(t, func(bit int) bool { return mask&(1<<bit) != 0 }, value)
+	})
+}
@@ -0,0 +1,35 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+)
+
+// OutputFormats are the formats of the commands which print YAML or JSON.
+var OutputFormats = []string{"yaml", "json"}
+
+// ValidateOutputFormat returns an error if format is not one of the
+// OutputFormats.
+func ValidateOutputFormat(format string) error {
+	for _, f := range OutputFormats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown output format %q, must be one of %v", format, OutputFormats)
+}
+
+// Marshal encodes v in the output format, which is YAML if format is empty.
+// JSON is indented and ends with a newline, like YAML.
+func Marshal(format string, v interface{}) ([]byte, error) {
+	if format != "json" {
+		return yaml.Marshal(v)
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
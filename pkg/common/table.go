@@ -0,0 +1,44 @@
+package common
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// WriteTable writes the rows as a table with the given header. If columns is
+// not empty, only those columns of the header are written, in the given order.
+// Columns are matched case-insensitively.
+func WriteTable(out io.Writer, header []string, rows [][]string, columns []string) error {
+	indices := make([]int, len(header))
+	for i := range header {
+		indices[i] = i
+	}
+	if len(columns) != 0 {
+		indices = nil
+		for _, column := range columns {
+			index := -1
+			for i, name := range header {
+				if strings.EqualFold(name, column) {
+					index = i
+					break
+				}
+			}
+			if index == -1 {
+				return fmt.Errorf("unknown column %q, must be one of %v", column, header)
+			}
+			indices = append(indices, index)
+		}
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, row := range append([][]string{header}, rows...) {
+		var cells []string
+		for _, i := range indices {
+			cells = append(cells, row[i])
+		}
+		tw.Write([]byte(strings.Join(cells, "\t") + "\n"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,253 @@
+// Package config loads the gwctl config file, which holds the defaults of the
+// user for all commands, like:
+//
+//	output: json
+//	namespace: ns1
+//	aliases:
+//	  hr: httproutes
+//	policies:
+//	  TimeoutPolicy.foo.com:
+//	    mergeStrategy: strategic
+//	    mergeListKeys: rules=name
+//	    renderer: generic
+//	extraPolicyCRDs:
+//	- name: ratelimits.bar.com
+//	  type: inherited
+//	columns:
+//	  policies: [POLICYNAME, POLICYKIND, SUMMARY]
+//
+// The config file is optional, and every setting defaults to the behavior
+// without a config file.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/policymanager"
+)
+
+// PathEnv is the environment variable which overrides the path of the config
+// file.
+const PathEnv = "GWCTL_CONFIG"
+
+type Config struct {
+	// Output is the default of the --output flag of the commands which print
+	// YAML or JSON, like describe and what-if.
+	Output string `json:"output,omitempty"`
+	// Namespace is the default namespace, instead of "default".
+	Namespace string `json:"namespace,omitempty"`
+	// AllNamespaces defaults the --all-namespaces flag to true.
+	AllNamespaces bool `json:"allNamespaces,omitempty"`
+	// Aliases maps an alias to a resource type, like "hr" to "httproutes".
+	Aliases map[string]string `json:"aliases,omitempty"`
+	// Policies configures the policies of the Policy CRDs, keyed by the kind
+	// (which matches the kind in all groups) or the PolicyCrdID.
+	Policies map[string]PolicyConfig `json:"policies,omitempty"`
+	// ExtraPolicyCRDs are treated as Policy CRDs even without the
+	// gateway.networking.k8s.io/policy label.
+	ExtraPolicyCRDs []ExtraPolicyCRD `json:"extraPolicyCRDs,omitempty"`
+	// Columns maps a table, one of "policies", "policycrds" or "httproutes"
+	// (as printed by get, the only command which prints tables), to the
+	// columns which are printed, in order.
+	Columns map[string][]string `json:"columns,omitempty"`
+}
+
+type PolicyConfig struct {
+	// MergeStrategy and MergeListKeys take precedence over the annotations
+	// of the Policy CRD, and have the same format.
+	MergeStrategy string `json:"mergeStrategy,omitempty"`
+	MergeListKeys string `json:"mergeListKeys,omitempty"`
+	// Renderer is either "builtin" (the default), which summarizes the
+	// policies with the Renderer registered for the kind, or "generic", which
+	// summarizes all fields as "field=value".
+	Renderer string `json:"renderer,omitempty"`
+}
+
+type ExtraPolicyCRD struct {
+	// Name is the name of the CRD, like "ratelimits.bar.com".
+	Name string `json:"name,omitempty"`
+	// Type is either "inherited" or "direct" (the default).
+	Type string `json:"type,omitempty"`
+}
+
+// DefaultPath returns the path of the config file, which is
+// $XDG_CONFIG_HOME/gwctl/config.yaml (where XDG_CONFIG_HOME defaults to
+// ~/.config), unless overridden through PathEnv.
+func DefaultPath() string {
+	if path := os.Getenv(PathEnv); path != "" {
+		return path
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		dir = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(dir, "gwctl", "config.yaml")
+}
+
+// Load reads and validates the config file at path. A missing config file
+// results in the empty Config.
+func Load(path string) (Config, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Config{}, nil
+	}
+	if err != nil {
+		return Config{}, err
+	}
+
+	var result Config
+	if err := yaml.UnmarshalStrict(b, &result); err != nil {
+		return Config{}, fmt.Errorf("failed to parse config file %v: %v", path, err)
+	}
+	if err := result.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid config file %v: %v", path, err)
+	}
+	return result, nil
+}
+
+// Validate checks the settings which can be checked without a cluster. The
+// columns of the tables are checked when the tables are printed.
+func (c Config) Validate() error {
+	switch c.Output {
+	case "", "yaml", "json":
+	default:
+		return fmt.Errorf("output: unknown output format %q, must be one of [yaml json]", c.Output)
+	}
+	for alias, resourceType := range c.Aliases {
+		if alias == "" || resourceType == "" {
+			return fmt.Errorf("aliases: alias %q of %q must not be empty", alias, resourceType)
+		}
+	}
+	for kind, policyConfig := range c.Policies {
+		if _, err := policymanager.ParseMergeStrategy(policyConfig.MergeStrategy, policyConfig.MergeListKeys); err != nil {
+			return fmt.Errorf("policies[%v]: %v", kind, err)
+		}
+		switch policyConfig.Renderer {
+		case "", "builtin", "generic":
+		default:
+			return fmt.Errorf("policies[%v]: unknown renderer %q, must be one of [builtin generic]", kind, policyConfig.Renderer)
+		}
+	}
+	for i, extraPolicyCRD := range c.ExtraPolicyCRDs {
+		if extraPolicyCRD.Name == "" {
+			return fmt.Errorf("extraPolicyCRDs[%v]: name must not be empty", i)
+		}
+		switch extraPolicyCRD.Type {
+		case "", "inherited", "direct":
+		default:
+			return fmt.Errorf("extraPolicyCRDs[%v]: unknown type %q, must be one of [inherited direct]", i, extraPolicyCRD.Type)
+		}
+	}
+	for table := range c.Columns {
+		switch table {
+		case "policies", "policycrds", "httproutes":
+		default:
+			return fmt.Errorf("columns: unknown table %q, must be one of [policies policycrds httproutes]", table)
+		}
+	}
+	return nil
+}
+
+// OutputFormat returns the default output format, or fallback if unset.
+func (c Config) OutputFormat(fallback string) string {
+	if c.Output == "" {
+		return fallback
+	}
+	return c.Output
+}
+
+// DefaultNamespace returns the default namespace of the commands.
+func (c Config) DefaultNamespace() string {
+	if c.Namespace == "" {
+		return "default"
+	}
+	return c.Namespace
+}
+
+// ResolveAlias returns the resource type of the alias, or name itself if it is
+// not an alias.
+func (c Config) ResolveAlias(name string) string {
+	if resourceType, ok := c.Aliases[name]; ok {
+		return resourceType
+	}
+	return name
+}
+
+// TableColumns returns the columns of the table, or nil for all columns.
+func (c Config) TableColumns(table string) []string {
+	return c.Columns[table]
+}
+
+// RegisterPolicyCRDs registers the ExtraPolicyCRDs with the PolicyManager. It
+// must be called before the PolicyManager is initialized.
+func (c Config) RegisterPolicyCRDs(policyManager *policymanager.PolicyManager) {
+	for _, extraPolicyCRD := range c.ExtraPolicyCRDs {
+		policyType := extraPolicyCRD.Type
+		if policyType == "" {
+			policyType = "direct"
+		}
+		policyManager.TreatAsPolicyCRD(extraPolicyCRD.Name, policyType)
+	}
+}
+
+// ApplyMergeStrategies applies the merge strategies of the Policies to the
+// matching Policy CRDs of the initialized PolicyManager. Settings of Policy CRDs
+// which are not installed are ignored.
+func (c Config) ApplyMergeStrategies(policyManager *policymanager.PolicyManager) error {
+	for _, kind := range c.policyKinds() {
+		policyConfig := c.Policies[kind]
+		if policyConfig.MergeStrategy == "" && policyConfig.MergeListKeys == "" {
+			continue
+		}
+		mergeStrategy, err := policymanager.ParseMergeStrategy(policyConfig.MergeStrategy, policyConfig.MergeListKeys)
+		if err != nil {
+			return fmt.Errorf("policies[%v]: %v", kind, err)
+		}
+		for _, policyCRD := range policyManager.GetCRDs() {
+			if string(policyCRD.ID()) != kind && policyCRD.CRD().Spec.Names.Kind != kind {
+				continue
+			}
+			if err := policyManager.SetMergeStrategy(policyCRD.ID(), mergeStrategy); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GenericRendererKinds returns the kinds (or PolicyCrdIDs) of the Policies
+// whose summaries use the generic renderer.
+func (c Config) GenericRendererKinds() []string {
+	var result []string
+	for _, kind := range c.policyKinds() {
+		if c.Policies[kind].Renderer == "generic" {
+			result = append(result, kind)
+		}
+	}
+	return result
+}
+
+// policyKinds returns the keys of the Policies, where the kinds come before the
+// PolicyCrdIDs, such that the settings of a PolicyCrdID take precedence over
+// the settings of its kind when applied in order.
+func (c Config) policyKinds() []string {
+	var result []string
+	for kind := range c.Policies {
+		result = append(result, kind)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := strings.Contains(result[i], "."), strings.Contains(result[j], ".")
+		if a != b {
+			return b
+		}
+		return result[i] < result[j]
+	})
+	return result
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+	"github.com/gauravkghildiyal/gwctl/pkg/policymanager"
+)
+
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		want    Config
+		wantErr bool
+	}{
+		{
+			name: "all settings",
+			content: `
+output: json
+namespace: ns1
+allNamespaces: true
+aliases:
+  hr: httproutes
+policies:
+  TimeoutPolicy:
+    mergeStrategy: strategic
+    mergeListKeys: rules=name
+    renderer: generic
+extraPolicyCRDs:
+- name: ratelimits.bar.com
+  type: inherited
+columns:
+  policies: [POLICYNAME, SUMMARY]
+`,
+			want: Config{
+				Output:          "json",
+				Namespace:       "ns1",
+				AllNamespaces:   true,
+				Aliases:         map[string]string{"hr": "httproutes"},
+				Policies:        map[string]PolicyConfig{"TimeoutPolicy": {MergeStrategy: "strategic", MergeListKeys: "rules=name", Renderer: "generic"}},
+				ExtraPolicyCRDs: []ExtraPolicyCRD{{Name: "ratelimits.bar.com", Type: "inherited"}},
+				Columns:         map[string][]string{"policies": {"POLICYNAME", "SUMMARY"}},
+			},
+		},
+		{
+			name:    "unknown field",
+			content: "outputFormat: json",
+			wantErr: true,
+		},
+		{
+			name:    "unknown output format",
+			content: "output: table",
+			wantErr: true,
+		},
+		{
+			name:    "invalid merge strategy",
+			content: "policies: {TimeoutPolicy: {mergeStrategy: atomic, mergeListKeys: rules=name}}",
+			wantErr: true,
+		},
+		{
+			name:    "unknown renderer",
+			content: "policies: {TimeoutPolicy: {renderer: fancy}}",
+			wantErr: true,
+		},
+		{
+			name:    "unknown type of extra policy CRD",
+			content: "extraPolicyCRDs: [{name: ratelimits.bar.com, type: true}]",
+			wantErr: true,
+		},
+		{
+			name:    "columns of a table which cannot be configured",
+			content: "columns: {gateways: [NAME]}",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tc.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Load(path)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Load() returned err=%v; wantErr=%v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Load() returned unexpected diff (-want +got)=\n%v", diff)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		got, err := Load(filepath.Join(t.TempDir(), "config.yaml"))
+		if err != nil {
+			t.Fatalf("Load() returned err=%v; want no error", err)
+		}
+		if diff := cmp.Diff(Config{}, got); diff != "" {
+			t.Errorf("Load() returned unexpected diff (-want +got)=\n%v", diff)
+		}
+		if got.DefaultNamespace() != "default" || got.OutputFormat("yaml") != "yaml" || got.ResolveAlias("hr") != "hr" {
+			t.Errorf("empty Config does not default to the behavior without a config file")
+		}
+	})
+}
+
+func TestPolicySettings(t *testing.T) {
+	crd := func(name, group, kind string, labels map[string]string) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Scope:    apiextensionsv1.ClusterScoped,
+				Group:    group,
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1"}},
+				Names: apiextensionsv1.CustomResourceDefinitionNames{
+					Plural: name[:len(name)-len(group)-1],
+					Kind:   kind,
+				},
+			},
+		}
+	}
+	timeoutPolicy := func(group string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": group + "/v1",
+				"kind":       "TimeoutPolicy",
+				"metadata":   map[string]interface{}{"name": "timeout-" + group},
+				"spec": map[string]interface{}{
+					"default":   map[string]interface{}{"seconds": int64(30)},
+					"targetRef": map[string]interface{}{"kind": "Namespace", "name": "default"},
+				},
+			},
+		}
+	}
+	objects := []runtime.Object{
+		crd("timeoutpolicies.foo.com", "foo.com", "TimeoutPolicy", map[string]string{common.GatewayPolicyLabelKey: "inherited"}),
+		crd("timeoutpolicies.bar.com", "bar.com", "TimeoutPolicy", map[string]string{common.GatewayPolicyLabelKey: "inherited"}),
+		// Without the label, the CRD is only treated as a Policy CRD through
+		// the config.
+		crd("ratelimits.bar.com", "bar.com", "RateLimit", nil),
+		timeoutPolicy("foo.com"),
+		timeoutPolicy("bar.com"),
+		&unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "bar.com/v1",
+				"kind":       "RateLimit",
+				"metadata":   map[string]interface{}{"name": "rate-limit"},
+				"spec": map[string]interface{}{
+					"default":   map[string]interface{}{"requests": int64(10)},
+					"targetRef": map[string]interface{}{"kind": "Namespace", "name": "default"},
+				},
+			},
+		},
+	}
+
+	cfg := Config{
+		Policies: map[string]PolicyConfig{
+			"TimeoutPolicy":         {MergeStrategy: "deep"},
+			"TimeoutPolicy.bar.com": {MergeStrategy: "atomic", Renderer: "generic"},
+			"RetryPolicy":           {Renderer: "generic"},
+		},
+		ExtraPolicyCRDs: []ExtraPolicyCRD{{Name: "ratelimits.bar.com", Type: "inherited"}},
+	}
+	fakeClients := common.MustClientsForTest(t, objects...)
+	policyManager := policymanager.New(fakeClients.DC, fakeClients.Client)
+	cfg.RegisterPolicyCRDs(policyManager)
+	if err := policyManager.Init(context.Background()); err != nil {
+		t.Fatalf("Init() returned err=%v; want no error", err)
+	}
+	if err := cfg.ApplyMergeStrategies(policyManager); err != nil {
+		t.Fatalf("ApplyMergeStrategies() returned err=%v; want no error", err)
+	}
+
+	rateLimit, ok := policyManager.GetCRD("RateLimit.bar.com")
+	if !ok || !rateLimit.IsInherited() {
+		t.Errorf("GetCRD(RateLimit.bar.com) = %v, %v; want the extra Policy CRD as inherited", rateLimit.ID(), ok)
+	}
+	if got := len(policyManager.GetPolicies()); got != 3 {
+		t.Errorf("len(GetPolicies()) = %v; want 3 including the policy of the extra Policy CRD", got)
+	}
+
+	want := map[policymanager.PolicyCrdID]policymanager.MergeStrategyType{
+		"TimeoutPolicy.foo.com": policymanager.MergeStrategyDeep,
+		"TimeoutPolicy.bar.com": policymanager.MergeStrategyAtomic,
+		"RateLimit.bar.com":     policymanager.MergeStrategyJSONMerge,
+	}
+	got := make(map[policymanager.PolicyCrdID]policymanager.MergeStrategyType)
+	for _, policyCRD := range policyManager.GetCRDs() {
+		mergeStrategy, err := policyCRD.MergeStrategy()
+		if err != nil {
+			t.Fatalf("MergeStrategy() returned err=%v; want no error", err)
+		}
+		got[policyCRD.ID()] = mergeStrategy.Type
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("MergeStrategy() of the Policy CRDs returned unexpected diff (-want +got)=\n%v", diff)
+	}
+
+	if diff := cmp.Diff([]string{"RetryPolicy", "TimeoutPolicy.bar.com"}, cfg.GenericRendererKinds()); diff != "" {
+		t.Errorf("GenericRendererKinds() returned unexpected diff (-want +got)=\n%v", diff)
+	}
+}
@@ -0,0 +1,181 @@
+// Package defaults applies the defaults of the Gateway API to objects read from
+// the API server.
+//
+// Most optional fields within the Gateway API have well defined defaults (like
+// the group and kind of a backendRef defaulting to the core Service). The API
+// server will usually have applied these defaults already, but objects created
+// before a CRD upgrade (or fabricated within tests) may still have them unset.
+// Applying the defaults before any analysis means the rest of gwctl does not
+// need to handle the unset case for such fields.
+//
+// Fields which have no default within the Gateway API (like the port of a
+// backendRef) are left untouched and may still be nil. The namespaces of
+// references are left unset as well, such that describe shows the references
+// as written by the user; they are instead resolved against the namespace of
+// the referencing object wherever they are used.
+package defaults
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+)
+
+// NewClient returns a client which applies the Gateway API defaults to every
+// object it reads.
+func NewClient(c client.Client) client.Client {
+	return &defaultingClient{Client: c}
+}
+
+type defaultingClient struct {
+	client.Client
+}
+
+func (c *defaultingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if err := c.Client.Get(ctx, key, obj, opts...); err != nil {
+		return err
+	}
+	Apply(obj)
+	return nil
+}
+
+func (c *defaultingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if err := c.Client.List(ctx, list, opts...); err != nil {
+		return err
+	}
+	Apply(list)
+	return nil
+}
+
+// Apply sets the Gateway API defaults on the object (or list of objects) in
+// place. Objects of unknown types are left untouched.
+func Apply(obj runtime.Object) {
+	switch o := obj.(type) {
+	case *gatewayv1beta1.Gateway:
+		Gateway(o)
+	case *gatewayv1beta1.GatewayList:
+		for i := range o.Items {
+			Gateway(&o.Items[i])
+		}
+	case *gatewayv1beta1.HTTPRoute:
+		HTTPRoute(o)
+	case *gatewayv1beta1.HTTPRouteList:
+		for i := range o.Items {
+			HTTPRoute(&o.Items[i])
+		}
+	}
+}
+
+// Gateway sets the defaults for the addresses and listeners of the Gateway.
+func Gateway(gw *gatewayv1beta1.Gateway) {
+	for i := range gw.Spec.Addresses {
+		if gw.Spec.Addresses[i].Type == nil {
+			gw.Spec.Addresses[i].Type = common.PtrTo(gatewayv1beta1.IPAddressType)
+		}
+	}
+
+	for i := range gw.Spec.Listeners {
+		listener := &gw.Spec.Listeners[i]
+		if listener.TLS != nil && listener.TLS.Mode == nil {
+			listener.TLS.Mode = common.PtrTo(gatewayv1beta1.TLSModeTerminate)
+		}
+
+		if listener.AllowedRoutes == nil {
+			listener.AllowedRoutes = &gatewayv1beta1.AllowedRoutes{}
+		}
+		if listener.AllowedRoutes.Namespaces == nil {
+			listener.AllowedRoutes.Namespaces = &gatewayv1beta1.RouteNamespaces{}
+		}
+		if listener.AllowedRoutes.Namespaces.From == nil {
+			listener.AllowedRoutes.Namespaces.From = common.PtrTo(gatewayv1beta1.NamespacesFromSame)
+		}
+		for j := range listener.AllowedRoutes.Kinds {
+			if listener.AllowedRoutes.Kinds[j].Group == nil {
+				listener.AllowedRoutes.Kinds[j].Group = common.PtrTo(gatewayv1beta1.Group(gatewayv1beta1.GroupName))
+			}
+		}
+	}
+}
+
+// HTTPRoute sets the defaults for the parentRefs, matches, filters and
+// backendRefs of the HTTPRoute.
+func HTTPRoute(httpRoute *gatewayv1beta1.HTTPRoute) {
+	for i := range httpRoute.Spec.ParentRefs {
+		parentRef := &httpRoute.Spec.ParentRefs[i]
+		if parentRef.Group == nil {
+			parentRef.Group = common.PtrTo(gatewayv1beta1.Group(gatewayv1beta1.GroupName))
+		}
+		if parentRef.Kind == nil {
+			parentRef.Kind = common.PtrTo(gatewayv1beta1.Kind("Gateway"))
+		}
+	}
+
+	for i := range httpRoute.Spec.Rules {
+		rule := &httpRoute.Spec.Rules[i]
+
+		// A rule without matches matches all requests, which is the same as
+		// a single PathPrefix match on "/".
+		if len(rule.Matches) == 0 {
+			rule.Matches = []gatewayv1beta1.HTTPRouteMatch{{}}
+		}
+		for j := range rule.Matches {
+			httpRouteMatch(&rule.Matches[j])
+		}
+
+		for j := range rule.Filters {
+			httpRouteFilter(&rule.Filters[j])
+		}
+
+		for j := range rule.BackendRefs {
+			backendRef := &rule.BackendRefs[j]
+			backendObjectReference(&backendRef.BackendObjectReference)
+			if backendRef.Weight == nil {
+				backendRef.Weight = common.PtrTo(int32(1))
+			}
+			for k := range backendRef.Filters {
+				httpRouteFilter(&backendRef.Filters[k])
+			}
+		}
+	}
+}
+
+func httpRouteMatch(match *gatewayv1beta1.HTTPRouteMatch) {
+	if match.Path == nil {
+		match.Path = &gatewayv1beta1.HTTPPathMatch{}
+	}
+	if match.Path.Type == nil {
+		match.Path.Type = common.PtrTo(gatewayv1beta1.PathMatchPathPrefix)
+	}
+	if match.Path.Value == nil {
+		match.Path.Value = common.PtrTo("/")
+	}
+	for i := range match.Headers {
+		if match.Headers[i].Type == nil {
+			match.Headers[i].Type = common.PtrTo(gatewayv1beta1.HeaderMatchExact)
+		}
+	}
+	for i := range match.QueryParams {
+		if match.QueryParams[i].Type == nil {
+			match.QueryParams[i].Type = common.PtrTo(gatewayv1beta1.QueryParamMatchExact)
+		}
+	}
+}
+
+func httpRouteFilter(filter *gatewayv1beta1.HTTPRouteFilter) {
+	if filter.RequestMirror != nil {
+		backendObjectReference(&filter.RequestMirror.BackendRef)
+	}
+}
+
+func backendObjectReference(ref *gatewayv1beta1.BackendObjectReference) {
+	if ref.Group == nil {
+		ref.Group = common.PtrTo(gatewayv1beta1.Group(""))
+	}
+	if ref.Kind == nil {
+		ref.Kind = common.PtrTo(gatewayv1beta1.Kind("Service"))
+	}
+}
@@ -0,0 +1,151 @@
+package defaults
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+)
+
+func TestGateway(t *testing.T) {
+	gw := &gatewayv1beta1.Gateway{
+		Spec: gatewayv1beta1.GatewaySpec{
+			Addresses: []gatewayv1beta1.GatewayAddress{{Value: "10.0.0.1"}},
+			Listeners: []gatewayv1beta1.Listener{
+				{Name: "http"},
+				{
+					Name: "https",
+					TLS:  &gatewayv1beta1.GatewayTLSConfig{},
+					AllowedRoutes: &gatewayv1beta1.AllowedRoutes{
+						Namespaces: &gatewayv1beta1.RouteNamespaces{From: common.PtrTo(gatewayv1beta1.NamespacesFromAll)},
+						Kinds:      []gatewayv1beta1.RouteGroupKind{{Kind: "HTTPRoute"}},
+					},
+				},
+			},
+		},
+	}
+	Apply(gw)
+
+	want := gatewayv1beta1.GatewaySpec{
+		Addresses: []gatewayv1beta1.GatewayAddress{{Type: common.PtrTo(gatewayv1beta1.IPAddressType), Value: "10.0.0.1"}},
+		Listeners: []gatewayv1beta1.Listener{
+			{
+				Name: "http",
+				AllowedRoutes: &gatewayv1beta1.AllowedRoutes{
+					Namespaces: &gatewayv1beta1.RouteNamespaces{From: common.PtrTo(gatewayv1beta1.NamespacesFromSame)},
+				},
+			},
+			{
+				Name: "https",
+				TLS:  &gatewayv1beta1.GatewayTLSConfig{Mode: common.PtrTo(gatewayv1beta1.TLSModeTerminate)},
+				AllowedRoutes: &gatewayv1beta1.AllowedRoutes{
+					Namespaces: &gatewayv1beta1.RouteNamespaces{From: common.PtrTo(gatewayv1beta1.NamespacesFromAll)},
+					Kinds:      []gatewayv1beta1.RouteGroupKind{{Group: common.PtrTo(gatewayv1beta1.Group(gatewayv1beta1.GroupName)), Kind: "HTTPRoute"}},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, gw.Spec); diff != "" {
+		t.Errorf("Unexpected diff after applying defaults (-want +got)=\n%v", diff)
+	}
+}
+
+func TestHTTPRoute(t *testing.T) {
+	httpRoute := &gatewayv1beta1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo-httproute",
+			Namespace: "ns1",
+		},
+		Spec: gatewayv1beta1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
+				ParentRefs: []gatewayv1beta1.ParentReference{
+					{Name: "foo-gateway"},
+					{Name: "bar-gateway", Namespace: common.PtrTo(gatewayv1beta1.Namespace("ns2"))},
+				},
+			},
+			Rules: []gatewayv1beta1.HTTPRouteRule{
+				{
+					Filters: []gatewayv1beta1.HTTPRouteFilter{{
+						Type:          gatewayv1beta1.HTTPRouteFilterRequestMirror,
+						RequestMirror: &gatewayv1beta1.HTTPRequestMirrorFilter{BackendRef: gatewayv1beta1.BackendObjectReference{Name: "mirror-svc"}},
+					}},
+					BackendRefs: []gatewayv1beta1.HTTPBackendRef{{
+						BackendRef: gatewayv1beta1.BackendRef{
+							BackendObjectReference: gatewayv1beta1.BackendObjectReference{Name: "foo-svc"},
+						},
+					}},
+				},
+				{
+					Matches: []gatewayv1beta1.HTTPRouteMatch{{
+						Path:        &gatewayv1beta1.HTTPPathMatch{Value: common.PtrTo("/foo")},
+						Headers:     []gatewayv1beta1.HTTPHeaderMatch{{Name: "version", Value: "v2"}},
+						QueryParams: []gatewayv1beta1.HTTPQueryParamMatch{{Name: "debug", Value: "true"}},
+					}},
+				},
+			},
+		},
+	}
+	Apply(httpRoute)
+
+	// The namespaces of references are not defaulted, such that they are
+	// shown as written by the user.
+	serviceRef := func(name string) gatewayv1beta1.BackendObjectReference {
+		return gatewayv1beta1.BackendObjectReference{
+			Group: common.PtrTo(gatewayv1beta1.Group("")),
+			Kind:  common.PtrTo(gatewayv1beta1.Kind("Service")),
+			Name:  gatewayv1beta1.ObjectName(name),
+		}
+	}
+	gatewayRef := func(name string, namespace *gatewayv1beta1.Namespace) gatewayv1beta1.ParentReference {
+		return gatewayv1beta1.ParentReference{
+			Group:     common.PtrTo(gatewayv1beta1.Group(gatewayv1beta1.GroupName)),
+			Kind:      common.PtrTo(gatewayv1beta1.Kind("Gateway")),
+			Name:      gatewayv1beta1.ObjectName(name),
+			Namespace: namespace,
+		}
+	}
+	want := gatewayv1beta1.HTTPRouteSpec{
+		CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
+			ParentRefs: []gatewayv1beta1.ParentReference{
+				gatewayRef("foo-gateway", nil),
+				gatewayRef("bar-gateway", common.PtrTo(gatewayv1beta1.Namespace("ns2"))),
+			},
+		},
+		Rules: []gatewayv1beta1.HTTPRouteRule{
+			{
+				Matches: []gatewayv1beta1.HTTPRouteMatch{{
+					Path: &gatewayv1beta1.HTTPPathMatch{
+						Type:  common.PtrTo(gatewayv1beta1.PathMatchPathPrefix),
+						Value: common.PtrTo("/"),
+					},
+				}},
+				Filters: []gatewayv1beta1.HTTPRouteFilter{{
+					Type:          gatewayv1beta1.HTTPRouteFilterRequestMirror,
+					RequestMirror: &gatewayv1beta1.HTTPRequestMirrorFilter{BackendRef: serviceRef("mirror-svc")},
+				}},
+				BackendRefs: []gatewayv1beta1.HTTPBackendRef{{
+					BackendRef: gatewayv1beta1.BackendRef{
+						BackendObjectReference: serviceRef("foo-svc"),
+						Weight:                 common.PtrTo(int32(1)),
+					},
+				}},
+			},
+			{
+				Matches: []gatewayv1beta1.HTTPRouteMatch{{
+					Path: &gatewayv1beta1.HTTPPathMatch{
+						Type:  common.PtrTo(gatewayv1beta1.PathMatchPathPrefix),
+						Value: common.PtrTo("/foo"),
+					},
+					Headers:     []gatewayv1beta1.HTTPHeaderMatch{{Type: common.PtrTo(gatewayv1beta1.HeaderMatchExact), Name: "version", Value: "v2"}},
+					QueryParams: []gatewayv1beta1.HTTPQueryParamMatch{{Type: common.PtrTo(gatewayv1beta1.QueryParamMatchExact), Name: "debug", Value: "true"}},
+				}},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, httpRoute.Spec); diff != "" {
+		t.Errorf("Unexpected diff after applying defaults (-want +got)=\n%v", diff)
+	}
+}
@@ -0,0 +1,284 @@
+// Package doctor checks whether a cluster is ready for use with the Gateway API
+// and policies, and whether gwctl has the access it needs.
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+	"github.com/gauravkghildiyal/gwctl/pkg/gatewayapi"
+	"github.com/gauravkghildiyal/gwctl/pkg/policymanager"
+	"github.com/gauravkghildiyal/gwctl/pkg/resources/gatewayclasses"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+)
+
+type Status string
+
+const (
+	StatusPass Status = "PASS"
+	StatusWarn Status = "WARN"
+	StatusFail Status = "FAIL"
+)
+
+// Check is the outcome of a single check.
+type Check struct {
+	Name    string `json:",omitempty"`
+	Status  Status `json:",omitempty"`
+	Message string `json:",omitempty"`
+}
+
+// validPolicyLabelValues are the values of the policy label which qualify a
+// CRD as a policy CRD.
+var validPolicyLabelValues = []string{"true", "direct", "inherited"}
+
+// requiredCRDs are the Gateway API CRDs without which gwctl cannot function.
+// Other CoreCRDs are optional.
+var requiredCRDs = map[string]bool{
+	"gatewayclasses." + gatewayv1beta1.GroupName: true,
+	"gateways." + gatewayv1beta1.GroupName:       true,
+	"httproutes." + gatewayv1beta1.GroupName:     true,
+}
+
+// Run performs all the checks. Errors encountered while checking are reported
+// as failed checks instead of aborting the remaining checks.
+func Run(ctx context.Context, params *types.Params) []Check {
+	var result []Check
+	result = append(result, checkGatewayAPICRDs(ctx, params)...)
+	result = append(result, checkGatewayClasses(ctx, params)...)
+	policyCRDChecks, policyCRDs := checkPolicyCRDs(ctx, params)
+	result = append(result, policyCRDChecks...)
+	result = append(result, checkAccess(ctx, params, policyCRDs)...)
+	return result
+}
+
+func checkGatewayAPICRDs(ctx context.Context, params *types.Params) []Check {
+	installedCRDs, err := gatewayapi.GetInstalledCRDs(ctx, params.DC)
+	if err != nil {
+		return []Check{{Name: "Gateway API CRDs", Status: StatusFail, Message: err.Error()}}
+	}
+	installed := make(map[string]gatewayapi.InstalledCRD)
+	for _, crd := range installedCRDs {
+		installed[crd.Name] = crd
+	}
+
+	var result []Check
+	for _, name := range gatewayapi.CoreCRDs {
+		check := Check{Name: fmt.Sprintf("CRD %v", name)}
+		crd, ok := installed[name]
+		switch {
+		case !ok && requiredCRDs[name]:
+			check.Status, check.Message = StatusFail, "not installed"
+		case !ok:
+			check.Status, check.Message = StatusWarn, "not installed"
+		case !isReadable(crd.ServedVersions):
+			check.Status = StatusFail
+			check.Message = fmt.Sprintf("served versions [%v] are not readable by gwctl, which reads [%v]", strings.Join(crd.ServedVersions, ", "), strings.Join(gatewayapi.KnownVersions, ", "))
+		default:
+			check.Status = StatusPass
+			check.Message = fmt.Sprintf("serving [%v]", strings.Join(crd.ServedVersions, ", "))
+			if crd.BundleVersion != "" {
+				check.Message += fmt.Sprintf(", bundle version %v", crd.BundleVersion)
+			}
+			if crd.Channel != "" {
+				check.Message += fmt.Sprintf(", %v channel", crd.Channel)
+			}
+		}
+		result = append(result, check)
+	}
+
+	bundleVersion, channel := gatewayapi.BundleVersionAndChannel(installedCRDs)
+	if strings.Contains(bundleVersion, ",") || strings.Contains(channel, ",") {
+		result = append(result, Check{
+			Name:    "Gateway API bundle",
+			Status:  StatusWarn,
+			Message: fmt.Sprintf("CRDs are installed from different bundles (versions: %v; channels: %v)", bundleVersion, channel),
+		})
+	}
+	return result
+}
+
+func isReadable(servedVersions []string) bool {
+	for _, served := range servedVersions {
+		for _, known := range gatewayapi.KnownVersions {
+			if served == known {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func checkGatewayClasses(ctx context.Context, params *types.Params) []Check {
+	gwcList, err := gatewayclasses.List(ctx, params)
+	if err != nil {
+		return []Check{{Name: "GatewayClasses", Status: StatusFail, Message: err.Error()}}
+	}
+	if len(gwcList) == 0 {
+		return []Check{{Name: "GatewayClasses", Status: StatusWarn, Message: "no GatewayClasses found"}}
+	}
+
+	var result []Check
+	for _, gwc := range gwcList {
+		check := Check{Name: fmt.Sprintf("GatewayClass %v", gwc.GetName())}
+		condition := apimeta.FindStatusCondition(gwc.Status.Conditions, string(gatewayv1beta1.GatewayClassConditionStatusAccepted))
+		switch {
+		case condition == nil:
+			check.Status = StatusWarn
+			check.Message = fmt.Sprintf("not yet Accepted; is the controller %q running?", gwc.Spec.ControllerName)
+		case condition.ObservedGeneration != 0 && condition.ObservedGeneration < gwc.GetGeneration():
+			check.Status = StatusWarn
+			check.Message = fmt.Sprintf("latest generation not yet observed by controller %q", gwc.Spec.ControllerName)
+		case condition.Status == metav1.ConditionTrue:
+			check.Status = StatusPass
+			check.Message = fmt.Sprintf("Accepted by controller %q", gwc.Spec.ControllerName)
+		default:
+			check.Status = StatusFail
+			check.Message = fmt.Sprintf("not Accepted by controller %q: %v: %v", gwc.Spec.ControllerName, condition.Reason, condition.Message)
+		}
+		result = append(result, check)
+	}
+	return result
+}
+
+// checkPolicyCRDs checks all CRDs having the policy label. The CRDs with a
+// valid label value are also returned.
+func checkPolicyCRDs(ctx context.Context, params *types.Params) ([]Check, []apiextensionsv1.CustomResourceDefinition) {
+	gvr := apiextensionsv1.SchemeGroupVersion.WithResource("customresourcedefinitions")
+	unstructuredCRDs, err := params.DC.Resource(gvr).List(ctx, metav1.ListOptions{LabelSelector: common.GatewayPolicyLabelKey})
+	if err != nil {
+		return []Check{{Name: "Policy CRDs", Status: StatusFail, Message: err.Error()}}, nil
+	}
+	crds := &apiextensionsv1.CustomResourceDefinitionList{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstructuredCRDs.UnstructuredContent(), crds); err != nil {
+		return []Check{{Name: "Policy CRDs", Status: StatusFail, Message: err.Error()}}, nil
+	}
+	if len(crds.Items) == 0 {
+		return []Check{{Name: "Policy CRDs", Status: StatusWarn, Message: fmt.Sprintf("no CRDs with the %q label found", common.GatewayPolicyLabelKey)}}, nil
+	}
+	sort.Slice(crds.Items, func(i, j int) bool { return crds.Items[i].GetName() < crds.Items[j].GetName() })
+
+	var result []Check
+	var validCRDs []apiextensionsv1.CustomResourceDefinition
+	for _, crd := range crds.Items {
+		check := Check{Name: fmt.Sprintf("Policy CRD %v", crd.GetName())}
+		labelValue := crd.GetLabels()[common.GatewayPolicyLabelKey]
+		if isValidPolicyLabelValue(labelValue) {
+			validCRDs = append(validCRDs, crd)
+		}
+		errors, warnings := policymanager.SplitSchemaIssues(policymanager.ValidateCRDSchema(crd, labelValue == "inherited"))
+		switch {
+		case !isValidPolicyLabelValue(labelValue):
+			check.Status = StatusFail
+			check.Message = fmt.Sprintf("invalid value %q for label %q, must be one of [%v]; the CRD is ignored", labelValue, common.GatewayPolicyLabelKey, strings.Join(validPolicyLabelValues, ", "))
+		case len(errors) != 0:
+			// The warnings are labelled as such amongst the errors.
+			for _, warning := range warnings {
+				errors = append(errors, fmt.Sprintf("%v: %v", policymanager.SchemaIssueWarning, warning))
+			}
+			check.Status = StatusFail
+			check.Message = strings.Join(errors, "; ")
+		case len(warnings) != 0:
+			check.Status = StatusWarn
+			check.Message = strings.Join(warnings, "; ")
+		default:
+			check.Status = StatusPass
+			check.Message = fmt.Sprintf("%v policy", labelValue)
+		}
+		result = append(result, check)
+	}
+	return result, validCRDs
+}
+
+func isValidPolicyLabelValue(value string) bool {
+	for _, valid := range validPolicyLabelValues {
+		if value == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAccess verifies that the user has the access needed to initialize the
+// PolicyManager (which lists the policies of each of the policyCRDs), and then
+// initializes a PolicyManager to confirm it.
+func checkAccess(ctx context.Context, params *types.Params, policyCRDs []apiextensionsv1.CustomResourceDefinition) []Check {
+	attributes := []authorizationv1.ResourceAttributes{
+		{Verb: "list", Group: apiextensionsv1.GroupName, Resource: "customresourcedefinitions"},
+		{Verb: "list", Group: gatewayv1beta1.GroupName, Resource: "referencegrants"},
+	}
+	for _, crd := range policyCRDs {
+		attributes = append(attributes, authorizationv1.ResourceAttributes{Verb: "list", Group: crd.Spec.Group, Resource: crd.Spec.Names.Plural})
+	}
+	var result []Check
+	for _, attr := range attributes {
+		attr := attr
+		check := Check{Name: fmt.Sprintf("Access %v %v", attr.Verb, qualifiedResource(attr))}
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attr},
+		}
+		if err := params.Client.Create(ctx, review); err != nil {
+			check.Status, check.Message = StatusFail, fmt.Sprintf("failed to review access: %v", err)
+		} else if !review.Status.Allowed {
+			check.Status, check.Message = StatusFail, "denied"
+			if review.Status.Reason != "" {
+				check.Message += ": " + review.Status.Reason
+			}
+		} else {
+			check.Status, check.Message = StatusPass, "allowed"
+		}
+		result = append(result, check)
+	}
+
+	check := Check{Name: "PolicyManager initialization", Status: StatusPass, Message: "succeeded"}
+	if err := policymanager.New(params.DC, params.Client).Init(ctx); err != nil {
+		check.Status, check.Message = StatusFail, err.Error()
+	}
+	return append(result, check)
+}
+
+func qualifiedResource(attr authorizationv1.ResourceAttributes) string {
+	if attr.Group == "" {
+		return attr.Resource
+	}
+	return attr.Resource + "." + attr.Group
+}
+
+// Print writes the checks as a checklist, followed by a summary. With an
+// output format, the checks are written in that format instead.
+func Print(params *types.Params, checks []Check) {
+	if params.Output != "" {
+		b, err := common.Marshal(params.Output, checks)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Fprint(params.Out, string(b))
+		return
+	}
+
+	counts := make(map[Status]int)
+	for _, check := range checks {
+		counts[check.Status]++
+		fmt.Fprintf(params.Out, "[%v] %v: %v\n", check.Status, check.Name, check.Message)
+	}
+	fmt.Fprintf(params.Out, "\n%d passed, %d warnings, %d failed\n", counts[StatusPass], counts[StatusWarn], counts[StatusFail])
+}
+
+// HasFailures returns true if any of the checks failed.
+func HasFailures(checks []Check) bool {
+	for _, check := range checks {
+		if check.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
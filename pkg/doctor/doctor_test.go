@@ -0,0 +1,187 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+	"github.com/gauravkghildiyal/gwctl/pkg/gatewayapi"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+)
+
+// accessReviewClient answers SelfSubjectAccessReviews by allowing access to
+// all resources except the denied ones.
+type accessReviewClient struct {
+	client.Client
+	denied map[string]bool
+}
+
+func (c *accessReviewClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	review, ok := obj.(*authorizationv1.SelfSubjectAccessReview)
+	if !ok {
+		return c.Client.Create(ctx, obj, opts...)
+	}
+	attr := review.Spec.ResourceAttributes
+	review.Status.Allowed = !c.denied[attr.Resource+"."+attr.Group]
+	if !review.Status.Allowed {
+		review.Status.Reason = "no RBAC policy matched"
+	}
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	gatewayAPICRD := func(name string, versions ...string) *apiextensionsv1.CustomResourceDefinition {
+		crd := &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Annotations: map[string]string{
+					gatewayapi.BundleVersionAnnotation: "v0.7.1",
+					gatewayapi.ChannelAnnotation:       "standard",
+				},
+			},
+		}
+		for _, version := range versions {
+			crd.Spec.Versions = append(crd.Spec.Versions, apiextensionsv1.CustomResourceDefinitionVersion{Name: version, Served: true})
+		}
+		return crd
+	}
+	policyCRD := func(group, kind, plural, labelValue string, schema *apiextensionsv1.CustomResourceValidation) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: plural + "." + group,
+				Labels: map[string]string{
+					common.GatewayPolicyLabelKey: labelValue,
+				},
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Scope:    apiextensionsv1.ClusterScoped,
+				Group:    group,
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1", Served: true, Schema: schema}},
+				Names: apiextensionsv1.CustomResourceDefinitionNames{
+					Plural: plural,
+					Kind:   kind,
+				},
+			},
+		}
+	}
+	policy := func(group, kind, name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": group + "/v1",
+				"kind":       kind,
+				"metadata": map[string]interface{}{
+					"name": name,
+				},
+				"spec": map[string]interface{}{
+					"targetRef": map[string]interface{}{
+						"kind": "Namespace",
+						"name": "default",
+					},
+				},
+			},
+		}
+	}
+	schemaWithSpec := func(specProperties ...string) *apiextensionsv1.CustomResourceValidation {
+		str := apiextensionsv1.JSONSchemaProps{Type: "string"}
+		ref := apiextensionsv1.JSONSchemaProps{Type: "object", Properties: map[string]apiextensionsv1.JSONSchemaProps{"group": str, "kind": str, "name": str}}
+		spec := apiextensionsv1.JSONSchemaProps{Type: "object", Properties: map[string]apiextensionsv1.JSONSchemaProps{}}
+		for _, property := range specProperties {
+			spec.Properties[property] = apiextensionsv1.JSONSchemaProps{Type: "object"}
+			if property == "targetRef" {
+				spec.Properties[property] = ref
+			}
+		}
+		status := apiextensionsv1.JSONSchemaProps{Type: "object", Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"ancestors": {Type: "array", Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &apiextensionsv1.JSONSchemaProps{
+				Type:       "object",
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{"ancestorRef": ref, "controllerName": str, "conditions": {Type: "array"}},
+			}}},
+		}}
+		return &apiextensionsv1.CustomResourceValidation{
+			OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+				Type:       "object",
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{"spec": spec, "status": status},
+			},
+		}
+	}
+
+	objects := []runtime.Object{
+		gatewayAPICRD("gatewayclasses.gateway.networking.k8s.io", "v1beta1"),
+		gatewayAPICRD("gateways.gateway.networking.k8s.io", "v1beta1"),
+		gatewayAPICRD("referencegrants.gateway.networking.k8s.io", "v2"),
+
+		&gatewayv1beta1.GatewayClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "accepted-gatewayclass", Generation: 2},
+			Spec:       gatewayv1beta1.GatewayClassSpec{ControllerName: "example.net/gateway-controller"},
+			Status: gatewayv1beta1.GatewayClassStatus{
+				Conditions: []metav1.Condition{{Type: "Accepted", Status: metav1.ConditionTrue, ObservedGeneration: 2}},
+			},
+		},
+		&gatewayv1beta1.GatewayClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "rejected-gatewayclass"},
+			Spec:       gatewayv1beta1.GatewayClassSpec{ControllerName: "example.net/gateway-controller"},
+			Status: gatewayv1beta1.GatewayClassStatus{
+				Conditions: []metav1.Condition{{Type: "Accepted", Status: metav1.ConditionFalse, Reason: "InvalidParameters", Message: "parametersRef not found"}},
+			},
+		},
+		&gatewayv1beta1.GatewayClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "unknown-gatewayclass"},
+			Spec:       gatewayv1beta1.GatewayClassSpec{ControllerName: "example.net/missing-controller"},
+		},
+
+		policyCRD("foo.com", "HealthCheckPolicy", "healthcheckpolicies", "inherited", schemaWithSpec("targetRef", "default")),
+		policy("foo.com", "HealthCheckPolicy", "health-check"),
+		policyCRD("bar.com", "TimeoutPolicy", "timeoutpolicies", "direct", schemaWithSpec("seconds")),
+		policy("bar.com", "TimeoutPolicy", "timeout"),
+		policyCRD("baz.com", "RetryPolicy", "retrypolicies", "true", nil),
+		policy("baz.com", "RetryPolicy", "retry"),
+		policyCRD("qux.com", "TracePolicy", "tracepolicies", "yes", schemaWithSpec("targetRef")),
+	}
+
+	params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
+	params.Client = &accessReviewClient{
+		Client: params.Client,
+		denied: map[string]bool{"referencegrants.gateway.networking.k8s.io": true},
+	}
+
+	checks := Run(context.Background(), params)
+	Print(params, checks)
+
+	got := params.Out.(*bytes.Buffer).String()
+	want := `[PASS] CRD gatewayclasses.gateway.networking.k8s.io: serving [v1beta1], bundle version v0.7.1, standard channel
+[PASS] CRD gateways.gateway.networking.k8s.io: serving [v1beta1], bundle version v0.7.1, standard channel
+[FAIL] CRD httproutes.gateway.networking.k8s.io: not installed
+[FAIL] CRD referencegrants.gateway.networking.k8s.io: served versions [v2] are not readable by gwctl, which reads [v1, v1beta1, v1alpha2]
+[PASS] GatewayClass accepted-gatewayclass: Accepted by controller "example.net/gateway-controller"
+[FAIL] GatewayClass rejected-gatewayclass: not Accepted by controller "example.net/gateway-controller": InvalidParameters: parametersRef not found
+[WARN] GatewayClass unknown-gatewayclass: not yet Accepted; is the controller "example.net/missing-controller" running?
+[PASS] Policy CRD healthcheckpolicies.foo.com: inherited policy
+[WARN] Policy CRD retrypolicies.baz.com: v1: no schema, so the conventions cannot be verified
+[FAIL] Policy CRD timeoutpolicies.bar.com: v1: spec.targetRef (or spec.targetRefs) is missing
+[FAIL] Policy CRD tracepolicies.qux.com: invalid value "yes" for label "gateway.networking.k8s.io/policy", must be one of [true, direct, inherited]; the CRD is ignored
+[PASS] Access list customresourcedefinitions.apiextensions.k8s.io: allowed
+[FAIL] Access list referencegrants.gateway.networking.k8s.io: denied: no RBAC policy matched
+[PASS] Access list healthcheckpolicies.foo.com: allowed
+[PASS] Access list retrypolicies.baz.com: allowed
+[PASS] Access list timeoutpolicies.bar.com: allowed
+[PASS] PolicyManager initialization: succeeded
+
+9 passed, 2 warnings, 6 failed
+`
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unexpected diff\ngot=\n%v\nwant=\n%v\ndiff (-want +got)=\n%v", got, want, diff)
+	}
+	if !HasFailures(checks) {
+		t.Errorf("HasFailures()=false; want true")
+	}
+}
@@ -0,0 +1,203 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// NewClient returns a client which reads every object at most once, and logs
+// the duration of every request it issues.
+//
+// The results of Gets and Lists are cached for the lifetime of the client,
+// which is a single invocation of gwctl, and concurrent reads of the same
+// object wait for the request in flight. Gets are also served from the cached
+// Lists of the namespace (or of all namespaces), and Lists within a namespace
+// from the cached List of all namespaces. Reads with options other than the
+// namespace, and writes, are passed through without caching.
+func NewClient(c client.Client) client.Client {
+	return &cachingClient{Client: c, entries: make(map[string]*entry)}
+}
+
+type cachingClient struct {
+	client.Client
+
+	mu sync.Mutex
+	// entries maps the key of every Get and List to its result, including
+	// those in flight.
+	entries map[string]*entry
+}
+
+type entry struct {
+	// done is closed once obj and err are set.
+	done chan struct{}
+	obj  runtime.Object
+	err  error
+}
+
+func getKey(gvk schema.GroupVersionKind, key client.ObjectKey) string {
+	return fmt.Sprintf("get %v %v", gvk, key)
+}
+
+func listKey(gvk schema.GroupVersionKind, namespace string) string {
+	return fmt.Sprintf("list %v %v", gvk, namespace)
+}
+
+// do returns the result of the entry with the key, calling fn to fill it
+// unless it is already cached or in flight. Successes and NotFound errors are
+// cached, while other errors are dropped such that failing requests are
+// retried.
+func (c *cachingClient) do(ctx context.Context, key string, fn func() (runtime.Object, error)) (runtime.Object, error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if !ok {
+		e = &entry{done: make(chan struct{})}
+		c.entries[key] = e
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		e.obj, e.err = fn()
+		if e.err != nil && !apierrors.IsNotFound(e.err) {
+			c.mu.Lock()
+			delete(c.entries, key)
+			c.mu.Unlock()
+		}
+		close(e.done)
+	} else {
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return e.obj, e.err
+}
+
+// cachedList returns the completed List of the kind within the namespace, or
+// else of all namespaces, if it is cached.
+func (c *cachingClient) cachedList(listGVK schema.GroupVersionKind, namespace string) (runtime.Object, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, ns := range []string{namespace, ""} {
+		e, ok := c.entries[listKey(listGVK, ns)]
+		if !ok {
+			continue
+		}
+		select {
+		case <-e.done:
+			if e.err == nil {
+				return e.obj, true
+			}
+		default:
+		}
+	}
+	return nil, false
+}
+
+func (c *cachingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil || len(opts) != 0 {
+		defer Timed("get %v %v", gvk.Kind, key)()
+		return c.Client.Get(ctx, key, obj, opts...)
+	}
+
+	listGVK := gvk.GroupVersion().WithKind(gvk.Kind + "List")
+	if list, ok := c.cachedList(listGVK, key.Namespace); ok {
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			o, ok := item.(client.Object)
+			if ok && o.GetNamespace() == key.Namespace && o.GetName() == key.Name {
+				return copyInto(obj, o)
+			}
+		}
+		return apierrors.NewNotFound(c.groupResource(gvk), key.Name)
+	}
+
+	result, err := c.do(ctx, getKey(gvk, key), func() (runtime.Object, error) {
+		defer Timed("get %v %v", gvk.Kind, key)()
+		o := obj.DeepCopyObject().(client.Object)
+		return o, c.Client.Get(ctx, key, o)
+	})
+	if err != nil {
+		return err
+	}
+	return copyInto(obj, result)
+}
+
+// groupResource returns the resource of the kind, as used by the NotFound
+// errors of the API server. The resource is guessed from the kind if the
+// RESTMapper cannot map it.
+func (c *cachingClient) groupResource(gvk schema.GroupVersionKind) schema.GroupResource {
+	if mapping, err := c.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err == nil {
+		return mapping.Resource.GroupResource()
+	}
+	plural, _ := meta.UnsafeGuessKindToResource(gvk)
+	return plural.GroupResource()
+}
+
+func (c *cachingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	gvk, err := apiutil.GVKForObject(list, c.Scheme())
+	listOptions := &client.ListOptions{}
+	listOptions.ApplyOptions(opts)
+	namespace := listOptions.Namespace
+	listOptions.Namespace = ""
+	if err != nil || !reflect.DeepEqual(*listOptions, client.ListOptions{}) {
+		defer Timed("list %v", gvk.Kind)()
+		return c.Client.List(ctx, list, opts...)
+	}
+
+	// A List within a namespace is served from the List of all namespaces.
+	if namespace != "" {
+		if allNamespaces, ok := c.cachedList(gvk, ""); ok {
+			return filterInto(list, allNamespaces, namespace)
+		}
+	}
+
+	result, err := c.do(ctx, listKey(gvk, namespace), func() (runtime.Object, error) {
+		defer Timed("list %v %v", gvk.Kind, namespace)()
+		l := list.DeepCopyObject().(client.ObjectList)
+		return l, c.Client.List(ctx, l, opts...)
+	})
+	if err != nil {
+		return err
+	}
+	return copyInto(list, result)
+}
+
+// copyInto sets dst to a copy of src, since callers may modify the objects
+// they read.
+func copyInto(dst, src runtime.Object) error {
+	dstValue, srcValue := reflect.ValueOf(dst), reflect.ValueOf(src.DeepCopyObject())
+	if dstValue.Type() != srcValue.Type() {
+		return fmt.Errorf("cannot read %T into %T", src, dst)
+	}
+	dstValue.Elem().Set(srcValue.Elem())
+	return nil
+}
+
+// filterInto sets dst to a copy of the items of src within the namespace.
+func filterInto(dst client.ObjectList, src runtime.Object, namespace string) error {
+	items, err := meta.ExtractList(src.DeepCopyObject())
+	if err != nil {
+		return err
+	}
+	var filtered []runtime.Object
+	for _, item := range items {
+		if o, ok := item.(client.Object); ok && o.GetNamespace() == namespace {
+			filtered = append(filtered, o)
+		}
+	}
+	return meta.SetList(dst, filtered)
+}
@@ -0,0 +1,90 @@
+// Package fetch issues the requests of a command to the API server
+// concurrently.
+//
+// A single command may need many objects, like the policies of every Policy CRD
+// or the Gateways of every HTTPRoute being described. Issuing these requests one
+// after the other makes the latency of the command grow with the size of the
+// cluster, so they are instead spread across a bounded number of workers (such
+// that the API server is not flooded). The same objects are also needed again
+// and again while computing effective policies, so the client of a command
+// (see NewClient) reads every object at most once.
+//
+// The duration of every request is logged at verbosity 4 (-v=4).
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// Workers bounds the number of requests which ForEach issues concurrently.
+const Workers = 8
+
+// ForEach calls fn for every index within [0, n) concurrently, using at most
+// Workers goroutines. The first error returned by fn cancels the context passed
+// to the remaining calls, which are then skipped, and is returned. Cancelling
+// ctx similarly skips the remaining calls and returns the error of ctx.
+func ForEach(ctx context.Context, n int, fn func(ctx context.Context, i int) error) error {
+	return forEach(ctx, Workers, n, fn)
+}
+
+func forEach(ctx context.Context, workers, n int, fn func(ctx context.Context, i int) error) error {
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var once sync.Once
+	var firstErr error
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := fn(workerCtx, i); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+	var skipped bool
+	for i := 0; i < n && !skipped; i++ {
+		select {
+		case indexes <- i:
+		case <-workerCtx.Done():
+			skipped = true
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if skipped {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// Timed logs the duration of the request described by format and args once the
+// returned function is called, like:
+//
+//	defer fetch.Timed("list %v", gvr)()
+func Timed(format string, args ...interface{}) func() {
+	if !klog.V(4).Enabled() {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		klog.V(4).Infof("%v took %v", fmt.Sprintf(format, args...), time.Since(start))
+	}
+}
@@ -0,0 +1,214 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachinerytypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+)
+
+func TestForEach(t *testing.T) {
+	t.Run("bounds the concurrent calls", func(t *testing.T) {
+		var running, maxRunning, calls int32
+		err := forEach(context.Background(), 3, 20, func(ctx context.Context, i int) error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			atomic.AddInt32(&calls, 1)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("forEach() failed: %v", err)
+		}
+		if calls != 20 {
+			t.Errorf("forEach() made %v calls; want 20", calls)
+		}
+		if maxRunning > 3 {
+			t.Errorf("forEach() made %v concurrent calls; want at most 3", maxRunning)
+		}
+	})
+
+	t.Run("first error cancels the remaining calls", func(t *testing.T) {
+		var calls int32
+		err := forEach(context.Background(), 1, 20, func(ctx context.Context, i int) error {
+			atomic.AddInt32(&calls, 1)
+			if i == 2 {
+				return fmt.Errorf("failed %v", i)
+			}
+			return nil
+		})
+		if err == nil || err.Error() != "failed 2" {
+			t.Errorf("forEach() = %v; want error of the third call", err)
+		}
+		if calls >= 20 {
+			t.Errorf("forEach() made %v calls; want the calls after the error to be skipped", calls)
+		}
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := forEach(ctx, 2, 20, func(ctx context.Context, i int) error {
+			return ctx.Err()
+		})
+		if err != context.Canceled {
+			t.Errorf("forEach() = %v; want %v", err, context.Canceled)
+		}
+	})
+}
+
+// mappedClient maps kinds to resources through mapper.
+type mappedClient struct {
+	client.Client
+	mapper meta.RESTMapper
+}
+
+func (c *mappedClient) RESTMapper() meta.RESTMapper {
+	return c.mapper
+}
+
+func TestClient(t *testing.T) {
+	fakeClients := common.MustClientsForTest(t,
+		&gatewayv1beta1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-gateway",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "foo"},
+			},
+			Spec: gatewayv1beta1.GatewaySpec{GatewayClassName: "foo-gatewayclass"},
+		},
+		&gatewayv1beta1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "bar-gateway",
+				Namespace: "ns1",
+			},
+			Spec: gatewayv1beta1.GatewaySpec{GatewayClassName: "bar-gatewayclass"},
+		},
+	)
+	// The fake client maps no kinds to resources, unlike the API server.
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.AddSpecific(gatewayv1beta1.SchemeGroupVersion.WithKind("Gateway"), gatewayv1beta1.SchemeGroupVersion.WithResource("gateways"), gatewayv1beta1.SchemeGroupVersion.WithResource("gateway"), meta.RESTScopeNamespace)
+	counting := common.NewCountingClient(&mappedClient{Client: fakeClients.Client, mapper: mapper})
+	counting.Release = make(chan struct{})
+	c := NewClient(counting)
+	fooKey := apimachinerytypes.NamespacedName{Namespace: "default", Name: "foo-gateway"}
+
+	// Concurrent Gets of the same Gateway are issued once.
+	gws := make([]gatewayv1beta1.Gateway, 5)
+	errs := make([]error, len(gws))
+	var wg sync.WaitGroup
+	for i := range gws {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = c.Get(context.Background(), fooKey, &gws[i])
+		}(i)
+	}
+	// Give the Gets time to start, such that they are in flight together.
+	time.Sleep(50 * time.Millisecond)
+	close(counting.Release)
+	wg.Wait()
+
+	for i := range gws {
+		if errs[i] != nil {
+			t.Fatalf("Get() failed: %v", errs[i])
+		}
+		if gws[i].Spec.GatewayClassName != "foo-gatewayclass" {
+			t.Errorf("Get() returned unexpected Gateway %+v", gws[i])
+		}
+	}
+	if counting.Count("get") != 1 {
+		t.Errorf("Get() issued %v requests; want 1", counting.Count("get"))
+	}
+
+	// Later Gets are served from the cache, which is not affected by callers
+	// modifying the objects they read.
+	gws[0].Spec.GatewayClassName = "modified"
+	gw := &gatewayv1beta1.Gateway{}
+	if err := c.Get(context.Background(), fooKey, gw); err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if gw.Spec.GatewayClassName != "foo-gatewayclass" {
+		t.Errorf("Get() returned modified Gateway %+v", gw)
+	}
+	if counting.Count("get") != 1 {
+		t.Errorf("Get() issued %v requests; want 1", counting.Count("get"))
+	}
+
+	// NotFound errors are cached as well.
+	for i := 0; i < 2; i++ {
+		err := c.Get(context.Background(), apimachinerytypes.NamespacedName{Namespace: "default", Name: "unknown-gateway"}, &gatewayv1beta1.Gateway{})
+		if !apierrors.IsNotFound(err) {
+			t.Errorf("Get() of unknown Gateway = %v; want NotFound", err)
+		}
+	}
+	if counting.Count("get") != 2 {
+		t.Errorf("Get() issued %v requests; want 2", counting.Count("get"))
+	}
+
+	// The List of all namespaces serves Lists within a namespace, and Gets of
+	// objects which were not read before.
+	allGateways := &gatewayv1beta1.GatewayList{}
+	if err := c.List(context.Background(), allGateways); err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(allGateways.Items) != 2 {
+		t.Errorf("List() returned %v Gateways; want 2", len(allGateways.Items))
+	}
+	ns1Gateways := &gatewayv1beta1.GatewayList{}
+	if err := c.List(context.Background(), ns1Gateways, client.InNamespace("ns1")); err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(ns1Gateways.Items) != 1 || ns1Gateways.Items[0].Name != "bar-gateway" {
+		t.Errorf("List() within ns1 returned unexpected Gateways %+v", ns1Gateways.Items)
+	}
+	if err := c.Get(context.Background(), apimachinerytypes.NamespacedName{Namespace: "ns1", Name: "bar-gateway"}, gw); err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if gw.Spec.GatewayClassName != "bar-gatewayclass" {
+		t.Errorf("Get() returned unexpected Gateway %+v", gw)
+	}
+	// The NotFound error served from the List names the resource like the
+	// API server.
+	err := c.Get(context.Background(), apimachinerytypes.NamespacedName{Namespace: "ns1", Name: "unknown-gateway"}, gw)
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("Get() of unknown Gateway = %v; want NotFound", err)
+	}
+	if want := `gateways.gateway.networking.k8s.io "unknown-gateway" not found`; err == nil || err.Error() != want {
+		t.Errorf("Get() of unknown Gateway = %v; want %v", err, want)
+	}
+	if counting.Count("get") != 2 || counting.Count("list") != 1 {
+		t.Errorf("Get() and List() issued %v and %v requests; want 2 and 1", counting.Count("get"), counting.Count("list"))
+	}
+
+	// Lists with other options are not cached.
+	for i := 0; i < 2; i++ {
+		selected := &gatewayv1beta1.GatewayList{}
+		if err := c.List(context.Background(), selected, client.MatchingLabels{"app": "foo"}); err != nil {
+			t.Fatalf("List() failed: %v", err)
+		}
+		if len(selected.Items) != 1 {
+			t.Errorf("List() by label returned %v Gateways; want 1", len(selected.Items))
+		}
+	}
+	if counting.Count("list") != 3 {
+		t.Errorf("List() issued %v requests; want 3", counting.Count("list"))
+	}
+}
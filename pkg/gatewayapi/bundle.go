@@ -0,0 +1,98 @@
+package gatewayapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+const (
+	// BundleVersionAnnotation is set on the CRDs of the Gateway API to the
+	// version of the release (like "v0.7.1") they were installed from.
+	BundleVersionAnnotation = "gateway.networking.k8s.io/bundle-version"
+	// ChannelAnnotation is set on the CRDs of the Gateway API to the release
+	// channel ("standard" or "experimental") they were installed from.
+	ChannelAnnotation = "gateway.networking.k8s.io/channel"
+)
+
+// CoreCRDs are the names of the Gateway API CRDs which gwctl reads.
+var CoreCRDs = []string{
+	"gatewayclasses." + gatewayv1beta1.GroupName,
+	"gateways." + gatewayv1beta1.GroupName,
+	"httproutes." + gatewayv1beta1.GroupName,
+	"referencegrants." + gatewayv1beta1.GroupName,
+}
+
+// InstalledCRD describes a Gateway API CRD installed in the cluster.
+type InstalledCRD struct {
+	Name          string
+	BundleVersion string
+	Channel       string
+	// ServedVersions are the versions of the CRD which are served.
+	ServedVersions []string
+}
+
+// GetInstalledCRDs returns the CoreCRDs which are installed in the cluster.
+// CRDs which are not installed are omitted.
+func GetInstalledCRDs(ctx context.Context, dc dynamic.Interface) ([]InstalledCRD, error) {
+	gvr := apiextensionsv1.SchemeGroupVersion.WithResource("customresourcedefinitions")
+
+	var result []InstalledCRD
+	for _, name := range CoreCRDs {
+		u, err := dc.Resource(gvr).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get CRD %v: %v", name, err)
+		}
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), crd); err != nil {
+			return nil, fmt.Errorf("failed to convert unstructured CRD %v to structured: %v", name, err)
+		}
+
+		installedCRD := InstalledCRD{
+			Name:          crd.GetName(),
+			BundleVersion: crd.GetAnnotations()[BundleVersionAnnotation],
+			Channel:       crd.GetAnnotations()[ChannelAnnotation],
+		}
+		for _, version := range crd.Spec.Versions {
+			if version.Served {
+				installedCRD.ServedVersions = append(installedCRD.ServedVersions, version.Name)
+			}
+		}
+		result = append(result, installedCRD)
+	}
+	return result, nil
+}
+
+// BundleVersionAndChannel summarizes the bundle version and channel of the
+// installed CRDs. If the CRDs were installed from different bundles (or
+// channels), all the distinct values are returned, comma separated.
+func BundleVersionAndChannel(crds []InstalledCRD) (string, string) {
+	var versions, channels []string
+	for _, crd := range crds {
+		versions = appendIfMissing(versions, crd.BundleVersion)
+		channels = appendIfMissing(channels, crd.Channel)
+	}
+	return strings.Join(versions, ", "), strings.Join(channels, ", ")
+}
+
+func appendIfMissing(values []string, value string) []string {
+	if value == "" {
+		return values
+	}
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,106 @@
+package gatewayapi
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// NewClient returns a client which reads Gateway API objects in the newest
+// version served by the cluster, and converts them into the internal model.
+// Objects which are served in the InternalVersion (and objects of other APIs)
+// are read through c as-is.
+func NewClient(c client.Client, dc dynamic.Interface, served *ServedVersions) client.Client {
+	return &versionedClient{Client: c, dc: dc, served: served}
+}
+
+type versionedClient struct {
+	client.Client
+	dc     dynamic.Interface
+	served *ServedVersions
+}
+
+func (c *versionedClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	gvr, ok := c.servedGVR(obj)
+	if !ok {
+		return c.Client.Get(ctx, key, obj, opts...)
+	}
+
+	getOptions := &client.GetOptions{}
+	getOptions.ApplyOptions(opts)
+	u, err := c.dc.Resource(gvr).Namespace(key.Namespace).Get(ctx, key.Name, *getOptions.AsGetOptions())
+	if err != nil {
+		return err
+	}
+	return toInternal(u.UnstructuredContent(), obj)
+}
+
+func (c *versionedClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	gvr, ok := c.servedGVR(list)
+	if !ok {
+		return c.Client.List(ctx, list, opts...)
+	}
+
+	listOptions := &client.ListOptions{}
+	listOptions.ApplyOptions(opts)
+	u, err := c.dc.Resource(gvr).Namespace(listOptions.Namespace).List(ctx, *listOptions.AsListOptions())
+	if err != nil {
+		return err
+	}
+	return toInternal(u.UnstructuredContent(), list)
+}
+
+// servedGVR returns the GroupVersionResource with which obj needs to be read,
+// if it needs to be read in a version different from the InternalVersion.
+func (c *versionedClient) servedGVR(obj runtime.Object) (schema.GroupVersionResource, bool) {
+	resource := resourceFor(obj)
+	if resource == "" {
+		return schema.GroupVersionResource{}, false
+	}
+	version := c.served.PreferredVersion(resource)
+	if version == "" || version == InternalVersion {
+		return schema.GroupVersionResource{}, false
+	}
+	return schema.GroupVersionResource{Group: gatewayv1beta1.GroupName, Version: version, Resource: resource}, true
+}
+
+// resourceFor returns the Gateway API resource for the internal model object
+// (or list), or an empty string if obj is not a Gateway API object.
+func resourceFor(obj runtime.Object) string {
+	switch obj.(type) {
+	case *gatewayv1beta1.GatewayClass, *gatewayv1beta1.GatewayClassList:
+		return "gatewayclasses"
+	case *gatewayv1beta1.Gateway, *gatewayv1beta1.GatewayList:
+		return "gateways"
+	case *gatewayv1beta1.HTTPRoute, *gatewayv1beta1.HTTPRouteList:
+		return "httproutes"
+	case *gatewayv1beta1.ReferenceGrant, *gatewayv1beta1.ReferenceGrantList:
+		return "referencegrants"
+	}
+	return ""
+}
+
+// toInternal converts the unstructured content of an object (or list) read in
+// some other version into the internal model. Fields unknown to the internal
+// model are dropped.
+func toInternal(content map[string]interface{}, obj runtime.Object) error {
+	apiVersion := gatewayv1beta1.GroupVersion.String()
+	content["apiVersion"] = apiVersion
+	if items, ok := content["items"].([]interface{}); ok {
+		for _, item := range items {
+			if itemContent, ok := item.(map[string]interface{}); ok {
+				itemContent["apiVersion"] = apiVersion
+			}
+		}
+	}
+
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, obj); err != nil {
+		return fmt.Errorf("failed to convert %T into the internal model: %v", obj, err)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package gatewayapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamicclient "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+func newFakeDiscovery(resources ...*metav1.APIResourceList) *fakediscovery.FakeDiscovery {
+	return &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: resources}}
+}
+
+func TestDiscoverServedVersions(t *testing.T) {
+	discoveryClient := newFakeDiscovery(
+		&metav1.APIResourceList{
+			GroupVersion: "gateway.networking.k8s.io/v1",
+			APIResources: []metav1.APIResource{{Name: "gatewayclasses"}, {Name: "gateways"}, {Name: "httproutes"}},
+		},
+		&metav1.APIResourceList{
+			GroupVersion: "gateway.networking.k8s.io/v1beta1",
+			APIResources: []metav1.APIResource{{Name: "gatewayclasses"}, {Name: "gateways"}, {Name: "httproutes"}, {Name: "referencegrants"}},
+		},
+		&metav1.APIResourceList{
+			GroupVersion: "gateway.networking.k8s.io/v1alpha2",
+			APIResources: []metav1.APIResource{{Name: "grpcroutes"}, {Name: "referencegrants"}},
+		},
+	)
+
+	got, err := DiscoverServedVersions(discoveryClient)
+	if err != nil {
+		t.Fatalf("DiscoverServedVersions() failed: %v", err)
+	}
+	want := &ServedVersions{
+		Versions: []string{"v1", "v1beta1", "v1alpha2"},
+		ResourceVersions: map[string]string{
+			"gatewayclasses":  "v1",
+			"gateways":        "v1",
+			"httproutes":      "v1",
+			"referencegrants": "v1beta1",
+			"grpcroutes":      "v1alpha2",
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("DiscoverServedVersions() returned unexpected diff (-want +got)=\n%v", diff)
+	}
+}
+
+func TestClient(t *testing.T) {
+	httpRoute := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "gateway.networking.k8s.io/v1",
+			"kind":       "HTTPRoute",
+			"metadata": map[string]interface{}{
+				"name":      "foo-httproute",
+				"namespace": "default",
+			},
+			"spec": map[string]interface{}{
+				"hostnames": []interface{}{"example.com"},
+				"parentRefs": []interface{}{
+					map[string]interface{}{"name": "foo-gateway"},
+				},
+			},
+		},
+	}
+	gvr := schema.GroupVersionResource{Group: gatewayv1beta1.GroupName, Version: "v1", Resource: "httproutes"}
+	dc := fakedynamicclient.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "HTTPRouteList"}, httpRoute)
+
+	// The typed client does not know about any objects, so all Gateway API
+	// objects must be read through the dynamic client.
+	scheme := runtime.NewScheme()
+	gatewayv1beta1.AddToScheme(scheme)
+	typedClient := fakeclient.NewClientBuilder().WithScheme(scheme).Build()
+
+	served := &ServedVersions{
+		Versions:         []string{"v1"},
+		ResourceVersions: map[string]string{"httproutes": "v1"},
+	}
+	c := NewClient(typedClient, dc, served)
+
+	wantSpec := gatewayv1beta1.HTTPRouteSpec{
+		CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
+			ParentRefs: []gatewayv1beta1.ParentReference{{Name: "foo-gateway"}},
+		},
+		Hostnames: []gatewayv1beta1.Hostname{"example.com"},
+	}
+
+	got := &gatewayv1beta1.HTTPRoute{}
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "foo-httproute"}, got); err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if got.APIVersion != gatewayv1beta1.GroupVersion.String() {
+		t.Errorf("Get() returned apiVersion %q; want %q", got.APIVersion, gatewayv1beta1.GroupVersion.String())
+	}
+	if diff := cmp.Diff(wantSpec, got.Spec); diff != "" {
+		t.Errorf("Get() returned unexpected diff (-want +got)=\n%v", diff)
+	}
+
+	gotList := &gatewayv1beta1.HTTPRouteList{}
+	if err := c.List(context.Background(), gotList, client.InNamespace("default")); err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(gotList.Items) != 1 {
+		t.Fatalf("List() returned %d items; want 1", len(gotList.Items))
+	}
+	if diff := cmp.Diff(wantSpec, gotList.Items[0].Spec); diff != "" {
+		t.Errorf("List() returned unexpected diff (-want +got)=\n%v", diff)
+	}
+
+	// Gateways are not served in any other version, so they must be read
+	// through the typed client.
+	if err := c.List(context.Background(), &gatewayv1beta1.GatewayList{}); err != nil {
+		t.Errorf("List() for Gateways failed: %v", err)
+	}
+}
+
+func TestGetInstalledCRDs(t *testing.T) {
+	newCRD := func(name, bundleVersion, channel string, versions ...string) *apiextensionsv1.CustomResourceDefinition {
+		crd := &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Annotations: map[string]string{
+					BundleVersionAnnotation: bundleVersion,
+					ChannelAnnotation:       channel,
+				},
+			},
+		}
+		for _, version := range versions {
+			crd.Spec.Versions = append(crd.Spec.Versions, apiextensionsv1.CustomResourceDefinitionVersion{Name: version, Served: true})
+		}
+		// A version which is no longer served.
+		crd.Spec.Versions = append(crd.Spec.Versions, apiextensionsv1.CustomResourceDefinitionVersion{Name: "v1alpha2"})
+		return crd
+	}
+
+	scheme := runtime.NewScheme()
+	apiextensionsv1.AddToScheme(scheme)
+	dc := fakedynamicclient.NewSimpleDynamicClient(scheme,
+		newCRD("gateways.gateway.networking.k8s.io", "v1.0.0", "standard", "v1", "v1beta1"),
+		newCRD("httproutes.gateway.networking.k8s.io", "v1.0.0", "experimental", "v1", "v1beta1"),
+	)
+
+	got, err := GetInstalledCRDs(context.Background(), dc)
+	if err != nil {
+		t.Fatalf("GetInstalledCRDs() failed: %v", err)
+	}
+	want := []InstalledCRD{
+		{Name: "gateways.gateway.networking.k8s.io", BundleVersion: "v1.0.0", Channel: "standard", ServedVersions: []string{"v1", "v1beta1"}},
+		{Name: "httproutes.gateway.networking.k8s.io", BundleVersion: "v1.0.0", Channel: "experimental", ServedVersions: []string{"v1", "v1beta1"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetInstalledCRDs() returned unexpected diff (-want +got)=\n%v", diff)
+	}
+
+	gotBundleVersion, gotChannel := BundleVersionAndChannel(got)
+	if gotBundleVersion != "v1.0.0" {
+		t.Errorf("BundleVersionAndChannel() returned bundle version %q; want %q", gotBundleVersion, "v1.0.0")
+	}
+	if gotChannel != "standard, experimental" {
+		t.Errorf("BundleVersionAndChannel() returned channel %q; want %q", gotChannel, "standard, experimental")
+	}
+}
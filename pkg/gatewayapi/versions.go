@@ -0,0 +1,88 @@
+// Package gatewayapi handles the differences between the Gateway API versions
+// served by a cluster.
+//
+// gwctl uses the v1beta1 types as its internal model of the Gateway API. A
+// cluster may however serve a Gateway API resource only in some newer (or
+// older) version. This package discovers the versions served by the cluster,
+// reads each resource in the newest version served for it and converts it into
+// the internal model.
+package gatewayapi
+
+import (
+	"fmt"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// KnownVersions are the versions of the Gateway API which gwctl can read,
+// ordered from newest to oldest. All of them are structurally compatible with
+// the internal model for the resources gwctl reads.
+var KnownVersions = []string{"v1", "v1beta1", "v1alpha2"}
+
+// InternalVersion is the version of the Gateway API types used as the
+// internal model within gwctl.
+var InternalVersion = gatewayv1beta1.GroupVersion.Version
+
+// ServedVersions describes the versions of the Gateway API served by a cluster.
+type ServedVersions struct {
+	// Versions contains the Gateway API versions served by the cluster, ordered
+	// from newest to oldest.
+	Versions []string
+	// ResourceVersions maps a Gateway API resource (like "httproutes") to the
+	// newest version in which it is served.
+	ResourceVersions map[string]string
+}
+
+// DiscoverServedVersions uses the discovery API to find the versions of the
+// Gateway API served by the cluster. Versions unknown to gwctl are ignored.
+func DiscoverServedVersions(discoveryClient discovery.DiscoveryInterface) (*ServedVersions, error) {
+	result := &ServedVersions{ResourceVersions: make(map[string]string)}
+
+	// Iterate from oldest to newest so that newer versions replace older ones
+	// within ResourceVersions.
+	for i := len(KnownVersions) - 1; i >= 0; i-- {
+		version := KnownVersions[i]
+		gv := schema.GroupVersion{Group: gatewayv1beta1.GroupName, Version: version}
+		resourceList, err := discoveryClient.ServerResourcesForGroupVersion(gv.String())
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to discover resources for %v: %v", gv, err)
+		}
+
+		result.Versions = append([]string{version}, result.Versions...)
+		for _, resource := range resourceList.APIResources {
+			result.ResourceVersions[resource.Name] = version
+		}
+	}
+	return result, nil
+}
+
+// PreferredVersion returns the newest version in which the resource is served.
+// An empty string is returned if the resource is not served in any of the
+// KnownVersions.
+func (s *ServedVersions) PreferredVersion(resource string) string {
+	if s == nil {
+		return ""
+	}
+	return s.ResourceVersions[resource]
+}
+
+// Resources returns the names of all Gateway API resources served by the
+// cluster, in sorted order.
+func (s *ServedVersions) Resources() []string {
+	if s == nil {
+		return nil
+	}
+	var result []string
+	for resource := range s.ResourceVersions {
+		result = append(result, resource)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,258 @@
+// Package multicluster runs gwctl across the clusters of multiple kubeconfig
+// contexts, such that teams which run the same Gateway topology in many
+// clusters can view it at once and spot where the clusters drifted apart.
+package multicluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+	"github.com/gauravkghildiyal/gwctl/pkg/fetch"
+	"github.com/gauravkghildiyal/gwctl/pkg/policymanager"
+	"github.com/gauravkghildiyal/gwctl/pkg/resources/httproutes"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+	"github.com/gauravkghildiyal/gwctl/pkg/whatif"
+)
+
+// Cluster is the cluster of a kubeconfig context.
+type Cluster struct {
+	// Name is the name of the kubeconfig context.
+	Name   string
+	Params *types.Params
+}
+
+// Contexts returns the contexts to run across, which are all contexts of the
+// kubeconfig if all is set, or else the given contexts (which must exist).
+func Contexts(params *types.Params, contexts []string, all bool) ([]string, error) {
+	if params.Clusters == nil {
+		return nil, fmt.Errorf("running across clusters is not supported")
+	}
+	known, err := params.Clusters.Contexts()
+	if err != nil {
+		return nil, err
+	}
+	if all {
+		return known, nil
+	}
+	var result []string
+	seen := make(map[string]bool)
+	for _, name := range contexts {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if i := sort.SearchStrings(known, name); i == len(known) || known[i] != name {
+			return nil, fmt.Errorf("unknown context %q, must be one of %v", name, known)
+		}
+		result = append(result, name)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no contexts given")
+	}
+	return result, nil
+}
+
+// Load loads the clusters of the contexts concurrently. The clusters are
+// returned in the order of the contexts, skipping those which failed to load,
+// whose errors are joined.
+func Load(ctx context.Context, params *types.Params, contexts []string) ([]Cluster, error) {
+	clusters := make([]*Cluster, len(contexts))
+	errs := make([]error, len(contexts))
+	// The errors are collected instead of returned to fetch.ForEach, such that
+	// a failing cluster does not cancel loading the others.
+	err := fetch.ForEach(ctx, len(contexts), func(ctx context.Context, i int) error {
+		clusterParams, err := params.Clusters.Load(ctx, contexts[i])
+		if err != nil {
+			errs[i] = fmt.Errorf("cluster %v: %v", contexts[i], err)
+			return nil
+		}
+		clusters[i] = &Cluster{Name: contexts[i], Params: clusterParams}
+		return nil
+	})
+
+	var result []Cluster
+	for _, cluster := range clusters {
+		if cluster != nil {
+			result = append(result, *cluster)
+		}
+	}
+	// err is only set once ctx is cancelled, in which case the remaining
+	// contexts are skipped.
+	return result, errors.Join(append(errs, err)...)
+}
+
+// ForEach calls fn for every cluster concurrently, and joins the errors
+// returned by fn (without cancelling the calls for other clusters). Cancelling
+// ctx skips the remaining clusters.
+func ForEach(ctx context.Context, clusters []Cluster, fn func(context.Context, Cluster) error) error {
+	errs := make([]error, len(clusters))
+	err := fetch.ForEach(ctx, len(clusters), func(ctx context.Context, i int) error {
+		if err := fn(ctx, clusters[i]); err != nil {
+			errs[i] = fmt.Errorf("cluster %v: %v", clusters[i].Name, err)
+		}
+		return nil
+	})
+	return errors.Join(append(errs, err)...)
+}
+
+// Drift is an effective policy of an HTTPRoute which differs between the
+// clusters having the HTTPRoute.
+type Drift struct {
+	// Resource is the HTTPRoute, like "HTTPRoute/default/foo".
+	Resource string
+	// Gateway is the Gateway through which the effective policy applies, or
+	// empty for direct policies.
+	Gateway     string
+	PolicyCrdID policymanager.PolicyCrdID
+	// Specs are the effective specs by the name of the cluster. Clusters
+	// which have the HTTPRoute but to which the policy does not apply are
+	// missing.
+	Specs map[string]map[string]interface{}
+	// Missing are the clusters which have the HTTPRoute but to which the
+	// policy does not apply, sorted.
+	Missing []string
+}
+
+type policyKey struct {
+	resource    string
+	gateway     string
+	policyCrdID policymanager.PolicyCrdID
+}
+
+// CompareHTTPRoutes compares the effective policies of the HTTPRoutes with the
+// same namespace and name across the clusters, and returns those which differ
+// sorted by HTTPRoute, Gateway and policy kind. HTTPRoutes are only compared
+// across the clusters which have them, and name "" compares all HTTPRoutes
+// within namespace (where "" is all namespaces).
+func CompareHTTPRoutes(ctx context.Context, clusters []Cluster, namespace, name string) ([]Drift, error) {
+	// routeClusters maps every HTTPRoute to the clusters which have it, while
+	// specs maps every effective policy to its spec by cluster.
+	var mu sync.Mutex
+	routeClusters := make(map[string][]string)
+	specs := make(map[policyKey]map[string]map[string]interface{})
+
+	err := ForEach(ctx, clusters, func(ctx context.Context, cluster Cluster) error {
+		httpRoutes, err := httproutes.List(ctx, cluster.Params, namespace)
+		if err != nil {
+			return err
+		}
+		effectivePolicies, err := whatif.EffectivePolicies(ctx, cluster.Params)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, httpRoute := range httpRoutes {
+			if name != "" && httpRoute.Name != name {
+				continue
+			}
+			resource := fmt.Sprintf("HTTPRoute/%v/%v", httpRoute.Namespace, httpRoute.Name)
+			routeClusters[resource] = append(routeClusters[resource], cluster.Name)
+		}
+		for _, effectivePolicy := range effectivePolicies {
+			if !strings.HasPrefix(effectivePolicy.Resource, "HTTPRoute/") {
+				continue
+			}
+			key := policyKey{resource: effectivePolicy.Resource, gateway: effectivePolicy.Gateway, policyCrdID: effectivePolicy.PolicyCrdID}
+			if specs[key] == nil {
+				specs[key] = make(map[string]map[string]interface{})
+			}
+			specs[key][cluster.Name] = effectivePolicy.Spec
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Drift
+	for key, specsByCluster := range specs {
+		clusterNames := routeClusters[key.resource]
+		if len(clusterNames) < 2 {
+			continue
+		}
+		drift := Drift{
+			Resource:    key.resource,
+			Gateway:     key.gateway,
+			PolicyCrdID: key.policyCrdID,
+			Specs:       make(map[string]map[string]interface{}),
+		}
+		var drifted bool
+		for _, clusterName := range clusterNames {
+			spec, ok := specsByCluster[clusterName]
+			if !ok {
+				drift.Missing = append(drift.Missing, clusterName)
+				drifted = true
+				continue
+			}
+			drift.Specs[clusterName] = spec
+			if !reflect.DeepEqual(spec, specsByCluster[clusterNames[0]]) {
+				drifted = true
+			}
+		}
+		if drifted {
+			sort.Strings(drift.Missing)
+			result = append(result, drift)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		if a.Gateway != b.Gateway {
+			return a.Gateway < b.Gateway
+		}
+		return a.PolicyCrdID < b.PolicyCrdID
+	})
+	return result, nil
+}
+
+type resourceView struct {
+	Resource string      `json:",omitempty"`
+	Drifts   []driftView `json:",omitempty"`
+}
+
+type driftView struct {
+	Gateway  string                            `json:",omitempty"`
+	Policy   policymanager.PolicyCrdID         `json:",omitempty"`
+	Clusters map[string]map[string]interface{} `json:",omitempty"`
+	Missing  []string                          `json:",omitempty"`
+}
+
+// PrintDrifts prints the drifts grouped by HTTPRoute, with the effective spec
+// of the policy in every cluster.
+func PrintDrifts(params *types.Params, drifts []Drift) {
+	// Like whatif.Print, JSON is printed as an empty list instead.
+	if len(drifts) == 0 && params.Output != "json" {
+		fmt.Fprintln(params.Out, "No effective policies drifted")
+		return
+	}
+
+	views := []resourceView{}
+	for _, drift := range drifts {
+		if len(views) == 0 || views[len(views)-1].Resource != drift.Resource {
+			views = append(views, resourceView{Resource: drift.Resource})
+		}
+		view := &views[len(views)-1]
+		view.Drifts = append(view.Drifts, driftView{
+			Gateway:  drift.Gateway,
+			Policy:   drift.PolicyCrdID,
+			Clusters: drift.Specs,
+			Missing:  drift.Missing,
+		})
+	}
+
+	b, err := common.Marshal(params.Output, views)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Fprint(params.Out, string(b))
+}
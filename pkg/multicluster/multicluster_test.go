@@ -0,0 +1,163 @@
+package multicluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+)
+
+// fakeLoader loads the Params of every context from fake clients.
+type fakeLoader struct {
+	t        *testing.T
+	contexts []string
+	objects  map[string][]runtime.Object
+}
+
+func (l *fakeLoader) Contexts() ([]string, error) {
+	return l.contexts, nil
+}
+
+func (l *fakeLoader) Load(ctx context.Context, name string) (*types.Params, error) {
+	objects, ok := l.objects[name]
+	if !ok {
+		return nil, fmt.Errorf("cluster is unreachable")
+	}
+	return types.MustParamsForTest(l.t, common.MustClientsForTest(l.t, objects...)), nil
+}
+
+func TestCompareHTTPRoutes(t *testing.T) {
+	// clusterObjects returns the objects of a cluster with the HTTPRoutes, and
+	// a TimeoutPolicy for foo-httproute unless seconds is zero.
+	clusterObjects := func(seconds int64, httpRouteNames ...string) []runtime.Object {
+		objects := []runtime.Object{
+			&apiextensionsv1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "timeoutpolicies.foo.com",
+					Labels: map[string]string{
+						common.GatewayPolicyLabelKey: "direct",
+					},
+				},
+				Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+					Scope:    apiextensionsv1.NamespaceScoped,
+					Group:    "foo.com",
+					Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1"}},
+					Names: apiextensionsv1.CustomResourceDefinitionNames{
+						Plural: "timeoutpolicies",
+						Kind:   "TimeoutPolicy",
+					},
+				},
+			},
+		}
+		for _, name := range httpRouteNames {
+			objects = append(objects, &gatewayv1beta1.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "default",
+				},
+			})
+		}
+		// Every cluster has a policy of the CRD for the fake dynamic client to
+		// list, which targets foo-httproute unless seconds is zero.
+		targetName := "foo-httproute"
+		if seconds == 0 {
+			targetName = "unknown-httproute"
+		}
+		objects = append(objects, &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "foo.com/v1",
+				"kind":       "TimeoutPolicy",
+				"metadata": map[string]interface{}{
+					"name":      "timeout-policy",
+					"namespace": "default",
+				},
+				"spec": map[string]interface{}{
+					"seconds": seconds,
+					"targetRef": map[string]interface{}{
+						"group": "gateway.networking.k8s.io",
+						"kind":  "HTTPRoute",
+						"name":  targetName,
+					},
+				},
+			},
+		})
+		return objects
+	}
+
+	params := types.MustParamsForTest(t, common.MustClientsForTest(t))
+	params.Clusters = &fakeLoader{
+		t:        t,
+		contexts: []string{"a", "b", "c", "d", "e", "unreachable"},
+		objects: map[string][]runtime.Object{
+			"a": clusterObjects(30, "foo-httproute", "bar-httproute"),
+			"b": clusterObjects(60, "foo-httproute"),
+			"c": clusterObjects(0, "foo-httproute"),
+			// d does not have foo-httproute, so it is not compared.
+			"d": clusterObjects(0, "bar-httproute"),
+			"e": clusterObjects(30, "foo-httproute"),
+		},
+	}
+
+	if _, err := Contexts(params, []string{"a", "f"}, false); err == nil {
+		t.Errorf("Contexts() with unknown context succeeded; want error")
+	}
+	contexts, err := Contexts(params, nil, true)
+	if err != nil {
+		t.Fatalf("Contexts() failed: %v", err)
+	}
+
+	clusters, err := Load(context.Background(), params, contexts)
+	if err == nil {
+		t.Errorf("Load() succeeded; want error for the unreachable cluster")
+	}
+	var names []string
+	for _, cluster := range clusters {
+		names = append(names, cluster.Name)
+	}
+	if diff := cmp.Diff([]string{"a", "b", "c", "d", "e"}, names); diff != "" {
+		t.Errorf("Load() returned unexpected diff of clusters (-want +got)=\n%v", diff)
+	}
+
+	drifts, err := CompareHTTPRoutes(context.Background(), clusters, "", "")
+	if err != nil {
+		t.Fatalf("CompareHTTPRoutes() failed: %v", err)
+	}
+	PrintDrifts(params, drifts)
+	got := params.Out.(*bytes.Buffer).String()
+	want := `
+- Drifts:
+  - Clusters:
+      a:
+        seconds: 30
+      b:
+        seconds: 60
+      e:
+        seconds: 30
+    Missing:
+    - c
+    Policy: TimeoutPolicy.foo.com
+  Resource: HTTPRoute/default/foo-httproute
+`
+	if diff := cmp.Diff(common.YamlString(want), common.YamlString(got), common.YamlStringTransformer); diff != "" {
+		t.Errorf("PrintDrifts: Unexpected diff\ngot=\n%v\nwant=\n%v\ndiff (-want +got)=\n%v", got, want, diff)
+	}
+
+	// Clusters with the same effective policies do not drift.
+	drifts, err = CompareHTTPRoutes(context.Background(), []Cluster{clusters[0], clusters[4]}, "", "")
+	if err != nil {
+		t.Fatalf("CompareHTTPRoutes() failed: %v", err)
+	}
+	if len(drifts) != 0 {
+		t.Errorf("CompareHTTPRoutes() = %v; want no drifts", drifts)
+	}
+}
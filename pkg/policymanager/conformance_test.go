@@ -0,0 +1,299 @@
+package policymanager
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// The tests in this file verify the precedence of inherited policies as per
+// [GEP-713]:
+//
+//   - spec.override of a policy wins over any policy below its target in the
+//     hierarchy (top-down), and spec.default of a policy wins over any policy
+//     above its target (bottom-up).
+//   - An override always wins over a default.
+//   - Amongst policies attached at the same level, the oldest policy wins,
+//     followed by the alphabetical order of "<namespace>/<name>".
+//
+// [GEP-713]: https://gateway-api.sigs.k8s.io/geps/gep-713/
+
+// conformanceLevels is the hierarchy from the top down.
+var conformanceLevels = []string{"GatewayClass", "Namespace", "Gateway", "HTTPRoute"}
+
+func conformancePolicy(name, level string, created time.Time, strategy MergeStrategyType, spec map[string]interface{}) Policy {
+	return Policy{
+		u: unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "foo.com/v1",
+			"kind":       "TimeoutPolicy",
+			"metadata": map[string]interface{}{
+				"name":              name,
+				"namespace":         "default",
+				"creationTimestamp": metav1.NewTime(created).UTC().Format(time.RFC3339),
+			},
+			"spec": spec,
+		}},
+		targetRef:     ObjRef{Kind: level, Name: name},
+		inherited:     true,
+		mergeStrategy: MergeStrategy{Type: strategy},
+	}
+}
+
+// mergeLevels merges the policies of each level, and then the levels from the
+// top down, like the effective policies of the resources are calculated.
+func mergeLevels(t *testing.T, levels [][]Policy) Policy {
+	var result map[PolicyCrdID]Policy
+	for _, policies := range levels {
+		policiesByKind, err := MergePoliciesOfSimilarKind(policies)
+		if err != nil {
+			t.Fatalf("MergePoliciesOfSimilarKind() returned err=%v; want no error", err)
+		}
+		result, err = MergePoliciesOfDifferentHierarchy(result, policiesByKind)
+		if err != nil {
+			t.Fatalf("MergePoliciesOfDifferentHierarchy() returned err=%v; want no error", err)
+		}
+	}
+	return result["TimeoutPolicy.foo.com"]
+}
+
+// TestConformance_AllLevelCombinations sets a single field through
+// spec.default, spec.override, both or neither at each level of the
+// hierarchy, for every combination and merge strategy.
+func TestConformance_AllLevelCombinations(t *testing.T) {
+	const (
+		none = iota
+		withDefault
+		withOverride
+		withBoth
+		choices
+	)
+	combinations := 1
+	for range conformanceLevels {
+		combinations *= choices
+	}
+
+	for _, strategy := range mergeStrategyTypes {
+		for combination := 0; combination < combinations; combination++ {
+			var levels [][]Policy
+			var description []string
+			wantValue, wantOrigin := "", ValueOrigin{}
+			overridden := false
+			for i, level := range conformanceLevels {
+				choice := combination
+				for j := 0; j < i; j++ {
+					choice /= choices
+				}
+				choice %= choices
+
+				name := "policy-" + level
+				spec := make(map[string]interface{})
+				if choice == withDefault || choice == withBoth {
+					spec["default"] = map[string]interface{}{"timeout": level + "-default"}
+					description = append(description, level+"-default")
+					// The lowest default wins, unless there is any override.
+					if !overridden {
+						wantValue = level + "-default"
+						wantOrigin = ValueOrigin{Field: "default", PolicyNamespace: "default", PolicyName: name, TargetRef: ObjRef{Kind: level, Name: name}}
+					}
+				}
+				if choice == withOverride || choice == withBoth {
+					spec["override"] = map[string]interface{}{"timeout": level + "-override"}
+					description = append(description, level+"-override")
+					// The highest override wins.
+					if !overridden {
+						overridden = true
+						wantValue = level + "-override"
+						wantOrigin = ValueOrigin{Field: "override", PolicyNamespace: "default", PolicyName: name, TargetRef: ObjRef{Kind: level, Name: name}}
+					}
+				}
+				if choice != none {
+					levels = append(levels, []Policy{conformancePolicy(name, level, time.Unix(0, 0), strategy, spec)})
+				} else {
+					levels = append(levels, nil)
+				}
+			}
+
+			t.Run(fmt.Sprintf("%v/%v", strategy, description), func(t *testing.T) {
+				merged := mergeLevels(t, levels)
+				if len(description) == 0 {
+					return
+				}
+				effectiveSpec, err := merged.EffectiveSpec()
+				if err != nil {
+					t.Fatalf("EffectiveSpec() returned err=%v; want no error", err)
+				}
+				if got := effectiveSpec["timeout"]; got != wantValue {
+					t.Errorf("EffectiveSpec()[timeout] = %v; want %v", got, wantValue)
+				}
+				gotOrigin, _ := merged.Origin("timeout")
+				if diff := cmp.Diff(wantOrigin, gotOrigin); diff != "" {
+					t.Errorf("Origin(timeout) returned unexpected diff (-want +got)=\n%v", diff)
+				}
+			})
+		}
+	}
+}
+
+// TestConformance_SameLevel attaches two policies to the same level, which
+// are ordered by their creation time and then by their names.
+func TestConformance_SameLevel(t *testing.T) {
+	older, newer := time.Unix(0, 0), time.Unix(3600, 0)
+	testCases := []struct {
+		name                   string
+		created1, created2     time.Time
+		spec1, spec2           map[string]interface{}
+		wantValue, wantWinners string
+	}{
+		{
+			name:     "older override wins over newer override",
+			created1: newer, created2: older,
+			spec1:     map[string]interface{}{"override": map[string]interface{}{"timeout": "1"}},
+			spec2:     map[string]interface{}{"override": map[string]interface{}{"timeout": "2"}},
+			wantValue: "2", wantWinners: "policy-2",
+		},
+		{
+			name:     "older default wins over newer default",
+			created1: older, created2: newer,
+			spec1:     map[string]interface{}{"default": map[string]interface{}{"timeout": "1"}},
+			spec2:     map[string]interface{}{"default": map[string]interface{}{"timeout": "2"}},
+			wantValue: "1", wantWinners: "policy-1",
+		},
+		{
+			name:     "newer override wins over older default",
+			created1: older, created2: newer,
+			spec1:     map[string]interface{}{"default": map[string]interface{}{"timeout": "1"}},
+			spec2:     map[string]interface{}{"override": map[string]interface{}{"timeout": "2"}},
+			wantValue: "2", wantWinners: "policy-2",
+		},
+		{
+			name:     "alphabetical order decides for the same creation time",
+			created1: older, created2: older,
+			spec1:     map[string]interface{}{"override": map[string]interface{}{"timeout": "1"}},
+			spec2:     map[string]interface{}{"override": map[string]interface{}{"timeout": "2"}},
+			wantValue: "1", wantWinners: "policy-1",
+		},
+	}
+
+	for _, tc := range testCases {
+		for _, strategy := range mergeStrategyTypes {
+			t.Run(fmt.Sprintf("%v/%v", tc.name, strategy), func(t *testing.T) {
+				// The order in which the policies are listed must not matter.
+				for _, policies := range [][]Policy{
+					{conformancePolicy("policy-1", "Gateway", tc.created1, strategy, tc.spec1), conformancePolicy("policy-2", "Gateway", tc.created2, strategy, tc.spec2)},
+					{conformancePolicy("policy-2", "Gateway", tc.created2, strategy, tc.spec2), conformancePolicy("policy-1", "Gateway", tc.created1, strategy, tc.spec1)},
+				} {
+					merged := mergeLevels(t, [][]Policy{policies})
+					effectiveSpec, err := merged.EffectiveSpec()
+					if err != nil {
+						t.Fatalf("EffectiveSpec() returned err=%v; want no error", err)
+					}
+					if got := effectiveSpec["timeout"]; got != tc.wantValue {
+						t.Errorf("EffectiveSpec()[timeout] = %v; want %v", got, tc.wantValue)
+					}
+					if origin, _ := merged.Origin("timeout"); origin.PolicyName != tc.wantWinners {
+						t.Errorf("Origin(timeout).PolicyName = %v; want %v", origin.PolicyName, tc.wantWinners)
+					}
+				}
+			})
+		}
+	}
+}
+
+// TestConformance_Examples covers examples with several fields, which are set
+// at different levels.
+func TestConformance_Examples(t *testing.T) {
+	created := time.Unix(0, 0)
+	testCases := []struct {
+		name   string
+		levels [][]Policy
+		want   map[string]interface{}
+	}{
+		{
+			name: "each field is decided independently",
+			levels: [][]Policy{
+				{conformancePolicy("gatewayclass", "GatewayClass", created, MergeStrategyJSONMerge, map[string]interface{}{
+					"override": map[string]interface{}{"a": "gatewayclass"},
+					"default":  map[string]interface{}{"e": "gatewayclass"},
+				})},
+				{conformancePolicy("namespace", "Namespace", created, MergeStrategyJSONMerge, map[string]interface{}{
+					"default": map[string]interface{}{"a": "namespace", "b": "namespace", "e": "namespace"},
+				})},
+				{conformancePolicy("gateway", "Gateway", created, MergeStrategyJSONMerge, map[string]interface{}{
+					"override": map[string]interface{}{"a": "gateway", "b": "gateway", "c": "gateway"},
+				})},
+				{conformancePolicy("httproute", "HTTPRoute", created, MergeStrategyJSONMerge, map[string]interface{}{
+					"override": map[string]interface{}{"a": "httproute"},
+					"default":  map[string]interface{}{"c": "httproute", "d": "httproute"},
+				})},
+			},
+			want: map[string]interface{}{
+				"a": "gatewayclass",
+				"b": "gateway",
+				"c": "gateway",
+				"d": "httproute",
+				"e": "namespace",
+			},
+		},
+		{
+			name: "nested overrides of the highest ancestor win across levels",
+			levels: [][]Policy{
+				{conformancePolicy("gatewayclass", "GatewayClass", created, MergeStrategyJSONMerge, map[string]interface{}{
+					"override": map[string]interface{}{"retry": map[string]interface{}{"codes": "5xx"}},
+				})},
+				{conformancePolicy("gateway", "Gateway", created, MergeStrategyJSONMerge, map[string]interface{}{
+					"override": map[string]interface{}{"retry": map[string]interface{}{"codes": "reset", "attempts": int64(2)}},
+				})},
+				{conformancePolicy("httproute", "HTTPRoute", created, MergeStrategyJSONMerge, map[string]interface{}{
+					"override": map[string]interface{}{"retry": map[string]interface{}{"codes": "all", "attempts": int64(9)}},
+					"default":  map[string]interface{}{"retry": map[string]interface{}{"attempts": int64(5), "backoff": "1s"}},
+				})},
+			},
+			want: map[string]interface{}{
+				"retry": map[string]interface{}{"codes": "5xx", "attempts": float64(2), "backoff": "1s"},
+			},
+		},
+		{
+			name: "an override cannot be removed by a lower level",
+			levels: [][]Policy{
+				{conformancePolicy("gatewayclass", "GatewayClass", created, MergeStrategyJSONMerge, map[string]interface{}{
+					"override": map[string]interface{}{"a": "gatewayclass"},
+				})},
+				{conformancePolicy("gateway", "Gateway", created, MergeStrategyJSONMerge, map[string]interface{}{
+					"override": map[string]interface{}{"a": nil},
+					"default":  map[string]interface{}{"a": nil},
+				})},
+			},
+			want: map[string]interface{}{"a": "gatewayclass"},
+		},
+		{
+			name: "a default of the highest ancestor applies if no lower level sets the field",
+			levels: [][]Policy{
+				{conformancePolicy("gatewayclass", "GatewayClass", created, MergeStrategyJSONMerge, map[string]interface{}{
+					"default": map[string]interface{}{"a": "gatewayclass"},
+				})},
+				nil,
+				{conformancePolicy("gateway", "Gateway", created, MergeStrategyJSONMerge, map[string]interface{}{
+					"default": map[string]interface{}{"b": "gateway"},
+				})},
+				nil,
+			},
+			want: map[string]interface{}{"a": "gatewayclass", "b": "gateway"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := mergeLevels(t, tc.levels).EffectiveSpec()
+			if err != nil {
+				t.Fatalf("EffectiveSpec() returned err=%v; want no error", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("EffectiveSpec() returned unexpected diff (-want +got)=\n%v", diff)
+			}
+		})
+	}
+}
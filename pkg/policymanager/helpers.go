@@ -1,5 +1,9 @@
 package policymanager
 
+import (
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
 // ToPolicyRefs returns the Object references of all given policies. Note that
 // these are not the value of targetRef within the Policies but rather the
 // reference to the Policy object itself.
@@ -15,3 +19,48 @@ func ToPolicyRefs(policies []Policy) []ObjRef {
 	}
 	return result
 }
+
+// InheritedPolicies returns the inherited policies amongst the given policies.
+// These are the policies which apply to the resources below their target in
+// the hierarchy.
+func InheritedPolicies(policies []Policy) []Policy {
+	var result []Policy
+	for _, policy := range policies {
+		if policy.IsInherited() {
+			result = append(result, policy)
+		}
+	}
+	return result
+}
+
+// DirectPolicies returns the policies amongst the given policies which are not
+// inherited. These only apply to their exact target.
+func DirectPolicies(policies []Policy) []Policy {
+	var result []Policy
+	for _, policy := range policies {
+		if policy.IsDirect() {
+			result = append(result, policy)
+		}
+	}
+	return result
+}
+
+// BackendRefToObjRef returns the reference to the backend within the
+// backendRef, after applying the defaults from the Gateway API for unspecified
+// fields.
+func BackendRefToObjRef(backendRef gatewayv1beta1.BackendObjectReference, routeNamespace string) ObjRef {
+	objRef := ObjRef{
+		Kind: "Service",
+		Name: string(backendRef.Name),
+	}
+	if backendRef.Group != nil {
+		objRef.Group = string(*backendRef.Group)
+	}
+	if backendRef.Kind != nil {
+		objRef.Kind = string(*backendRef.Kind)
+	}
+	if backendRef.Namespace != nil {
+		objRef.Namespace = string(*backendRef.Namespace)
+	}
+	return objRef.Normalize(routeNamespace)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -11,7 +12,11 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/fetch"
 )
 
 const (
@@ -20,21 +25,40 @@ const (
 
 type PolicyManager struct {
 	dc dynamic.Interface
+	// client reads the Gateway API objects, like the ReferenceGrants.
+	client client.Client
 
 	// policyCRDs maps a CRD name to the CRD object.
 	policyCRDs map[PolicyCrdID]PolicyCRD
-	// policies maps a policy name to the policy object.
+	// policies maps the key of a policy, see policyKey, to the policy object.
 	policies map[string]Policy
+	// referenceGrants contains all ReferenceGrants, which decide whether
+	// cross-namespace references are permitted.
+	referenceGrants []gatewayv1beta1.ReferenceGrant
+	// extraPolicyCRDs maps the name of a CRD, which is treated as a Policy CRD
+	// even without the label, to its type of policy.
+	extraPolicyCRDs map[string]string
 }
 
-func New(dc dynamic.Interface) *PolicyManager {
+func New(dc dynamic.Interface, c client.Client) *PolicyManager {
 	return &PolicyManager{
 		dc:         dc,
+		client:     c,
 		policyCRDs: make(map[PolicyCrdID]PolicyCRD),
 		policies:   make(map[string]Policy),
+
+		extraPolicyCRDs: make(map[string]string),
 	}
 }
 
+// TreatAsPolicyCRD treats the CRD with the given name as a Policy CRD, as if it
+// had the gateway.networking.k8s.io/policy label with the value of policyType
+// ("inherited" or "direct"). The label of the CRD takes precedence if set. It
+// must be called before Init.
+func (p *PolicyManager) TreatAsPolicyCRD(name, policyType string) {
+	p.extraPolicyCRDs[name] = policyType
+}
+
 // Init will construct a local cache of all Policy CRDs and Policy Resources.
 func (p *PolicyManager) Init(ctx context.Context) error {
 	allCRDs, err := fetchCRDs(ctx, p.dc)
@@ -42,7 +66,14 @@ func (p *PolicyManager) Init(ctx context.Context) error {
 		return err
 	}
 	for _, crd := range allCRDs {
-		policyCRD := PolicyCRD{crd}
+		if policyType, ok := p.extraPolicyCRDs[crd.Name]; ok && crd.GetLabels()[gatewayPolicyLabelKey] == "" {
+			labels := map[string]string{gatewayPolicyLabelKey: policyType}
+			for key, value := range crd.GetLabels() {
+				labels[key] = value
+			}
+			crd.SetLabels(labels)
+		}
+		policyCRD := PolicyCRD{crd: crd}
 		// Check if the CRD is a Gateway Policy CRD
 		if policyCRD.IsValid() {
 			p.policyCRDs[policyCRD.ID()] = policyCRD
@@ -58,22 +89,122 @@ func (p *PolicyManager) Init(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
-		p.policies[unstrucutredPolicy.GetNamespace()+"/"+unstrucutredPolicy.GetName()] = policy
+		p.policies[policyKey(unstrucutredPolicy)] = policy
+	}
+
+	p.referenceGrants, err = fetchReferenceGrants(ctx, p.client)
+	if err != nil {
+		return err
 	}
 
 	return nil
 }
 
+// PoliciesAttachedTo returns the policies which target the object referenced
+// by objRef. The policies are sorted by their namespace, name and kind. Policies
+// which target an object in a different namespace without being permitted by a
+// ReferenceGrant are excluded.
 func (p *PolicyManager) PoliciesAttachedTo(objRef ObjRef) []Policy {
 	var result []Policy
 	for _, policy := range p.policies {
-		if policy.IsAttachedTo(objRef) {
-			result = append(result, policy)
+		if !policy.IsAttachedTo(objRef) {
+			continue
+		}
+		if permitted, _ := p.IsTargetRefPermitted(policy); !permitted {
+			continue
 		}
+		result = append(result, policy)
 	}
+	sort.Slice(result, func(i, j int) bool {
+		a := fmt.Sprintf("%v/%v/%v", result[i].u.GetNamespace(), result[i].u.GetName(), result[i].PolicyCrdID())
+		b := fmt.Sprintf("%v/%v/%v", result[j].u.GetNamespace(), result[j].u.GetName(), result[j].PolicyCrdID())
+		return a < b
+	})
 	return result
 }
 
+// Clone returns a copy of the PolicyManager, whose policies can be added or
+// removed without affecting the original. This is useful to simulate the
+// effect of policy changes before applying them to the cluster.
+func (p *PolicyManager) Clone() *PolicyManager {
+	clone := New(p.dc, p.client)
+	for id, policyCRD := range p.policyCRDs {
+		clone.policyCRDs[id] = policyCRD
+	}
+	for key, policy := range p.policies {
+		clone.policies[key] = policy.DeepCopy()
+	}
+	clone.referenceGrants = append(clone.referenceGrants, p.referenceGrants...)
+	for name, policyType := range p.extraPolicyCRDs {
+		clone.extraPolicyCRDs[name] = policyType
+	}
+	return clone
+}
+
+// policyKey identifies the policy by its kind, namespace and name, since
+// policies of different kinds may share the namespace and name.
+func policyKey(u unstructured.Unstructured) string {
+	gvk := u.GroupVersionKind()
+	return fmt.Sprintf("%v.%v/%v/%v", gvk.Kind, gvk.Group, u.GetNamespace(), u.GetName())
+}
+
+// AddPolicy adds the policy to the PolicyManager, replacing any existing
+// policy with the same kind, namespace and name. Since the creation time
+// decides the precedence amongst conflicting policies, a replacing policy
+// keeps the creation time of the policy it replaces, while a new policy is
+// created now.
+func (p *PolicyManager) AddPolicy(u unstructured.Unstructured) error {
+	u = *u.DeepCopy()
+	key := policyKey(u)
+	if existing, ok := p.policies[key]; ok {
+		u.SetCreationTimestamp(existing.u.GetCreationTimestamp())
+	} else {
+		u.SetCreationTimestamp(metav1.Now())
+	}
+
+	policy, err := PolicyFromUnstrucutred(u, p.policyCRDs)
+	if err != nil {
+		return err
+	}
+	p.policies[key] = policy
+	return nil
+}
+
+// RemovePolicy removes the policy with the same kind, namespace and name as u
+// from the PolicyManager. It returns false if no such policy exists.
+func (p *PolicyManager) RemovePolicy(u unstructured.Unstructured) bool {
+	key := policyKey(u)
+	if _, ok := p.policies[key]; !ok {
+		return false
+	}
+	delete(p.policies, key)
+	return true
+}
+
+// SetMergeStrategy sets the strategy by which policies of the Policy CRD with
+// the given ID are merged, taking precedence over the annotations of the CRD.
+func (p *PolicyManager) SetMergeStrategy(id PolicyCrdID, mergeStrategy MergeStrategy) error {
+	policyCRD, ok := p.policyCRDs[id]
+	if !ok {
+		return fmt.Errorf("unknown Policy CRD %v", id)
+	}
+	policyCRD.mergeStrategy = &mergeStrategy
+	p.policyCRDs[id] = policyCRD
+	for key, policy := range p.policies {
+		if policy.PolicyCrdID() == id {
+			policy.mergeStrategy = mergeStrategy
+			p.policies[key] = policy
+		}
+	}
+	return nil
+}
+
+// GetCRD returns the Policy CRD with the given ID.
+func (p *PolicyManager) GetCRD(id PolicyCrdID) (PolicyCRD, bool) {
+	policyCRD, ok := p.policyCRDs[id]
+	return policyCRD, ok
+}
+
 func (p *PolicyManager) GetCRDs() []PolicyCRD {
 	var result []PolicyCRD
 	for _, policyCRD := range p.policyCRDs {
@@ -93,6 +224,7 @@ func (p *PolicyManager) GetPolicies() []Policy {
 // fetchCRDs will fetch all CRDs from the API Server
 func fetchCRDs(ctx context.Context, dc dynamic.Interface) ([]apiextensionsv1.CustomResourceDefinition, error) {
 	gvr := schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+	defer fetch.Timed("list %v", gvr)()
 	unstructuredCRDs, err := dc.Resource(gvr).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return []apiextensionsv1.CustomResourceDefinition{}, fmt.Errorf("failed to list CRDs: %v", err)
@@ -107,16 +239,23 @@ func fetchCRDs(ctx context.Context, dc dynamic.Interface) ([]apiextensionsv1.Cus
 }
 
 // fetchPolicies will fetch all policy resources corresponding to the CRDs
-// present in policyCRDs.
+// present in policyCRDs. The policies of the CRDs are listed concurrently.
 func fetchPolicies(ctx context.Context, dc dynamic.Interface, policyCRDs map[PolicyCrdID]PolicyCRD) ([]unstructured.Unstructured, error) {
-	var result []unstructured.Unstructured
+	var ids []PolicyCrdID
+	for id := range policyCRDs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
 
-	for _, policyCRD := range policyCRDs {
+	policiesByCRD := make([][]unstructured.Unstructured, len(ids))
+	err := fetch.ForEach(ctx, len(ids), func(ctx context.Context, i int) error {
+		policyCRD := policyCRDs[ids[i]]
 		gvr := schema.GroupVersionResource{
 			Group:    policyCRD.crd.Spec.Group,
 			Version:  policyCRD.crd.Spec.Versions[0].Name,
 			Resource: policyCRD.crd.Spec.Names.Plural, // CRD Kinds directy map to the Resource.
 		}
+		defer fetch.Timed("list %v", gvr)()
 
 		var policies *unstructured.UnstructuredList
 		var err error
@@ -126,12 +265,19 @@ func fetchPolicies(ctx context.Context, dc dynamic.Interface, policyCRDs map[Pol
 			policies, err = dc.Resource(gvr).Namespace("").List(ctx, metav1.ListOptions{})
 		}
 		if err != nil {
-			return result, err
+			return err
 		}
-
-		result = append(result, policies.Items...)
+		policiesByCRD[i] = policies.Items
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	var result []unstructured.Unstructured
+	for _, policies := range policiesByCRD {
+		result = append(result, policies...)
+	}
 	return result, nil
 }
 
@@ -140,6 +286,9 @@ type PolicyCrdID string
 
 type PolicyCRD struct {
 	crd apiextensionsv1.CustomResourceDefinition
+	// mergeStrategy overrides the MergeStrategy configured through the
+	// annotations of the CRD.
+	mergeStrategy *MergeStrategy
 }
 
 // ID returns a unique identifier for this PolicyCRD.
@@ -161,6 +310,18 @@ func (p PolicyCRD) IsDirect() bool {
 	return p.crd.GetLabels()[gatewayPolicyLabelKey] == "direct"
 }
 
+// MergeStrategy returns the strategy by which policies of the CRD are merged.
+// It is configured through the MergeStrategyAnnotation and
+// MergeListKeysAnnotation of the CRD, unless it is set through
+// PolicyManager.SetMergeStrategy.
+func (p PolicyCRD) MergeStrategy() (MergeStrategy, error) {
+	if p.mergeStrategy != nil {
+		return *p.mergeStrategy, nil
+	}
+	annotations := p.crd.GetAnnotations()
+	return ParseMergeStrategy(annotations[MergeStrategyAnnotation], annotations[MergeListKeysAnnotation])
+}
+
 func (p PolicyCRD) CRD() *apiextensionsv1.CustomResourceDefinition {
 	return p.crd.DeepCopy()
 }
@@ -177,6 +338,23 @@ type Policy struct {
 	// Indicates whether the policy is supposed to be "inherited" (as opposed to
 	// "direct").
 	inherited bool
+	// mergeStrategy is the MergeStrategy of the CRD of the policy.
+	mergeStrategy MergeStrategy
+	// origins maps the top-level fields of the effective spec of a merged
+	// policy to the policies providing their values. It is nil for policies
+	// which are not the result of a merge.
+	origins map[string]ValueOrigin
+}
+
+// ValueOrigin identifies the policy which provides a value within the
+// effective spec.
+type ValueOrigin struct {
+	// Field is "default" or "override" for values of inherited policies, and
+	// empty for values of direct policies.
+	Field           string
+	PolicyNamespace string
+	PolicyName      string
+	TargetRef       ObjRef
 }
 
 type ObjRef struct {
@@ -186,6 +364,39 @@ type ObjRef struct {
 	Namespace string `json:",omitempty"`
 }
 
+// clusterScopedKinds contains the kinds which are known to be cluster scoped.
+// References to these kinds never carry a namespace.
+var clusterScopedKinds = map[schema.GroupKind]bool{
+	{Group: "", Kind: "Namespace"}:                           true,
+	{Group: gatewayv1alpha2.GroupName, Kind: "GatewayClass"}: true,
+}
+
+// IsClusterScoped returns true if the ObjRef references a cluster scoped
+// object.
+func (o ObjRef) IsClusterScoped() bool {
+	return clusterScopedKinds[schema.GroupKind{Group: o.Group, Kind: o.Kind}]
+}
+
+// Normalize returns the canonical form of the ObjRef, such that two references
+// to the same object compare equal.
+//
+//   - References to cluster scoped objects have their namespace cleared.
+//   - References to namespaced objects which do not specify a namespace get
+//     defaultNamespace. As per the [Gateway Specification], this should be the
+//     namespace of the referring object (for example, the Policy).
+//
+// [Gateway Specification]: https://gateway-api.sigs.k8s.io/geps/gep-713/#policy-targetref-api
+func (o ObjRef) Normalize(defaultNamespace string) ObjRef {
+	if o.IsClusterScoped() {
+		o.Namespace = ""
+		return o
+	}
+	if o.Namespace == "" {
+		o.Namespace = defaultNamespace
+	}
+	return o
+}
+
 func PolicyFromUnstrucutred(u unstructured.Unstructured, policyCRDs map[PolicyCrdID]PolicyCRD) (Policy, error) {
 	result := Policy{u: u}
 
@@ -202,17 +413,16 @@ func PolicyFromUnstrucutred(u unstructured.Unstructured, policyCRDs map[PolicyCr
 		return Policy{}, fmt.Errorf("failed to convert unstructured policy resource to structured: %v", err)
 	}
 	result.targetRef = ObjRef{
-		Group:     string(structuredPolicy.Spec.TargetRef.Group),
-		Kind:      string(structuredPolicy.Spec.TargetRef.Kind),
-		Name:      string(structuredPolicy.Spec.TargetRef.Name),
-		Namespace: structuredPolicy.GetNamespace(),
-	}
-	if result.targetRef.Namespace == "default" {
-		result.targetRef.Namespace = ""
+		Group: string(structuredPolicy.Spec.TargetRef.Group),
+		Kind:  string(structuredPolicy.Spec.TargetRef.Kind),
+		Name:  string(structuredPolicy.Spec.TargetRef.Name),
 	}
 	if structuredPolicy.Spec.TargetRef.Namespace != nil {
 		result.targetRef.Namespace = string(*structuredPolicy.Spec.TargetRef.Namespace)
 	}
+	// An unspecified namespace in the targetRef refers to the namespace of the
+	// Policy itself.
+	result.targetRef = result.targetRef.Normalize(structuredPolicy.GetNamespace())
 
 	// Get the CRD corresponding to this policy object.
 	policyCRD, ok := policyCRDs[result.PolicyCrdID()]
@@ -220,6 +430,13 @@ func PolicyFromUnstrucutred(u unstructured.Unstructured, policyCRDs map[PolicyCr
 		return Policy{}, fmt.Errorf("unable to find CRD corresponding to policy object")
 	}
 	result.inherited = policyCRD.IsInherited()
+	mergeStrategy, err := policyCRD.MergeStrategy()
+	if err != nil {
+		// An invalid MergeStrategy is reported when validating the CRD, and
+		// falls back to the default.
+		mergeStrategy = MergeStrategy{Type: MergeStrategyJSONMerge}
+	}
+	result.mergeStrategy = mergeStrategy
 
 	return result, nil
 }
@@ -241,20 +458,11 @@ func (p Policy) IsDirect() bool {
 	return !p.inherited
 }
 
+// IsAttachedTo returns true if the Policy targets the object referenced by
+// objRef. objRef is expected to carry the namespace of the object if the object
+// is namespaced.
 func (p Policy) IsAttachedTo(objRef ObjRef) bool {
-	if p.targetRef.Kind == "Namespace" && p.targetRef.Name == "" {
-		p.targetRef.Name = "default"
-	}
-	if objRef.Kind == "Namespace" && objRef.Name == "" {
-		objRef.Name = "default"
-	}
-	if p.targetRef.Kind != "Namespace" && p.targetRef.Namespace == "" {
-		p.targetRef.Namespace = "default"
-	}
-	if objRef.Kind != "Namespace" && objRef.Namespace == "" {
-		objRef.Namespace = "default"
-	}
-	return p.targetRef == objRef
+	return p.targetRef == objRef.Normalize("")
 }
 
 func (p Policy) Unstructured() *unstructured.Unstructured {
@@ -263,13 +471,49 @@ func (p Policy) Unstructured() *unstructured.Unstructured {
 
 func (p Policy) DeepCopy() Policy {
 	clone := Policy{
-		u:         *p.u.DeepCopy(),
-		targetRef: p.targetRef,
-		inherited: p.inherited,
+		u:             *p.u.DeepCopy(),
+		targetRef:     p.targetRef,
+		inherited:     p.inherited,
+		mergeStrategy: p.mergeStrategy,
+	}
+	if p.origins != nil {
+		clone.origins = make(map[string]ValueOrigin)
+		for field, origin := range p.origins {
+			clone.origins[field] = origin
+		}
 	}
 	return clone
 }
 
+// Origin returns the policy which provides the value of the top-level field
+// of the effective spec. It returns false if the effective spec does not have
+// the field.
+func (p Policy) Origin(field string) (ValueOrigin, bool) {
+	if p.origins != nil {
+		origin, ok := p.origins[field]
+		return origin, ok
+	}
+
+	origin := ValueOrigin{
+		PolicyNamespace: p.u.GetNamespace(),
+		PolicyName:      p.u.GetName(),
+		TargetRef:       p.targetRef,
+	}
+	spec := p.Spec()
+	if !p.IsInherited() {
+		_, ok := spec[field]
+		return origin, ok && field != "targetRef"
+	}
+	for _, specField := range []string{"override", "default"} {
+		values, _ := spec[specField].(map[string]interface{})
+		if _, ok := values[field]; ok {
+			origin.Field = specField
+			return origin, true
+		}
+	}
+	return ValueOrigin{}, false
+}
+
 func (p Policy) Spec() map[string]interface{} {
 	spec, ok, err := unstructured.NestedFieldCopy(p.u.UnstructuredContent(), "spec")
 	if err != nil || !ok {
@@ -0,0 +1,302 @@
+package policymanager
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestObjRefNormalize(t *testing.T) {
+	testCases := []struct {
+		name             string
+		objRef           ObjRef
+		defaultNamespace string
+		want             ObjRef
+	}{
+		{
+			name:             "namespaced kind without namespace gets default namespace",
+			objRef:           ObjRef{Group: "gateway.networking.k8s.io", Kind: "Gateway", Name: "foo"},
+			defaultNamespace: "ns1",
+			want:             ObjRef{Group: "gateway.networking.k8s.io", Kind: "Gateway", Name: "foo", Namespace: "ns1"},
+		},
+		{
+			name:             "namespaced kind with namespace keeps namespace",
+			objRef:           ObjRef{Group: "gateway.networking.k8s.io", Kind: "Gateway", Name: "foo", Namespace: "ns2"},
+			defaultNamespace: "ns1",
+			want:             ObjRef{Group: "gateway.networking.k8s.io", Kind: "Gateway", Name: "foo", Namespace: "ns2"},
+		},
+		{
+			name:             "namespaced kind in default namespace keeps namespace",
+			objRef:           ObjRef{Group: "gateway.networking.k8s.io", Kind: "HTTPRoute", Name: "foo", Namespace: "default"},
+			defaultNamespace: "",
+			want:             ObjRef{Group: "gateway.networking.k8s.io", Kind: "HTTPRoute", Name: "foo", Namespace: "default"},
+		},
+		{
+			name:             "namespaced kind without any namespace stays unset",
+			objRef:           ObjRef{Group: "gateway.networking.k8s.io", Kind: "HTTPRoute", Name: "foo"},
+			defaultNamespace: "",
+			want:             ObjRef{Group: "gateway.networking.k8s.io", Kind: "HTTPRoute", Name: "foo"},
+		},
+		{
+			name:             "GatewayClass never has a namespace",
+			objRef:           ObjRef{Group: "gateway.networking.k8s.io", Kind: "GatewayClass", Name: "foo", Namespace: "ns2"},
+			defaultNamespace: "ns1",
+			want:             ObjRef{Group: "gateway.networking.k8s.io", Kind: "GatewayClass", Name: "foo"},
+		},
+		{
+			name:             "Namespace never has a namespace",
+			objRef:           ObjRef{Kind: "Namespace", Name: "default"},
+			defaultNamespace: "ns1",
+			want:             ObjRef{Kind: "Namespace", Name: "default"},
+		},
+		{
+			name:             "kind with same name in a different group is namespaced",
+			objRef:           ObjRef{Group: "foo.com", Kind: "GatewayClass", Name: "foo"},
+			defaultNamespace: "ns1",
+			want:             ObjRef{Group: "foo.com", Kind: "GatewayClass", Name: "foo", Namespace: "ns1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.objRef.Normalize(tc.defaultNamespace)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Normalize(%q) returned unexpected diff (-want, +got): \n%v", tc.defaultNamespace, diff)
+			}
+		})
+	}
+}
+
+func TestPolicyFromUnstrucutred_TargetRef(t *testing.T) {
+	policyCRDs := map[PolicyCrdID]PolicyCRD{
+		"HealthCheckPolicy.foo.com": {
+			crd: apiextensionsv1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{gatewayPolicyLabelKey: "inherited"},
+				},
+				Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+					Group: "foo.com",
+					Names: apiextensionsv1.CustomResourceDefinitionNames{Kind: "HealthCheckPolicy"},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name            string
+		policyNamespace string
+		targetRef       map[string]interface{}
+		want            ObjRef
+		wantAttachedTo  []ObjRef
+		wantNotAttached []ObjRef
+	}{
+		{
+			name:            "namespaced policy targeting namespaced object without namespace",
+			policyNamespace: "ns1",
+			targetRef:       map[string]interface{}{"group": "gateway.networking.k8s.io", "kind": "Gateway", "name": "foo"},
+			want:            ObjRef{Group: "gateway.networking.k8s.io", Kind: "Gateway", Name: "foo", Namespace: "ns1"},
+			wantAttachedTo: []ObjRef{
+				{Group: "gateway.networking.k8s.io", Kind: "Gateway", Name: "foo", Namespace: "ns1"},
+			},
+			wantNotAttached: []ObjRef{
+				{Group: "gateway.networking.k8s.io", Kind: "Gateway", Name: "foo", Namespace: "default"},
+				{Group: "gateway.networking.k8s.io", Kind: "Gateway", Name: "foo"},
+			},
+		},
+		{
+			name:            "namespaced policy in default namespace targeting namespaced object",
+			policyNamespace: "default",
+			targetRef:       map[string]interface{}{"group": "gateway.networking.k8s.io", "kind": "HTTPRoute", "name": "foo"},
+			want:            ObjRef{Group: "gateway.networking.k8s.io", Kind: "HTTPRoute", Name: "foo", Namespace: "default"},
+			wantAttachedTo: []ObjRef{
+				{Group: "gateway.networking.k8s.io", Kind: "HTTPRoute", Name: "foo", Namespace: "default"},
+			},
+			wantNotAttached: []ObjRef{
+				{Group: "gateway.networking.k8s.io", Kind: "HTTPRoute", Name: "foo"},
+				{Group: "gateway.networking.k8s.io", Kind: "HTTPRoute", Name: "foo", Namespace: "ns1"},
+			},
+		},
+		{
+			name:            "namespaced policy targeting namespaced object in another namespace",
+			policyNamespace: "ns1",
+			targetRef:       map[string]interface{}{"group": "gateway.networking.k8s.io", "kind": "Gateway", "name": "foo", "namespace": "ns2"},
+			want:            ObjRef{Group: "gateway.networking.k8s.io", Kind: "Gateway", Name: "foo", Namespace: "ns2"},
+			wantAttachedTo: []ObjRef{
+				{Group: "gateway.networking.k8s.io", Kind: "Gateway", Name: "foo", Namespace: "ns2"},
+			},
+			wantNotAttached: []ObjRef{
+				{Group: "gateway.networking.k8s.io", Kind: "Gateway", Name: "foo", Namespace: "ns1"},
+			},
+		},
+		{
+			name:            "namespaced policy targeting GatewayClass",
+			policyNamespace: "ns1",
+			targetRef:       map[string]interface{}{"group": "gateway.networking.k8s.io", "kind": "GatewayClass", "name": "foo"},
+			want:            ObjRef{Group: "gateway.networking.k8s.io", Kind: "GatewayClass", Name: "foo"},
+			wantAttachedTo: []ObjRef{
+				{Group: "gateway.networking.k8s.io", Kind: "GatewayClass", Name: "foo"},
+				{Group: "gateway.networking.k8s.io", Kind: "GatewayClass", Name: "foo", Namespace: "ns1"},
+			},
+			wantNotAttached: []ObjRef{
+				{Group: "gateway.networking.k8s.io", Kind: "GatewayClass", Name: "bar"},
+			},
+		},
+		{
+			name:            "namespaced policy targeting Namespace",
+			policyNamespace: "ns1",
+			targetRef:       map[string]interface{}{"kind": "Namespace", "name": "ns1"},
+			want:            ObjRef{Kind: "Namespace", Name: "ns1"},
+			wantAttachedTo: []ObjRef{
+				{Kind: "Namespace", Name: "ns1"},
+			},
+			wantNotAttached: []ObjRef{
+				{Kind: "Namespace", Name: "default"},
+				{Kind: "Namespace"},
+			},
+		},
+		{
+			name:            "cluster scoped policy targeting GatewayClass",
+			policyNamespace: "",
+			targetRef:       map[string]interface{}{"group": "gateway.networking.k8s.io", "kind": "GatewayClass", "name": "foo"},
+			want:            ObjRef{Group: "gateway.networking.k8s.io", Kind: "GatewayClass", Name: "foo"},
+			wantAttachedTo: []ObjRef{
+				{Group: "gateway.networking.k8s.io", Kind: "GatewayClass", Name: "foo"},
+			},
+		},
+		{
+			name:            "cluster scoped policy targeting Namespace named default",
+			policyNamespace: "",
+			targetRef:       map[string]interface{}{"kind": "Namespace", "name": "default"},
+			want:            ObjRef{Kind: "Namespace", Name: "default"},
+			wantAttachedTo: []ObjRef{
+				{Kind: "Namespace", Name: "default"},
+			},
+			wantNotAttached: []ObjRef{
+				{Kind: "Namespace"},
+			},
+		},
+		{
+			name:            "cluster scoped policy targeting namespaced object with namespace",
+			policyNamespace: "",
+			targetRef:       map[string]interface{}{"group": "gateway.networking.k8s.io", "kind": "Gateway", "name": "foo", "namespace": "default"},
+			want:            ObjRef{Group: "gateway.networking.k8s.io", Kind: "Gateway", Name: "foo", Namespace: "default"},
+			wantAttachedTo: []ObjRef{
+				{Group: "gateway.networking.k8s.io", Kind: "Gateway", Name: "foo", Namespace: "default"},
+			},
+			wantNotAttached: []ObjRef{
+				{Group: "gateway.networking.k8s.io", Kind: "Gateway", Name: "foo"},
+			},
+		},
+		{
+			name:            "cluster scoped policy targeting namespaced object without namespace",
+			policyNamespace: "",
+			targetRef:       map[string]interface{}{"group": "gateway.networking.k8s.io", "kind": "Gateway", "name": "foo"},
+			want:            ObjRef{Group: "gateway.networking.k8s.io", Kind: "Gateway", Name: "foo"},
+			wantNotAttached: []ObjRef{
+				{Group: "gateway.networking.k8s.io", Kind: "Gateway", Name: "foo", Namespace: "default"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			u := unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": "foo.com/v1",
+					"kind":       "HealthCheckPolicy",
+					"metadata": map[string]interface{}{
+						"name":      "policy",
+						"namespace": tc.policyNamespace,
+					},
+					"spec": map[string]interface{}{
+						"targetRef": tc.targetRef,
+					},
+				},
+			}
+			policy, err := PolicyFromUnstrucutred(u, policyCRDs)
+			if err != nil {
+				t.Fatalf("PolicyFromUnstrucutred returned err=%v; want no error", err)
+			}
+
+			if diff := cmp.Diff(tc.want, policy.TargetRef()); diff != "" {
+				t.Errorf("TargetRef() returned unexpected diff (-want, +got): \n%v", diff)
+			}
+			for _, objRef := range tc.wantAttachedTo {
+				if !policy.IsAttachedTo(objRef) {
+					t.Errorf("IsAttachedTo(%+v) = false; want true", objRef)
+				}
+			}
+			for _, objRef := range tc.wantNotAttached {
+				if policy.IsAttachedTo(objRef) {
+					t.Errorf("IsAttachedTo(%+v) = true; want false", objRef)
+				}
+			}
+		})
+	}
+}
+
+func TestClone_AddAndRemovePolicy(t *testing.T) {
+	policyCRD := PolicyCRD{
+		crd: apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{gatewayPolicyLabelKey: "direct"},
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Group: "bar.com",
+				Names: apiextensionsv1.CustomResourceDefinitionNames{Kind: "TimeoutPolicy"},
+			},
+		},
+	}
+	policy := func(kind, name string) unstructured.Unstructured {
+		return unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "bar.com/v1",
+				"kind":       kind,
+				"metadata": map[string]interface{}{
+					"name":      name,
+					"namespace": "default",
+				},
+				"spec": map[string]interface{}{
+					"targetRef": map[string]interface{}{"kind": "Namespace", "name": "default"},
+				},
+			},
+		}
+	}
+	policyNames := func(p *PolicyManager) []string {
+		var result []string
+		for _, policy := range p.PoliciesAttachedTo(ObjRef{Kind: "Namespace", Name: "default"}) {
+			result = append(result, policy.Unstructured().GetName())
+		}
+		return result
+	}
+
+	original := New(nil, nil)
+	original.policyCRDs[policyCRD.ID()] = policyCRD
+	if err := original.AddPolicy(policy("TimeoutPolicy", "existing")); err != nil {
+		t.Fatalf("AddPolicy() failed: %v", err)
+	}
+
+	clone := original.Clone()
+	if err := clone.AddPolicy(policy("TimeoutPolicy", "new")); err != nil {
+		t.Fatalf("AddPolicy() failed: %v", err)
+	}
+	if err := clone.AddPolicy(policy("UnknownPolicy", "unknown")); err == nil {
+		t.Errorf("AddPolicy() of a policy without a CRD succeeded; want error")
+	}
+	if clone.RemovePolicy(policy("UnknownPolicy", "existing")) {
+		t.Errorf("RemovePolicy() of a policy with a different kind returned true; want false")
+	}
+	if !clone.RemovePolicy(policy("TimeoutPolicy", "existing")) {
+		t.Errorf("RemovePolicy() of an existing policy returned false; want true")
+	}
+
+	if diff := cmp.Diff([]string{"existing"}, policyNames(original)); diff != "" {
+		t.Errorf("Policies of original PolicyManager changed (-want +got):\n%v", diff)
+	}
+	if diff := cmp.Diff([]string{"new"}, policyNames(clone)); diff != "" {
+		t.Errorf("Policies of cloned PolicyManager returned unexpected diff (-want +got):\n%v", diff)
+	}
+}
@@ -9,7 +9,9 @@ import (
 )
 
 // MergePoliciesOfSimilarKind will merge policies of similar Kind and return a
-// map of policies partitioned by their kind.
+// map of policies partitioned by their kind. The policies must be of the same
+// hierarchy, such that the policy of higher precedence wins for both
+// spec.default and spec.override.
 func MergePoliciesOfSimilarKind(policies []Policy) (map[PolicyCrdID]Policy, error) {
 	result := make(map[PolicyCrdID]Policy)
 	for _, policy := range policies {
@@ -24,7 +26,7 @@ func MergePoliciesOfSimilarKind(policies []Policy) (map[PolicyCrdID]Policy, erro
 		// Policy of kind policyCrdID already exists so merge them.
 		lowerPolicy, higherPolicy := orderPolicyByPrecedence(existingPolicy, policy)
 
-		res, err := mergePolicy(lowerPolicy, higherPolicy)
+		res, err := mergePolicy(lowerPolicy, higherPolicy, false)
 		if err != nil {
 			return nil, err
 		}
@@ -33,12 +35,23 @@ func MergePoliciesOfSimilarKind(policies []Policy) (map[PolicyCrdID]Policy, erro
 	return result, nil
 }
 
+// MergePoliciesOfSameHierarchy merges policies which are partitioned by their
+// kind, where the policy of higher precedence wins for both spec.default and
+// spec.override.
 func MergePoliciesOfSameHierarchy(policies1, policies2 map[PolicyCrdID]Policy) (map[PolicyCrdID]Policy, error) {
-	return mergePoliciesByKind(policies1, policies2, orderPolicyByPrecedence)
+	return mergePoliciesByKind(policies1, policies2, orderPolicyByPrecedence, false)
 }
 
+// MergePoliciesOfDifferentHierarchy merges the policies of a child into the
+// policies of its parent, both partitioned by their kind. As per [GEP-713],
+// the spec.default of the child wins over the parent (bottom-up), while the
+// spec.override of the parent wins over the child (top-down). Merging the
+// policies of more than two levels one level at a time, from the top down,
+// keeps the spec.override of the highest ancestor.
+//
+// [GEP-713]: https://gateway-api.sigs.k8s.io/geps/gep-713/#hierarchy
 func MergePoliciesOfDifferentHierarchy(parentPolicies, childPolicies map[PolicyCrdID]Policy) (map[PolicyCrdID]Policy, error) {
-	return mergePoliciesByKind(parentPolicies, childPolicies, func(a, b Policy) (Policy, Policy) { return a, b })
+	return mergePoliciesByKind(parentPolicies, childPolicies, func(a, b Policy) (Policy, Policy) { return a, b }, true)
 }
 
 // orderPolicyByPrecedence will decide the precedence of two policies as per the
@@ -72,8 +85,9 @@ func orderPolicyByPrecedence(a, b Policy) (Policy, Policy) {
 // mergePoliciesByKind will merge policies which are partitioned by their Kind.
 //
 // precedence function will order two policies such that the second policy
-// returned will have a higher precedence.
-func mergePoliciesByKind(policies1, policies2 map[PolicyCrdID]Policy, precedence func(a, b Policy) (Policy, Policy)) (map[PolicyCrdID]Policy, error) {
+// returned will have a higher precedence. parentOverrides is true if the first
+// policy is the parent of the second, see mergePolicy.
+func mergePoliciesByKind(policies1, policies2 map[PolicyCrdID]Policy, precedence func(a, b Policy) (Policy, Policy), parentOverrides bool) (map[PolicyCrdID]Policy, error) {
 	result := make(map[PolicyCrdID]Policy)
 
 	// Copy policies1 into result.
@@ -95,7 +109,7 @@ func mergePoliciesByKind(policies1, policies2 map[PolicyCrdID]Policy, precedence
 
 		lowerPolicy, higherPolicy := precedence(existingPolicy, policy)
 
-		res, err := mergePolicy(lowerPolicy, higherPolicy)
+		res, err := mergePolicy(lowerPolicy, higherPolicy, parentOverrides)
 		if err != nil {
 			return nil, err
 		}
@@ -104,7 +118,10 @@ func mergePoliciesByKind(policies1, policies2 map[PolicyCrdID]Policy, precedence
 	return result, nil
 }
 
-func mergePolicy(original, patch Policy) (Policy, error) {
+// mergePolicy merges patch into original, where patch has a higher precedence.
+// If parentOverrides is true, original is the parent of patch, such that the
+// spec.override of an inherited original takes precedence over patch.
+func mergePolicy(original, patch Policy, parentOverrides bool) (Policy, error) {
 	if original.PolicyCrdID() != patch.PolicyCrdID() {
 		return Policy{}, fmt.Errorf("cannot merge policies of different kind; kind1=%v, kind2=%v", original.PolicyCrdID(), patch.PolicyCrdID())
 	}
@@ -114,7 +131,7 @@ func mergePolicy(original, patch Policy) (Policy, error) {
 		return Policy{}, err
 	}
 
-	if original.IsInherited() {
+	if original.IsInherited() && parentOverrides {
 		// In case of an Inherited policy, the "spec.override" field of the parent
 		// should take precedence over the child. This means that the
 		// "spec.override" field of the original will have a higher priority. So we
@@ -138,8 +155,90 @@ func mergePolicy(original, patch Policy) (Policy, error) {
 		}
 	}
 
-	patch.u.Object = result
-	return patch, nil
+	if strategy := patch.mergeStrategy; strategy.Type != "" && strategy.Type != MergeStrategyJSONMerge {
+		// The fields besides spec are merged as usual, after which the values
+		// of the spec are merged by the strategy.
+		if spec, ok := mergeSpec(original, patch, strategy, parentOverrides); ok {
+			result["spec"] = spec
+			// Round trip through JSON, such that the values are represented
+			// as with any other strategy.
+			result, err = mergeUnstructured(result, map[string]interface{}{})
+			if err != nil {
+				return Policy{}, err
+			}
+		}
+	}
+
+	merged := patch
+	merged.u.Object = result
+	merged.origins = mergeOrigins(original, patch, merged, parentOverrides)
+	return merged, nil
+}
+
+// mergeSpec merges the spec of patch into the spec of original by the
+// strategy. It returns false if the values are not maps, in which case the
+// strategy does not apply.
+func mergeSpec(original, patch Policy, strategy MergeStrategy, parentOverrides bool) (map[string]interface{}, bool) {
+	originalSpec, patchSpec := original.Spec(), patch.Spec()
+	if !original.IsInherited() {
+		targetRef, hasTargetRef := patchSpec["targetRef"]
+		delete(originalSpec, "targetRef")
+		delete(patchSpec, "targetRef")
+		result := strategy.mergeValues(originalSpec, patchSpec)
+		if hasTargetRef {
+			result["targetRef"] = targetRef
+		}
+		return result, true
+	}
+
+	result := patchSpec
+	if result == nil {
+		result = make(map[string]interface{})
+	}
+	for _, field := range []string{"default", "override"} {
+		originalValues, ok1 := originalSpec[field].(map[string]interface{})
+		patchValues, ok2 := patchSpec[field].(map[string]interface{})
+		if (!ok1 && originalSpec[field] != nil) || (!ok2 && patchSpec[field] != nil) {
+			return nil, false
+		}
+		if originalValues == nil && patchValues == nil {
+			continue
+		}
+		if field == "override" && parentOverrides {
+			// The override of the original takes precedence, as in
+			// mergePolicy.
+			originalValues, patchValues = patchValues, originalValues
+		}
+		result[field] = strategy.mergeValues(originalValues, patchValues)
+	}
+	return result, true
+}
+
+// mergeOrigins returns the origins of the top-level fields of the effective
+// spec of merged, which is the result of merging patch into original.
+func mergeOrigins(original, patch, merged Policy, parentOverrides bool) map[string]ValueOrigin {
+	result := make(map[string]ValueOrigin)
+	effectiveSpec, err := merged.EffectiveSpec()
+	if err != nil {
+		return result
+	}
+	for field := range effectiveSpec {
+		originalOrigin, inOriginal := original.Origin(field)
+		patchOrigin, inPatch := patch.Origin(field)
+		originalOverrides := inOriginal && originalOrigin.Field == "override"
+		switch {
+		case originalOverrides && parentOverrides:
+			// The override of the parent wins over the patch.
+			result[field] = originalOrigin
+		case inPatch && (patchOrigin.Field == "override" || !originalOverrides):
+			// Otherwise the patch wins, except that an override of the
+			// original wins over a default of the patch.
+			result[field] = patchOrigin
+		case inOriginal:
+			result[field] = originalOrigin
+		}
+	}
+	return result
 }
 
 func mergeUnstructured(original, patch map[string]interface{}) (map[string]interface{}, error) {
@@ -111,8 +111,11 @@ func TestMergePoliciesOfSimilarKind(t *testing.T) {
 						"creationTimestamp": timeSmall,
 					},
 					"spec": map[string]interface{}{
+						// The older health-check-1 takes precedence over
+						// health-check-2 for both spec.override and
+						// spec.default.
 						"override": map[string]interface{}{
-							"key1": "f",
+							"key1": "a",
 							"key3": "b",
 						},
 						"default": map[string]interface{}{
@@ -124,6 +127,13 @@ func TestMergePoliciesOfSimilarKind(t *testing.T) {
 				},
 			},
 			inherited: true,
+			origins: map[string]ValueOrigin{
+				"key1": {Field: "override", PolicyName: "health-check-1"},
+				"key2": {Field: "default", PolicyName: "health-check-1"},
+				"key3": {Field: "override", PolicyName: "health-check-1"},
+				"key4": {Field: "default", PolicyName: "health-check-1"},
+				"key5": {Field: "default", PolicyName: "health-check-1"},
+			},
 		},
 		PolicyCrdID("TimeoutPolicy.bar.com"): {
 			u: unstructured.Unstructured{
@@ -143,6 +153,10 @@ func TestMergePoliciesOfSimilarKind(t *testing.T) {
 					},
 				},
 			},
+			origins: map[string]ValueOrigin{
+				"condition": {PolicyName: "timeout-policy-1"},
+				"seconds":   {PolicyName: "timeout-policy-1"},
+			},
 		},
 	}
 
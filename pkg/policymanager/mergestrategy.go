@@ -0,0 +1,187 @@
+package policymanager
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	// MergeStrategyAnnotation is set on a Policy CRD to the MergeStrategyType
+	// of its policies.
+	MergeStrategyAnnotation = "gwctl/merge-strategy"
+	// MergeListKeysAnnotation is set on a Policy CRD to the list keys of the
+	// strategic merge, formatted as "<field>=<key>,<field>=<key>".
+	MergeListKeysAnnotation = "gwctl/merge-list-keys"
+)
+
+// MergeStrategyType decides how the values of two policies of the same kind
+// are merged, where the values are the fields within spec.default and
+// spec.override of inherited policies and the fields within spec of direct
+// policies.
+type MergeStrategyType string
+
+const (
+	// MergeStrategyJSONMerge merges the values as an RFC 7386 JSON merge patch,
+	// i.e. maps are merged recursively and lists are replaced. This is the
+	// default.
+	MergeStrategyJSONMerge MergeStrategyType = "json-merge"
+	// MergeStrategyStrategic merges maps recursively, and merges lists whose
+	// field has a list key by the value of the key within their items. Other
+	// lists are replaced.
+	MergeStrategyStrategic MergeStrategyType = "strategic"
+	// MergeStrategyAtomic replaces each value as a whole, without merging maps
+	// or lists.
+	MergeStrategyAtomic MergeStrategyType = "atomic"
+	// MergeStrategyDeep merges maps recursively and appends the items of
+	// lists which are missing from the list of lower precedence.
+	MergeStrategyDeep MergeStrategyType = "deep"
+)
+
+var mergeStrategyTypes = []MergeStrategyType{MergeStrategyJSONMerge, MergeStrategyStrategic, MergeStrategyAtomic, MergeStrategyDeep}
+
+// MergeStrategy decides how policies of the same kind are merged.
+type MergeStrategy struct {
+	Type MergeStrategyType
+	// ListKeys maps the name of a list field to the key which identifies its
+	// items. It only applies to MergeStrategyStrategic.
+	ListKeys map[string]string
+}
+
+// ParseMergeStrategy parses a MergeStrategy from the values of the
+// MergeStrategyAnnotation and MergeListKeysAnnotation. An empty strategyType
+// defaults to MergeStrategyJSONMerge.
+func ParseMergeStrategy(strategyType, listKeys string) (MergeStrategy, error) {
+	result := MergeStrategy{Type: MergeStrategyType(strategyType)}
+	if result.Type == "" {
+		result.Type = MergeStrategyJSONMerge
+	}
+	valid := false
+	for _, t := range mergeStrategyTypes {
+		valid = valid || t == result.Type
+	}
+	if !valid {
+		return MergeStrategy{}, fmt.Errorf("unknown merge strategy %q, must be one of %v", strategyType, mergeStrategyTypes)
+	}
+
+	if listKeys == "" {
+		return result, nil
+	}
+	if result.Type != MergeStrategyStrategic {
+		return MergeStrategy{}, fmt.Errorf("list keys only apply to the %q merge strategy", MergeStrategyStrategic)
+	}
+	result.ListKeys = make(map[string]string)
+	for _, pair := range strings.Split(listKeys, ",") {
+		field, key, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || field == "" || key == "" {
+			return MergeStrategy{}, fmt.Errorf("invalid list key %q, must be formatted as <field>=<key>", pair)
+		}
+		result.ListKeys[field] = key
+	}
+	return result, nil
+}
+
+func (m MergeStrategy) String() string {
+	if len(m.ListKeys) == 0 {
+		return string(m.Type)
+	}
+	var listKeys []string
+	for field, key := range m.ListKeys {
+		listKeys = append(listKeys, field+"="+key)
+	}
+	sort.Strings(listKeys)
+	return fmt.Sprintf("%v(%v)", m.Type, strings.Join(listKeys, ","))
+}
+
+// mergeValues merges the values of two policies, where patch takes precedence
+// over original. A nil value within patch deletes the field. It does not
+// apply to MergeStrategyJSONMerge, which is handled by mergeUnstructured.
+func (m MergeStrategy) mergeValues(original, patch map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	for field, value := range original {
+		result[field] = value
+	}
+	for field, value := range patch {
+		if value == nil {
+			delete(result, field)
+			continue
+		}
+		if m.Type == MergeStrategyAtomic {
+			result[field] = value
+			continue
+		}
+		result[field] = m.merge(field, result[field], value)
+	}
+	return result
+}
+
+// merge merges the value of the field for MergeStrategyStrategic and
+// MergeStrategyDeep.
+func (m MergeStrategy) merge(field string, original, patch interface{}) interface{} {
+	switch patch := patch.(type) {
+	case map[string]interface{}:
+		if original, ok := original.(map[string]interface{}); ok {
+			return m.mergeValues(original, patch)
+		}
+	case []interface{}:
+		original, ok := original.([]interface{})
+		if !ok {
+			break
+		}
+		if m.Type == MergeStrategyDeep {
+			return appendMissing(original, patch)
+		}
+		if key, ok := m.ListKeys[field]; ok {
+			if result, ok := m.mergeByKey(key, original, patch); ok {
+				return result
+			}
+		}
+	}
+	return patch
+}
+
+// mergeByKey merges the items of the lists which have the same value of key,
+// and appends the other items of patch. It returns false if any item is not a
+// map with the key.
+func (m MergeStrategy) mergeByKey(key string, original, patch []interface{}) ([]interface{}, bool) {
+	result := append([]interface{}{}, original...)
+	indexByKey := make(map[interface{}]int)
+	for i, item := range original {
+		item, ok := item.(map[string]interface{})
+		if !ok || item[key] == nil {
+			return nil, false
+		}
+		indexByKey[fmt.Sprint(item[key])] = i
+	}
+	for _, item := range patch {
+		item, ok := item.(map[string]interface{})
+		if !ok || item[key] == nil {
+			return nil, false
+		}
+		if i, ok := indexByKey[fmt.Sprint(item[key])]; ok {
+			result[i] = m.mergeValues(result[i].(map[string]interface{}), item)
+			continue
+		}
+		result = append(result, item)
+	}
+	return result, true
+}
+
+// appendMissing appends the items of patch which are missing from original.
+func appendMissing(original, patch []interface{}) []interface{} {
+	result := append([]interface{}{}, original...)
+	for _, item := range patch {
+		missing := true
+		for _, existing := range original {
+			if reflect.DeepEqual(item, existing) {
+				missing = false
+				break
+			}
+		}
+		if missing {
+			result = append(result, item)
+		}
+	}
+	return result
+}
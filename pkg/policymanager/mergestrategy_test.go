@@ -0,0 +1,276 @@
+package policymanager
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestParseMergeStrategy(t *testing.T) {
+	testCases := []struct {
+		name         string
+		strategyType string
+		listKeys     string
+		want         MergeStrategy
+		wantErr      bool
+	}{
+		{
+			name: "default",
+			want: MergeStrategy{Type: MergeStrategyJSONMerge},
+		},
+		{
+			name:         "strategic with list keys",
+			strategyType: "strategic",
+			listKeys:     "rules=name, headers=name",
+			want:         MergeStrategy{Type: MergeStrategyStrategic, ListKeys: map[string]string{"rules": "name", "headers": "name"}},
+		},
+		{
+			name:         "unknown type",
+			strategyType: "replace",
+			wantErr:      true,
+		},
+		{
+			name:         "list keys without strategic",
+			strategyType: "deep",
+			listKeys:     "rules=name",
+			wantErr:      true,
+		},
+		{
+			name:         "invalid list keys",
+			strategyType: "strategic",
+			listKeys:     "rules",
+			wantErr:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseMergeStrategy(tc.strategyType, tc.listKeys)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseMergeStrategy() returned err=%v; wantErr=%v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ParseMergeStrategy() returned unexpected diff (-want +got)=\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestMergeStrategies(t *testing.T) {
+	policy := func(name string, inherited bool, annotations map[string]string, spec map[string]interface{}) Policy {
+		crd := apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      map[string]string{gatewayPolicyLabelKey: "direct"},
+				Annotations: annotations,
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Group: "foo.com",
+				Names: apiextensionsv1.CustomResourceDefinitionNames{Kind: "RoutingPolicy"},
+			},
+		}
+		if inherited {
+			crd.Labels[gatewayPolicyLabelKey] = "inherited"
+		}
+		u := unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "foo.com/v1",
+			"kind":       "RoutingPolicy",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+			"spec":       spec,
+		}}
+		result, err := PolicyFromUnstrucutred(u, map[PolicyCrdID]PolicyCRD{"RoutingPolicy.foo.com": {crd: crd}})
+		if err != nil {
+			t.Fatalf("PolicyFromUnstrucutred() returned err=%v; want no error", err)
+		}
+		return result
+	}
+	parentValues := map[string]interface{}{
+		"headers": map[string]interface{}{"a": "parent", "b": "parent"},
+		"rules": []interface{}{
+			map[string]interface{}{"name": "r1", "weight": int64(1), "path": "/parent"},
+			map[string]interface{}{"name": "r2", "weight": int64(2)},
+		},
+	}
+	childValues := map[string]interface{}{
+		"headers": map[string]interface{}{"a": "child"},
+		"rules": []interface{}{
+			map[string]interface{}{"name": "r1", "weight": int64(10)},
+			map[string]interface{}{"name": "r3", "weight": int64(3)},
+		},
+	}
+
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		want        map[string]interface{}
+	}{
+		{
+			name: "json-merge by default",
+			want: map[string]interface{}{
+				"headers": map[string]interface{}{"a": "child", "b": "parent"},
+				"rules": []interface{}{
+					map[string]interface{}{"name": "r1", "weight": float64(10)},
+					map[string]interface{}{"name": "r3", "weight": float64(3)},
+				},
+			},
+		},
+		{
+			name:        "atomic",
+			annotations: map[string]string{MergeStrategyAnnotation: "atomic"},
+			want: map[string]interface{}{
+				"headers": map[string]interface{}{"a": "child"},
+				"rules": []interface{}{
+					map[string]interface{}{"name": "r1", "weight": float64(10)},
+					map[string]interface{}{"name": "r3", "weight": float64(3)},
+				},
+			},
+		},
+		{
+			name:        "deep",
+			annotations: map[string]string{MergeStrategyAnnotation: "deep"},
+			want: map[string]interface{}{
+				"headers": map[string]interface{}{"a": "child", "b": "parent"},
+				"rules": []interface{}{
+					map[string]interface{}{"name": "r1", "weight": float64(1), "path": "/parent"},
+					map[string]interface{}{"name": "r2", "weight": float64(2)},
+					map[string]interface{}{"name": "r1", "weight": float64(10)},
+					map[string]interface{}{"name": "r3", "weight": float64(3)},
+				},
+			},
+		},
+		{
+			name:        "strategic",
+			annotations: map[string]string{MergeStrategyAnnotation: "strategic", MergeListKeysAnnotation: "rules=name"},
+			want: map[string]interface{}{
+				"headers": map[string]interface{}{"a": "child", "b": "parent"},
+				"rules": []interface{}{
+					map[string]interface{}{"name": "r1", "weight": float64(10), "path": "/parent"},
+					map[string]interface{}{"name": "r2", "weight": float64(2)},
+					map[string]interface{}{"name": "r3", "weight": float64(3)},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name+"/inherited default", func(t *testing.T) {
+			parent := policy("parent", true, tc.annotations, map[string]interface{}{"default": parentValues})
+			child := policy("child", true, tc.annotations, map[string]interface{}{"default": childValues})
+			merged, err := MergePoliciesOfDifferentHierarchy(
+				map[PolicyCrdID]Policy{"RoutingPolicy.foo.com": parent},
+				map[PolicyCrdID]Policy{"RoutingPolicy.foo.com": child},
+			)
+			if err != nil {
+				t.Fatalf("MergePoliciesOfDifferentHierarchy() returned err=%v; want no error", err)
+			}
+			got, err := merged["RoutingPolicy.foo.com"].EffectiveSpec()
+			if err != nil {
+				t.Fatalf("EffectiveSpec() returned err=%v; want no error", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("EffectiveSpec() returned unexpected diff (-want +got)=\n%v", diff)
+			}
+		})
+
+		// The override of the parent takes precedence over the child.
+		t.Run(tc.name+"/inherited override", func(t *testing.T) {
+			parent := policy("parent", true, tc.annotations, map[string]interface{}{"override": childValues})
+			child := policy("child", true, tc.annotations, map[string]interface{}{"override": parentValues})
+			merged, err := MergePoliciesOfDifferentHierarchy(
+				map[PolicyCrdID]Policy{"RoutingPolicy.foo.com": parent},
+				map[PolicyCrdID]Policy{"RoutingPolicy.foo.com": child},
+			)
+			if err != nil {
+				t.Fatalf("MergePoliciesOfDifferentHierarchy() returned err=%v; want no error", err)
+			}
+			got, err := merged["RoutingPolicy.foo.com"].EffectiveSpec()
+			if err != nil {
+				t.Fatalf("EffectiveSpec() returned err=%v; want no error", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("EffectiveSpec() returned unexpected diff (-want +got)=\n%v", diff)
+			}
+		})
+
+		t.Run(tc.name+"/direct", func(t *testing.T) {
+			targetRef := map[string]interface{}{"kind": "Namespace", "name": "default"}
+			parentSpec := map[string]interface{}{"targetRef": targetRef}
+			childSpec := map[string]interface{}{"targetRef": targetRef}
+			for field, value := range parentValues {
+				parentSpec[field] = value
+			}
+			for field, value := range childValues {
+				childSpec[field] = value
+			}
+			merged, err := MergePoliciesOfSameHierarchy(
+				map[PolicyCrdID]Policy{"RoutingPolicy.foo.com": policy("parent", false, tc.annotations, parentSpec)},
+				map[PolicyCrdID]Policy{"RoutingPolicy.foo.com": policy("child", false, tc.annotations, childSpec)},
+			)
+			if err != nil {
+				t.Fatalf("MergePoliciesOfSameHierarchy() returned err=%v; want no error", err)
+			}
+			got, err := merged["RoutingPolicy.foo.com"].EffectiveSpec()
+			if err != nil {
+				t.Fatalf("EffectiveSpec() returned err=%v; want no error", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("EffectiveSpec() returned unexpected diff (-want +got)=\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestSetMergeStrategy(t *testing.T) {
+	policyCRD := PolicyCRD{
+		crd: apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      map[string]string{gatewayPolicyLabelKey: "inherited"},
+				Annotations: map[string]string{MergeStrategyAnnotation: "unknown"},
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Group: "foo.com",
+				Names: apiextensionsv1.CustomResourceDefinitionNames{Kind: "RoutingPolicy"},
+			},
+		},
+	}
+	wantIssues := []SchemaIssue{{SchemaIssueError, "", `invalid merge strategy, falling back to json-merge: unknown merge strategy "unknown", must be one of [json-merge strategic atomic deep]`}}
+	if diff := cmp.Diff(wantIssues, policyCRD.Validate()); diff != "" {
+		t.Errorf("Validate() returned unexpected diff (-want +got)=\n%v", diff)
+	}
+
+	manager := New(nil, nil)
+	manager.policyCRDs[policyCRD.ID()] = policyCRD
+	err := manager.AddPolicy(unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "foo.com/v1",
+		"kind":       "RoutingPolicy",
+		"metadata":   map[string]interface{}{"name": "routing", "namespace": "default"},
+		"spec":       map[string]interface{}{},
+	}})
+	if err != nil {
+		t.Fatalf("AddPolicy() returned err=%v; want no error", err)
+	}
+	if got := manager.GetPolicies()[0].mergeStrategy; got.Type != MergeStrategyJSONMerge {
+		t.Errorf("mergeStrategy of policy with invalid annotation = %v; want %v", got, MergeStrategyJSONMerge)
+	}
+
+	want := MergeStrategy{Type: MergeStrategyDeep}
+	if err := manager.SetMergeStrategy(policyCRD.ID(), want); err != nil {
+		t.Fatalf("SetMergeStrategy() returned err=%v; want no error", err)
+	}
+	if diff := cmp.Diff(want, manager.GetPolicies()[0].mergeStrategy); diff != "" {
+		t.Errorf("mergeStrategy of existing policy has unexpected diff (-want +got)=\n%v", diff)
+	}
+	crd, _ := manager.GetCRD(policyCRD.ID())
+	if got, err := crd.MergeStrategy(); err != nil || got.Type != want.Type {
+		t.Errorf("MergeStrategy() = %v, %v; want %v, nil", got, err, want)
+	}
+	if len(crd.Validate()) != 0 {
+		t.Errorf("Validate() = %v; want no issues once the merge strategy is set", crd.Validate())
+	}
+
+	if err := manager.SetMergeStrategy("UnknownPolicy.foo.com", want); err == nil {
+		t.Errorf("SetMergeStrategy() of unknown Policy CRD returned no error")
+	}
+}
@@ -0,0 +1,112 @@
+package policymanager
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// fetchReferenceGrants will fetch all ReferenceGrants through c, which reads
+// them in the newest version served by the cluster. An empty list is returned
+// if the ReferenceGrant CRD is not installed, as found by the discovery of c.
+func fetchReferenceGrants(ctx context.Context, c client.Client) ([]gatewayv1beta1.ReferenceGrant, error) {
+	grants := &gatewayv1beta1.ReferenceGrantList{}
+	if err := c.List(ctx, grants); err != nil {
+		if meta.IsNoMatchError(err) {
+			return []gatewayv1beta1.ReferenceGrant{}, nil
+		}
+		return []gatewayv1beta1.ReferenceGrant{}, fmt.Errorf("failed to list ReferenceGrants: %v", err)
+	}
+	return grants.Items, nil
+}
+
+// ReferenceGrants returns all ReferenceGrants in the cluster.
+func (p *PolicyManager) ReferenceGrants() []gatewayv1beta1.ReferenceGrant {
+	result := make([]gatewayv1beta1.ReferenceGrant, len(p.referenceGrants))
+	copy(result, p.referenceGrants)
+	return result
+}
+
+// IsReferencePermitted returns true if an object of kind from.Group/from.Kind
+// within from.Namespace is allowed to reference the object identified by to.
+//
+// References within the same namespace, references from cluster scoped objects
+// and references to cluster scoped objects are always permitted. A
+// cross-namespace reference is only permitted if a [ReferenceGrant] in the
+// namespace of the referenced object allows it, in which case the
+// ReferenceGrant is also returned.
+//
+// [ReferenceGrant]: https://gateway-api.sigs.k8s.io/api-types/referencegrant/
+func (p *PolicyManager) IsReferencePermitted(from, to ObjRef) (bool, *gatewayv1beta1.ReferenceGrant) {
+	if from.Namespace == "" || to.Namespace == "" || from.Namespace == to.Namespace || to.IsClusterScoped() {
+		return true, nil
+	}
+
+	for i := range p.referenceGrants {
+		grant := &p.referenceGrants[i]
+		if grant.GetNamespace() != to.Namespace {
+			continue
+		}
+		if grantAllows(grant, from, to) {
+			return true, grant.DeepCopy()
+		}
+	}
+	return false, nil
+}
+
+func grantAllows(grant *gatewayv1beta1.ReferenceGrant, from, to ObjRef) bool {
+	fromAllowed := false
+	for _, grantFrom := range grant.Spec.From {
+		if string(grantFrom.Group) == from.Group && string(grantFrom.Kind) == from.Kind && string(grantFrom.Namespace) == from.Namespace {
+			fromAllowed = true
+			break
+		}
+	}
+	if !fromAllowed {
+		return false
+	}
+
+	for _, grantTo := range grant.Spec.To {
+		if string(grantTo.Group) != to.Group || string(grantTo.Kind) != to.Kind {
+			continue
+		}
+		if grantTo.Name == nil || *grantTo.Name == "" || string(*grantTo.Name) == to.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBackendRefPermitted returns true if the HTTPRoute is allowed to reference
+// the backend. For a permitted cross-namespace reference, the ReferenceGrant
+// which permits it is also returned. backendRef is expected to be normalized,
+// see BackendRefToObjRef.
+func (p *PolicyManager) IsBackendRefPermitted(httpRoute gatewayv1beta1.HTTPRoute, backendRef ObjRef) (bool, *gatewayv1beta1.ReferenceGrant) {
+	from := ObjRef{
+		Group:     gatewayv1beta1.GroupName,
+		Kind:      "HTTPRoute",
+		Name:      httpRoute.GetName(),
+		Namespace: httpRoute.GetNamespace(),
+	}
+	return p.IsReferencePermitted(from, backendRef)
+}
+
+// referrer returns the reference to the Policy object itself, which is used to
+// identify the Policy as the originator of the targetRef.
+func (p Policy) referrer() ObjRef {
+	return ObjRef{
+		Group:     p.u.GroupVersionKind().Group,
+		Kind:      p.u.GroupVersionKind().Kind,
+		Name:      p.u.GetName(),
+		Namespace: p.u.GetNamespace(),
+	}
+}
+
+// IsTargetRefPermitted returns true if the Policy is allowed to target the
+// object within its targetRef. See IsReferencePermitted for details.
+func (p *PolicyManager) IsTargetRefPermitted(policy Policy) (bool, *gatewayv1beta1.ReferenceGrant) {
+	return p.IsReferencePermitted(policy.referrer(), policy.TargetRef())
+}
@@ -0,0 +1,163 @@
+package policymanager
+
+import (
+	"context"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamicclient "k8s.io/client-go/dynamic/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/gatewayapi"
+)
+
+// failingClient fails every List with err.
+type failingClient struct {
+	client.Client
+	err error
+}
+
+func (c *failingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return c.err
+}
+
+func TestFetchReferenceGrants(t *testing.T) {
+	// The cluster only serves ReferenceGrants in v1, so they are read through
+	// the dynamic client.
+	gvr := schema.GroupVersionResource{Group: gatewayv1beta1.GroupName, Version: "v1", Resource: "referencegrants"}
+	dc := fakedynamicclient.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "ReferenceGrantList"},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "gateway.networking.k8s.io/v1",
+			"kind":       "ReferenceGrant",
+			"metadata":   map[string]interface{}{"name": "allow-foo-svc", "namespace": "ns2"},
+			"spec": map[string]interface{}{
+				"from": []interface{}{map[string]interface{}{"group": gatewayv1beta1.GroupName, "kind": "HTTPRoute", "namespace": "ns1"}},
+				"to":   []interface{}{map[string]interface{}{"group": "", "kind": "Service"}},
+			},
+		}},
+	)
+	scheme := runtime.NewScheme()
+	gatewayv1beta1.AddToScheme(scheme)
+	served := &gatewayapi.ServedVersions{Versions: []string{"v1"}, ResourceVersions: map[string]string{"referencegrants": "v1"}}
+	c := gatewayapi.NewClient(fakeclient.NewClientBuilder().WithScheme(scheme).Build(), dc, served)
+
+	grants, err := fetchReferenceGrants(context.Background(), c)
+	if err != nil {
+		t.Fatalf("fetchReferenceGrants() failed: %v", err)
+	}
+	if len(grants) != 1 || grants[0].Name != "allow-foo-svc" {
+		t.Errorf("fetchReferenceGrants() = %+v; want allow-foo-svc", grants)
+	}
+
+	// Only a missing ReferenceGrant CRD is treated as having no
+	// ReferenceGrants.
+	notInstalled := &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: gatewayv1beta1.GroupName, Kind: "ReferenceGrant"}}
+	if grants, err := fetchReferenceGrants(context.Background(), &failingClient{err: notInstalled}); err != nil || len(grants) != 0 {
+		t.Errorf("fetchReferenceGrants() without the CRD = %v, %v; want no ReferenceGrants", grants, err)
+	}
+	notFound := apierrors.NewNotFound(gvr.GroupResource(), "")
+	if _, err := fetchReferenceGrants(context.Background(), &failingClient{err: notFound}); err == nil {
+		t.Errorf("fetchReferenceGrants() with a NotFound error succeeded; want error")
+	}
+}
+
+func TestIsReferencePermitted(t *testing.T) {
+	svcName := gatewayv1beta1.ObjectName("foo-svc")
+	p := &PolicyManager{
+		referenceGrants: []gatewayv1beta1.ReferenceGrant{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "allow-foo-svc", Namespace: "ns2"},
+				Spec: gatewayv1beta1.ReferenceGrantSpec{
+					From: []gatewayv1beta1.ReferenceGrantFrom{{Group: gatewayv1beta1.GroupName, Kind: "HTTPRoute", Namespace: "ns1"}},
+					To:   []gatewayv1beta1.ReferenceGrantTo{{Kind: "Service", Name: &svcName}},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "allow-all-gateways", Namespace: "ns3"},
+				Spec: gatewayv1beta1.ReferenceGrantSpec{
+					From: []gatewayv1beta1.ReferenceGrantFrom{{Group: "foo.com", Kind: "HealthCheckPolicy", Namespace: "ns1"}},
+					To:   []gatewayv1beta1.ReferenceGrantTo{{Group: gatewayv1beta1.GroupName, Kind: "Gateway"}},
+				},
+			},
+		},
+	}
+
+	httpRoute := ObjRef{Group: gatewayv1beta1.GroupName, Kind: "HTTPRoute", Name: "foo-httproute", Namespace: "ns1"}
+	policy := ObjRef{Group: "foo.com", Kind: "HealthCheckPolicy", Name: "health-check", Namespace: "ns1"}
+
+	testCases := []struct {
+		name      string
+		from      ObjRef
+		to        ObjRef
+		want      bool
+		wantGrant string
+	}{
+		{
+			name: "same namespace is permitted",
+			from: httpRoute,
+			to:   ObjRef{Kind: "Service", Name: "bar-svc", Namespace: "ns1"},
+			want: true,
+		},
+		{
+			name: "cluster scoped target is permitted",
+			from: policy,
+			to:   ObjRef{Kind: "Namespace", Name: "ns2"},
+			want: true,
+		},
+		{
+			name:      "cross namespace reference permitted by name",
+			from:      httpRoute,
+			to:        ObjRef{Kind: "Service", Name: "foo-svc", Namespace: "ns2"},
+			want:      true,
+			wantGrant: "allow-foo-svc",
+		},
+		{
+			name: "cross namespace reference to a different name is not permitted",
+			from: httpRoute,
+			to:   ObjRef{Kind: "Service", Name: "bar-svc", Namespace: "ns2"},
+			want: false,
+		},
+		{
+			name:      "cross namespace reference permitted for all names",
+			from:      policy,
+			to:        ObjRef{Group: gatewayv1beta1.GroupName, Kind: "Gateway", Name: "foo-gateway", Namespace: "ns3"},
+			want:      true,
+			wantGrant: "allow-all-gateways",
+		},
+		{
+			name: "cross namespace reference from a different kind is not permitted",
+			from: httpRoute,
+			to:   ObjRef{Group: gatewayv1beta1.GroupName, Kind: "Gateway", Name: "foo-gateway", Namespace: "ns3"},
+			want: false,
+		},
+		{
+			name: "grant in a different namespace does not apply",
+			from: httpRoute,
+			to:   ObjRef{Kind: "Service", Name: "foo-svc", Namespace: "ns3"},
+			want: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, grant := p.IsReferencePermitted(tc.from, tc.to)
+			if got != tc.want {
+				t.Errorf("IsReferencePermitted()=%v; want %v", got, tc.want)
+			}
+			gotGrant := ""
+			if grant != nil {
+				gotGrant = grant.GetName()
+			}
+			if gotGrant != tc.wantGrant {
+				t.Errorf("IsReferencePermitted() returned grant %q; want %q", gotGrant, tc.wantGrant)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package policymanager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+type SchemaIssueSeverity string
+
+const (
+	// SchemaIssueError is an issue which prevents gwctl from correctly
+	// interpreting the policies of the CRD.
+	SchemaIssueError SchemaIssueSeverity = "Error"
+	// SchemaIssueWarning is a deviation from the GEP-713 conventions which does
+	// not affect how gwctl interprets the policies of the CRD.
+	SchemaIssueWarning SchemaIssueSeverity = "Warning"
+)
+
+// SchemaIssue is a deviation of a Policy CRD schema from the conventions of
+// [GEP-713].
+//
+// [GEP-713]: https://gateway-api.sigs.k8s.io/geps/gep-713/
+type SchemaIssue struct {
+	Severity SchemaIssueSeverity
+	// Version of the CRD which has the issue, or empty if the issue applies to
+	// all versions.
+	Version string
+	Message string
+}
+
+func (s SchemaIssue) String() string {
+	if s.Version == "" {
+		return fmt.Sprintf("%v: %v", s.Severity, s.Message)
+	}
+	return fmt.Sprintf("%v: %v: %v", s.Severity, s.Version, s.Message)
+}
+
+// SplitSchemaIssues returns the messages of the errors and of the warnings
+// amongst the issues, each prefixed by the version which has the issue.
+func SplitSchemaIssues(issues []SchemaIssue) ([]string, []string) {
+	var errors, warnings []string
+	for _, issue := range issues {
+		message := issue.Message
+		if issue.Version != "" {
+			message = fmt.Sprintf("%v: %v", issue.Version, issue.Message)
+		}
+		if issue.Severity == SchemaIssueError {
+			errors = append(errors, message)
+		} else {
+			warnings = append(warnings, message)
+		}
+	}
+	return errors, warnings
+}
+
+// Validate inspects the schema of every served version of the Policy CRD, see
+// ValidateCRDSchema for details, along with its MergeStrategy.
+func (p PolicyCRD) Validate() []SchemaIssue {
+	result := ValidateCRDSchema(p.crd, p.IsInherited())
+	if _, err := p.MergeStrategy(); err != nil {
+		result = append(result, SchemaIssue{SchemaIssueError, "", fmt.Sprintf("invalid merge strategy, falling back to %v: %v", MergeStrategyJSONMerge, err)})
+	}
+	return result
+}
+
+// ValidateCRDSchema inspects the schema of every served version of the CRD and
+// reports the issues with respect to GEP-713 conventions:
+//
+//   - The spec must have a targetRef (or targetRefs) with group, kind and name.
+//   - Inherited policies must have spec.default and/or spec.override, which are
+//     needed to calculate the effective policy.
+//   - The status should follow PolicyAncestorStatus, i.e. have a list of
+//     ancestors, each with an ancestorRef, controllerName and conditions.
+//   - The scope of the CRD should match the kinds it targets (when the kinds
+//     are restricted through an enum within the schema). The kinds are
+//     looked up within the groups of the enum of the group, or else within
+//     the core and Gateway API groups. Cluster scoped policies can only
+//     target namespaced kinds if the targetRef has a namespace.
+func ValidateCRDSchema(crd apiextensionsv1.CustomResourceDefinition, inherited bool) []SchemaIssue {
+	var result []SchemaIssue
+	for _, version := range crd.Spec.Versions {
+		if !version.Served {
+			continue
+		}
+		if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+			result = append(result, SchemaIssue{SchemaIssueWarning, version.Name, "no schema, so the conventions cannot be verified"})
+			continue
+		}
+		for _, issue := range validateVersionSchema(*version.Schema.OpenAPIV3Schema, crd.Spec.Scope, inherited) {
+			issue.Version = version.Name
+			result = append(result, issue)
+		}
+	}
+	return result
+}
+
+func validateVersionSchema(schema apiextensionsv1.JSONSchemaProps, scope apiextensionsv1.ResourceScope, inherited bool) []SchemaIssue {
+	var result []SchemaIssue
+	addError := func(format string, a ...interface{}) {
+		result = append(result, SchemaIssue{Severity: SchemaIssueError, Message: fmt.Sprintf(format, a...)})
+	}
+	addWarning := func(format string, a ...interface{}) {
+		result = append(result, SchemaIssue{Severity: SchemaIssueWarning, Message: fmt.Sprintf(format, a...)})
+	}
+
+	spec := schema.Properties["spec"]
+
+	// targetRef(s)
+	var targetRef *apiextensionsv1.JSONSchemaProps
+	targetRefPath := "spec.targetRef"
+	if prop, ok := spec.Properties["targetRef"]; ok {
+		targetRef = &prop
+	} else if prop, ok := spec.Properties["targetRefs"]; ok {
+		targetRefPath = "spec.targetRefs[]"
+		if prop.Items != nil && prop.Items.Schema != nil {
+			targetRef = prop.Items.Schema
+		} else {
+			addError("%v has no schema for its items", targetRefPath)
+		}
+	} else {
+		addError("spec.targetRef (or spec.targetRefs) is missing")
+	}
+	if targetRef != nil {
+		if missing := missingProperties(*targetRef, "group", "kind", "name"); len(missing) != 0 {
+			addError("%v is missing [%v]", targetRefPath, strings.Join(missing, ", "))
+		}
+		result = append(result, validateScope(*targetRef, targetRefPath, scope)...)
+	}
+
+	// default and override
+	if inherited {
+		_, hasDefault := spec.Properties["default"]
+		_, hasOverride := spec.Properties["override"]
+		if !hasDefault && !hasOverride {
+			addError("inherited policy has neither spec.default nor spec.override")
+		}
+	}
+
+	// status
+	status, ok := schema.Properties["status"]
+	switch {
+	case !ok:
+		addWarning("status is missing, expected PolicyAncestorStatus")
+	case status.Properties["ancestors"].Items == nil || status.Properties["ancestors"].Items.Schema == nil:
+		addWarning("status.ancestors is missing, expected PolicyAncestorStatus")
+	default:
+		if missing := missingProperties(*status.Properties["ancestors"].Items.Schema, "ancestorRef", "controllerName", "conditions"); len(missing) != 0 {
+			addWarning("status.ancestors[] is missing [%v], expected PolicyAncestorStatus", strings.Join(missing, ", "))
+		}
+	}
+
+	return result
+}
+
+// validateScope checks that a policy with the given scope can target the kinds
+// allowed by the targetRef schema.
+func validateScope(targetRef apiextensionsv1.JSONSchemaProps, targetRefPath string, scope apiextensionsv1.ResourceScope) []SchemaIssue {
+	groups := enumValues(targetRef.Properties["group"])
+	if len(groups) == 0 {
+		groups = knownGroups
+	}
+	var clusterScoped, namespaced []string
+	for _, kind := range enumValues(targetRef.Properties["kind"]) {
+		if isClusterScopedKind(groups, kind) {
+			clusterScoped = append(clusterScoped, kind)
+		} else {
+			namespaced = append(namespaced, kind)
+		}
+	}
+
+	var result []SchemaIssue
+	_, hasNamespace := targetRef.Properties["namespace"]
+	if scope == apiextensionsv1.ClusterScoped && len(namespaced) != 0 && !hasNamespace {
+		result = append(result, SchemaIssue{
+			Severity: SchemaIssueError,
+			Message:  fmt.Sprintf("cluster scoped policy targets namespaced kinds [%v] but %v has no namespace", strings.Join(namespaced, ", "), targetRefPath),
+		})
+	}
+	if scope == apiextensionsv1.NamespaceScoped && len(clusterScoped) != 0 && len(namespaced) == 0 {
+		result = append(result, SchemaIssue{
+			Severity: SchemaIssueWarning,
+			Message:  fmt.Sprintf("namespaced policy only targets cluster scoped kinds [%v], consider making the CRD cluster scoped", strings.Join(clusterScoped, ", ")),
+		})
+	}
+	return result
+}
+
+// knownGroups are the groups of the kinds understood by gwctl, which are
+// assumed for targetRefs whose group is not restricted.
+var knownGroups = []string{"", gatewayv1alpha2.GroupName}
+
+// isClusterScopedKind returns true if the kind is cluster scoped within any of
+// the groups.
+func isClusterScopedKind(groups []string, kind string) bool {
+	for _, group := range groups {
+		if clusterScopedKinds[schema.GroupKind{Group: group, Kind: kind}] {
+			return true
+		}
+	}
+	return false
+}
+
+func missingProperties(schema apiextensionsv1.JSONSchemaProps, names ...string) []string {
+	var result []string
+	for _, name := range names {
+		if _, ok := schema.Properties[name]; !ok {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+func enumValues(schema apiextensionsv1.JSONSchemaProps) []string {
+	var result []string
+	for _, value := range schema.Enum {
+		// Enum values are JSON encoded.
+		result = append(result, strings.Trim(string(value.Raw), `"`))
+	}
+	sort.Strings(result)
+	return result
+}
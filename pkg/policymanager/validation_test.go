@@ -0,0 +1,141 @@
+package policymanager
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestValidateCRDSchema(t *testing.T) {
+	object := func(properties map[string]apiextensionsv1.JSONSchemaProps) apiextensionsv1.JSONSchemaProps {
+		return apiextensionsv1.JSONSchemaProps{Type: "object", Properties: properties}
+	}
+	str := apiextensionsv1.JSONSchemaProps{Type: "string"}
+	enum := func(values ...string) apiextensionsv1.JSONSchemaProps {
+		result := apiextensionsv1.JSONSchemaProps{Type: "string"}
+		for _, value := range values {
+			result.Enum = append(result.Enum, apiextensionsv1.JSON{Raw: []byte(`"` + value + `"`)})
+		}
+		return result
+	}
+	targetRef := object(map[string]apiextensionsv1.JSONSchemaProps{"group": str, "kind": str, "name": str, "namespace": str})
+	ancestorStatus := object(map[string]apiextensionsv1.JSONSchemaProps{
+		"ancestors": {Type: "array", Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &apiextensionsv1.JSONSchemaProps{
+			Type:       "object",
+			Properties: map[string]apiextensionsv1.JSONSchemaProps{"ancestorRef": targetRef, "controllerName": str, "conditions": {Type: "array"}},
+		}}},
+	})
+	crdWithSchema := func(scope apiextensionsv1.ResourceScope, schema *apiextensionsv1.JSONSchemaProps) apiextensionsv1.CustomResourceDefinition {
+		version := apiextensionsv1.CustomResourceDefinitionVersion{Name: "v1", Served: true}
+		if schema != nil {
+			version.Schema = &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: schema}
+		}
+		return apiextensionsv1.CustomResourceDefinition{
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Scope: scope,
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+					version,
+					// Versions which are not served are never validated.
+					{Name: "v1alpha1"},
+				},
+			},
+		}
+	}
+	policySchema := func(spec, status map[string]apiextensionsv1.JSONSchemaProps) *apiextensionsv1.JSONSchemaProps {
+		properties := map[string]apiextensionsv1.JSONSchemaProps{"spec": object(spec)}
+		if status != nil {
+			properties["status"] = object(status)
+		}
+		result := object(properties)
+		return &result
+	}
+
+	testCases := []struct {
+		name      string
+		crd       apiextensionsv1.CustomResourceDefinition
+		inherited bool
+		want      []SchemaIssue
+	}{
+		{
+			name: "valid inherited policy",
+			crd: crdWithSchema(apiextensionsv1.NamespaceScoped, policySchema(
+				map[string]apiextensionsv1.JSONSchemaProps{"targetRef": targetRef, "default": {Type: "object"}},
+				ancestorStatus.Properties,
+			)),
+			inherited: true,
+		},
+		{
+			name: "valid direct policy with targetRefs",
+			crd: crdWithSchema(apiextensionsv1.NamespaceScoped, policySchema(
+				map[string]apiextensionsv1.JSONSchemaProps{"targetRefs": {Type: "array", Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &targetRef}}},
+				ancestorStatus.Properties,
+			)),
+		},
+		{
+			name: "no schema",
+			crd:  crdWithSchema(apiextensionsv1.NamespaceScoped, nil),
+			want: []SchemaIssue{{SchemaIssueWarning, "v1", "no schema, so the conventions cannot be verified"}},
+		},
+		{
+			name:      "missing targetRef, default, override and status",
+			crd:       crdWithSchema(apiextensionsv1.NamespaceScoped, policySchema(map[string]apiextensionsv1.JSONSchemaProps{}, nil)),
+			inherited: true,
+			want: []SchemaIssue{
+				{SchemaIssueError, "v1", "spec.targetRef (or spec.targetRefs) is missing"},
+				{SchemaIssueError, "v1", "inherited policy has neither spec.default nor spec.override"},
+				{SchemaIssueWarning, "v1", "status is missing, expected PolicyAncestorStatus"},
+			},
+		},
+		{
+			name: "incomplete targetRef and status",
+			crd: crdWithSchema(apiextensionsv1.NamespaceScoped, policySchema(
+				map[string]apiextensionsv1.JSONSchemaProps{"targetRef": object(map[string]apiextensionsv1.JSONSchemaProps{"name": str})},
+				map[string]apiextensionsv1.JSONSchemaProps{"ancestors": {Type: "array", Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &apiextensionsv1.JSONSchemaProps{
+					Type:       "object",
+					Properties: map[string]apiextensionsv1.JSONSchemaProps{"ancestorRef": targetRef},
+				}}}},
+			)),
+			want: []SchemaIssue{
+				{SchemaIssueError, "v1", "spec.targetRef is missing [group, kind]"},
+				{SchemaIssueWarning, "v1", "status.ancestors[] is missing [controllerName, conditions], expected PolicyAncestorStatus"},
+			},
+		},
+		{
+			name: "cluster scoped policy targeting namespaced kinds without namespace",
+			crd: crdWithSchema(apiextensionsv1.ClusterScoped, policySchema(
+				map[string]apiextensionsv1.JSONSchemaProps{"targetRef": object(map[string]apiextensionsv1.JSONSchemaProps{"group": str, "kind": enum("Gateway", "GatewayClass", "HTTPRoute"), "name": str})},
+				ancestorStatus.Properties,
+			)),
+			want: []SchemaIssue{
+				{SchemaIssueError, "v1", "cluster scoped policy targets namespaced kinds [Gateway, HTTPRoute] but spec.targetRef has no namespace"},
+			},
+		},
+		{
+			name: "namespaced policy targeting only cluster scoped kinds",
+			crd: crdWithSchema(apiextensionsv1.NamespaceScoped, policySchema(
+				map[string]apiextensionsv1.JSONSchemaProps{"targetRef": object(map[string]apiextensionsv1.JSONSchemaProps{"group": str, "kind": enum("GatewayClass", "Namespace"), "name": str})},
+				ancestorStatus.Properties,
+			)),
+			want: []SchemaIssue{
+				{SchemaIssueWarning, "v1", "namespaced policy only targets cluster scoped kinds [GatewayClass, Namespace], consider making the CRD cluster scoped"},
+			},
+		},
+		{
+			name: "namespaced policy targeting kinds named like cluster scoped kinds in other groups",
+			crd: crdWithSchema(apiextensionsv1.NamespaceScoped, policySchema(
+				map[string]apiextensionsv1.JSONSchemaProps{"targetRef": object(map[string]apiextensionsv1.JSONSchemaProps{"group": enum("foo.com"), "kind": enum("GatewayClass", "Namespace"), "name": str})},
+				ancestorStatus.Properties,
+			)),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ValidateCRDSchema(tc.crd, tc.inherited)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ValidateCRDSchema() returned unexpected diff (-want +got)=\n%v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package renderers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// fieldPhrase describes a field of the effective spec with format, unless the
+// field is missing.
+type fieldPhrase struct {
+	field  string
+	format func(value interface{}) string
+}
+
+// renderFields returns the phrases of the fields, in the given order.
+func renderFields(effectiveSpec map[string]interface{}, fields []fieldPhrase) []Phrase {
+	var result []Phrase
+	for _, f := range fields {
+		value, ok := effectiveSpec[f.field]
+		if !ok {
+			continue
+		}
+		result = append(result, Phrase{Field: f.field, Text: f.format(value)})
+	}
+	return result
+}
+
+// prefixed formats values like "<prefix> <value>".
+func prefixed(prefix string) func(interface{}) string {
+	return func(value interface{}) string {
+		return fmt.Sprintf("%v %v", prefix, formatValue(value))
+	}
+}
+
+// duration formats values like "<prefix> <duration>", where numbers are
+// considered to be seconds.
+func duration(prefix string) func(interface{}) string {
+	return func(value interface{}) string {
+		switch value.(type) {
+		case int64, float64:
+			return fmt.Sprintf("%v %vs", prefix, value)
+		default:
+			return fmt.Sprintf("%v %v", prefix, formatValue(value))
+		}
+	}
+}
+
+// list formats lists of scalars like "<prefix> a, b".
+func list(prefix string) func(interface{}) string {
+	return func(value interface{}) string {
+		values, ok := value.([]interface{})
+		if !ok {
+			return fmt.Sprintf("%v %v", prefix, formatValue(value))
+		}
+		var items []string
+		for _, item := range values {
+			items = append(items, formatValue(item))
+		}
+		return fmt.Sprintf("%v %v", prefix, strings.Join(items, ", "))
+	}
+}
+
+func renderTimeoutPolicy(effectiveSpec map[string]interface{}) []Phrase {
+	return renderFields(effectiveSpec, []fieldPhrase{
+		{"seconds", duration("timeout")},
+		{"timeout", duration("timeout")},
+		{"request", duration("request timeout")},
+		{"backendRequest", duration("backend request timeout")},
+		{"idle", duration("idle timeout")},
+	})
+}
+
+func renderRetryPolicy(effectiveSpec map[string]interface{}) []Phrase {
+	retries := func(value interface{}) string {
+		return fmt.Sprintf("%v retries", formatValue(value))
+	}
+	return renderFields(effectiveSpec, []fieldPhrase{
+		{"attempts", retries},
+		{"retries", retries},
+		{"numRetries", retries},
+		{"perTryTimeout", duration("per try timeout")},
+		{"backoff", duration("backoff")},
+		{"retryOn", list("on")},
+		{"codes", list("on")},
+	})
+}
+
+func renderHealthCheckPolicy(effectiveSpec map[string]interface{}) []Phrase {
+	return renderFields(effectiveSpec, []fieldPhrase{
+		{"type", prefixed("type")},
+		{"protocol", prefixed("protocol")},
+		{"path", prefixed("path")},
+		{"requestPath", prefixed("path")},
+		{"port", prefixed("port")},
+		{"interval", duration("every")},
+		{"timeout", duration("timeout")},
+		{"healthyThreshold", prefixed("healthy threshold")},
+		{"unhealthyThreshold", prefixed("unhealthy threshold")},
+	})
+}
+
+// renderBackendTLSPolicy summarizes the TLS configuration, which is either
+// spec.tls or spec.validation depending on the version of the API, like "TLS
+// to foo.example.com verified by ConfigMap/ca".
+func renderBackendTLSPolicy(effectiveSpec map[string]interface{}) []Phrase {
+	var result []Phrase
+	for _, field := range []string{"tls", "validation"} {
+		tls, ok := effectiveSpec[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		text := "TLS"
+		if hostname, ok := tls["hostname"]; ok {
+			text += fmt.Sprintf(" to %v", formatValue(hostname))
+		}
+		var verifiers []string
+		caCertRefs, _ := tls["caCertRefs"].([]interface{})
+		if len(caCertRefs) == 0 {
+			caCertRefs, _ = tls["caCertificateRefs"].([]interface{})
+		}
+		for _, caCertRef := range caCertRefs {
+			ref, _ := caCertRef.(map[string]interface{})
+			verifiers = append(verifiers, fmt.Sprintf("%v/%v", ref["kind"], ref["name"]))
+		}
+		if wellKnownCACerts, ok := tls["wellKnownCACerts"]; ok {
+			verifiers = append(verifiers, fmt.Sprintf("%v CA certificates", formatValue(wellKnownCACerts)))
+		}
+		if len(verifiers) != 0 {
+			text += " verified by " + strings.Join(verifiers, ", ")
+		}
+		result = append(result, Phrase{Field: field, Text: text})
+	}
+	return result
+}
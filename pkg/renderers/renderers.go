@@ -0,0 +1,176 @@
+// Package renderers produces human readable summaries of policies, like
+// "timeout 30s (override from GatewayClass)".
+//
+// Summaries are produced by a Renderer registered for the kind of the policy.
+// Renderers for well-known policy kinds are built-in, and Register is the
+// extension point for other policy CRDs. Fields of the effective spec which
+// are not covered by a Renderer are summarized generically as "field=value".
+package renderers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/policymanager"
+)
+
+// Phrase describes the value of a single top-level field of the effective
+// spec of a policy, like "timeout 30s".
+type Phrase struct {
+	// Field is the top-level field of the effective spec which is described.
+	// It is used to annotate the phrase with the origin of the value.
+	Field string
+	Text  string
+}
+
+// Renderer summarizes the effective spec of a policy. Fields of the effective
+// spec without a phrase are summarized generically.
+type Renderer interface {
+	Render(effectiveSpec map[string]interface{}) []Phrase
+}
+
+// RendererFunc is an adapter to use ordinary functions as Renderers.
+type RendererFunc func(effectiveSpec map[string]interface{}) []Phrase
+
+func (f RendererFunc) Render(effectiveSpec map[string]interface{}) []Phrase {
+	return f(effectiveSpec)
+}
+
+// Generic summarizes all fields generically as "field=value". Registering it
+// for a kind disables the built-in Renderer of the kind.
+var Generic Renderer = RendererFunc(func(map[string]interface{}) []Phrase { return nil })
+
+var (
+	mu sync.RWMutex
+	// registry is keyed by either a kind or a PolicyCrdID.
+	registry = map[string]Renderer{
+		"BackendTLSPolicy":  RendererFunc(renderBackendTLSPolicy),
+		"HealthCheckPolicy": RendererFunc(renderHealthCheckPolicy),
+		"RetryPolicy":       RendererFunc(renderRetryPolicy),
+		"TimeoutPolicy":     RendererFunc(renderTimeoutPolicy),
+	}
+)
+
+// Register registers the Renderer for policies of the given kind. kind is
+// either a kind like "TimeoutPolicy", which matches the kind in all groups, or
+// a PolicyCrdID like "TimeoutPolicy.foo.com", which takes precedence over the
+// kind. Registering a kind again replaces its Renderer, including a built-in
+// one.
+func Register(kind string, renderer Renderer) {
+	mu.Lock()
+	defer mu.Unlock()
+	registry[kind] = renderer
+}
+
+func lookup(policy policymanager.Policy) (Renderer, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if renderer, ok := registry[string(policy.PolicyCrdID())]; ok {
+		return renderer, true
+	}
+	renderer, ok := registry[policy.Unstructured().GetKind()]
+	return renderer, ok
+}
+
+// Summary returns a human readable summary of the effective spec of the
+// policy. Phrases are grouped by the origin of their values, like "timeout 30s
+// (override from GatewayClass); 3 retries (default from Gateway)".
+func Summary(policy policymanager.Policy) string {
+	effectiveSpec, err := policy.EffectiveSpec()
+	if err != nil {
+		return fmt.Sprintf("<invalid: %v>", err)
+	}
+
+	var phrases []Phrase
+	if renderer, ok := lookup(policy); ok {
+		phrases = renderer.Render(effectiveSpec)
+	}
+	phrases = append(phrases, genericPhrases(effectiveSpec, phrases)...)
+
+	// Group the phrases by their origin, in the order of their first
+	// appearance.
+	var origins []string
+	phrasesByOrigin := make(map[string][]string)
+	for _, phrase := range phrases {
+		origin := originText(policy, phrase.Field)
+		if _, ok := phrasesByOrigin[origin]; !ok {
+			origins = append(origins, origin)
+		}
+		phrasesByOrigin[origin] = append(phrasesByOrigin[origin], phrase.Text)
+	}
+
+	var groups []string
+	for _, origin := range origins {
+		group := strings.Join(phrasesByOrigin[origin], ", ")
+		if origin != "" {
+			group += " " + origin
+		}
+		groups = append(groups, group)
+	}
+	return strings.Join(groups, "; ")
+}
+
+// Summaries returns the Summary of each of the policies.
+func Summaries(policies map[policymanager.PolicyCrdID]policymanager.Policy) map[policymanager.PolicyCrdID]string {
+	if len(policies) == 0 {
+		return nil
+	}
+	result := make(map[policymanager.PolicyCrdID]string)
+	for policyCrdID, policy := range policies {
+		result[policyCrdID] = Summary(policy)
+	}
+	return result
+}
+
+// genericPhrases returns "field=value" phrases for the fields of the effective
+// spec which are not described by any of the phrases.
+func genericPhrases(effectiveSpec map[string]interface{}, phrases []Phrase) []Phrase {
+	described := make(map[string]bool)
+	for _, phrase := range phrases {
+		described[phrase.Field] = true
+	}
+
+	var fields []string
+	for field := range effectiveSpec {
+		if !described[field] {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	var result []Phrase
+	for _, field := range fields {
+		result = append(result, Phrase{Field: field, Text: fmt.Sprintf("%v=%v", field, formatValue(effectiveSpec[field]))})
+	}
+	return result
+}
+
+// originText formats the origin of the value of the field, like "(override
+// from GatewayClass)".
+func originText(policy policymanager.Policy, field string) string {
+	origin, ok := policy.Origin(field)
+	if !ok || origin.TargetRef.Kind == "" {
+		return ""
+	}
+	if origin.Field == "" {
+		return fmt.Sprintf("(from %v)", origin.TargetRef.Kind)
+	}
+	return fmt.Sprintf("(%v from %v)", origin.Field, origin.TargetRef.Kind)
+}
+
+// formatValue formats scalars as is, and other values as compact JSON.
+func formatValue(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		b, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprintf("%v", value)
+		}
+		return string(b)
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
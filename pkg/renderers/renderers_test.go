@@ -0,0 +1,138 @@
+package renderers
+
+import (
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+	"github.com/gauravkghildiyal/gwctl/pkg/policymanager"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+)
+
+func TestSummary(t *testing.T) {
+	policyCRD := func(group, version, kind, plural, policyType string) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   plural + "." + group,
+				Labels: map[string]string{common.GatewayPolicyLabelKey: policyType},
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Scope:    apiextensionsv1.NamespaceScoped,
+				Group:    group,
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: version}},
+				Names:    apiextensionsv1.CustomResourceDefinitionNames{Plural: plural, Kind: kind},
+			},
+		}
+	}
+	policy := func(apiVersion, kind, name string, spec map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": apiVersion,
+				"kind":       kind,
+				"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+				"spec":       spec,
+			},
+		}
+	}
+	gatewayClassRef := map[string]interface{}{"group": "gateway.networking.k8s.io", "kind": "GatewayClass", "name": "foo-gatewayclass"}
+	gatewayRef := map[string]interface{}{"group": "gateway.networking.k8s.io", "kind": "Gateway", "name": "foo-gateway"}
+
+	objects := []runtime.Object{
+		policyCRD("foo.com", "v1", "TimeoutPolicy", "timeoutpolicies", "inherited"),
+		policy("foo.com/v1", "TimeoutPolicy", "timeout-gatewayclass", map[string]interface{}{
+			"targetRef": gatewayClassRef,
+			"override":  map[string]interface{}{"seconds": int64(30)},
+		}),
+		policy("foo.com/v1", "TimeoutPolicy", "timeout-gateway", map[string]interface{}{
+			"targetRef": gatewayRef,
+			"default":   map[string]interface{}{"seconds": int64(60), "idle": "5m"},
+		}),
+		policyCRD("foo.com", "v1", "RetryPolicy", "retrypolicies", "inherited"),
+		policy("foo.com/v1", "RetryPolicy", "retry", map[string]interface{}{
+			"targetRef": gatewayRef,
+			"default": map[string]interface{}{
+				"attempts": int64(3),
+				"retryOn":  []interface{}{"5xx", "reset"},
+				"extra":    map[string]interface{}{"a": "b"},
+			},
+		}),
+		policyCRD("gateway.networking.k8s.io", "v1alpha2", "BackendTLSPolicy", "backendtlspolicies", "direct"),
+		policy("gateway.networking.k8s.io/v1alpha2", "BackendTLSPolicy", "backend-tls", map[string]interface{}{
+			"targetRef": map[string]interface{}{"group": "", "kind": "Service", "name": "foo-svc"},
+			"tls": map[string]interface{}{
+				"hostname":   "foo.example.com",
+				"caCertRefs": []interface{}{map[string]interface{}{"group": "", "kind": "ConfigMap", "name": "ca"}},
+			},
+		}),
+		policyCRD("example.com", "v1", "InHousePolicy", "inhousepolicies", "direct"),
+		policy("example.com/v1", "InHousePolicy", "in-house", map[string]interface{}{
+			"targetRef": gatewayRef,
+			"mode":      "strict",
+		}),
+	}
+	params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
+	policiesByName := make(map[string]policymanager.Policy)
+	for _, policy := range params.PolicyManager.GetPolicies() {
+		policiesByName[policy.Unstructured().GetName()] = policy
+	}
+
+	// Merge the TimeoutPolicies of the GatewayClass and the Gateway.
+	merged, err := policymanager.MergePoliciesOfDifferentHierarchy(
+		map[policymanager.PolicyCrdID]policymanager.Policy{"TimeoutPolicy.foo.com": policiesByName["timeout-gatewayclass"]},
+		map[policymanager.PolicyCrdID]policymanager.Policy{"TimeoutPolicy.foo.com": policiesByName["timeout-gateway"]},
+	)
+	if err != nil {
+		t.Fatalf("MergePoliciesOfDifferentHierarchy() returned err=%v; want no error", err)
+	}
+
+	testCases := []struct {
+		name   string
+		policy policymanager.Policy
+		want   string
+	}{
+		{
+			name:   "single inherited policy",
+			policy: policiesByName["timeout-gatewayclass"],
+			want:   "timeout 30s (override from GatewayClass)",
+		},
+		{
+			name:   "merged inherited policies",
+			policy: merged["TimeoutPolicy.foo.com"],
+			want:   "timeout 30s (override from GatewayClass); idle timeout 5m (default from Gateway)",
+		},
+		{
+			name:   "fields without phrases are summarized generically",
+			policy: policiesByName["retry"],
+			want:   `3 retries, on 5xx, reset, extra={"a":"b"} (default from Gateway)`,
+		},
+		{
+			name:   "direct policy",
+			policy: policiesByName["backend-tls"],
+			want:   "TLS to foo.example.com verified by ConfigMap/ca (from Service)",
+		},
+		{
+			name:   "policy without renderer",
+			policy: policiesByName["in-house"],
+			want:   "mode=strict (from Gateway)",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Summary(tc.policy); got != tc.want {
+				t.Errorf("Summary() = %q; want %q", got, tc.want)
+			}
+		})
+	}
+
+	Register("InHousePolicy.example.com", RendererFunc(func(effectiveSpec map[string]interface{}) []Phrase {
+		return []Phrase{{Field: "mode", Text: "mode " + effectiveSpec["mode"].(string)}}
+	}))
+	want := "mode strict (from Gateway)"
+	if got := Summary(policiesByName["in-house"]); got != want {
+		t.Errorf("Summary() with registered renderer = %q; want %q", got, want)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+	"github.com/gauravkghildiyal/gwctl/pkg/fetch"
 	"github.com/gauravkghildiyal/gwctl/pkg/policymanager"
+	"github.com/gauravkghildiyal/gwctl/pkg/renderers"
 	"github.com/gauravkghildiyal/gwctl/pkg/resources/httproutes"
-	"github.com/gauravkghildiyal/gwctl/pkg/resources/namespaces"
 	"github.com/gauravkghildiyal/gwctl/pkg/types"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -16,7 +18,6 @@ import (
 	"k8s.io/client-go/discovery"
 	"k8s.io/utils/strings/slices"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
-	"sigs.k8s.io/yaml"
 )
 
 func List(ctx context.Context, params *types.Params, resourceType, namespace string) ([]unstructured.Unstructured, error) {
@@ -98,39 +99,29 @@ func GetAttachedPolicies(ctx context.Context, params *types.Params, backend unst
 	return params.PolicyManager.PoliciesAttachedTo(objRef), nil
 }
 
-func GetEffectivePolicies(ctx context.Context, params *types.Params, backend unstructured.Unstructured) (map[string]map[policymanager.PolicyCrdID]policymanager.Policy, error) {
-	result := make(map[string]map[policymanager.PolicyCrdID]policymanager.Policy)
-
-	// Step 1: Aggregate all policies of the Backend and the Backend-namespace.
-	backendPolicies, err := GetAttachedPolicies(ctx, params, backend)
-	if err != nil {
-		return nil, err
-	}
-	backendNamespacePolicies, err := namespaces.GetAttachedPolicies(ctx, params, backend.GetNamespace())
+// GetDirectPolicies returns the direct policies attached to the Backend,
+// merged by their kind.
+func GetDirectPolicies(ctx context.Context, params *types.Params, backend unstructured.Unstructured) (map[policymanager.PolicyCrdID]policymanager.Policy, error) {
+	policies, err := GetAttachedPolicies(ctx, params, backend)
 	if err != nil {
 		return nil, err
 	}
+	return policymanager.MergePoliciesOfSimilarKind(policymanager.DirectPolicies(policies))
+}
 
-	// Step 2: Merge Backend and Backend-namespace policies by their kind.
-	backendPoliciesByKind, err := policymanager.MergePoliciesOfSimilarKind(backendPolicies)
-	if err != nil {
-		return nil, err
-	}
-	backendNamespacePoliciesByKind, err := policymanager.MergePoliciesOfSimilarKind(backendNamespacePolicies)
-	if err != nil {
-		return nil, err
-	}
+// GetEffectivePolicies returns the effective policies of the Backend,
+// partitioned by Gateway. allHTTPRoutes are the HTTPRoutes of all namespaces,
+// which are listed once by the caller instead of for every Backend.
+func GetEffectivePolicies(ctx context.Context, params *types.Params, backend unstructured.Unstructured, allHTTPRoutes []gatewayv1beta1.HTTPRoute) (map[string]map[policymanager.PolicyCrdID]policymanager.Policy, error) {
+	result := make(map[string]map[policymanager.PolicyCrdID]policymanager.Policy)
 
-	// Step 3: Find all HTTPRoutes which reference this Backend.
-	httpRoutes, err := httpRoutesForBackend(ctx, params, backend)
-	if err != nil {
-		return nil, err
-	}
+	// Step 1: Find all HTTPRoutes which reference this Backend.
+	httpRoutes := httpRoutesForBackend(params, backend, allHTTPRoutes)
 
-	// Step 4: Loop through all HTTPRoutes and get their effective policies. Merge
+	// Step 2: Loop through all HTTPRoutes and get their effective policies. Merge
 	// effective policies such that we get policies partitioned by Gateway.
 	for _, httpRoute := range httpRoutes {
-		httpRoutePoliciesByGateway, err := httproutes.GetEffectivePolicies(ctx, params, httpRoute.GetNamespace(), httpRoute.GetName())
+		httpRoutePoliciesByGateway, err := httproutes.GetEffectivePolicies(ctx, params, httpRoute)
 		if err != nil {
 			return nil, err
 		}
@@ -143,32 +134,26 @@ func GetEffectivePolicies(ctx context.Context, params *types.Params, backend uns
 		}
 	}
 
-	// Step 5: Loop through all Gateways and merge the Backend and
-	// Backend-namespace specific policies. Note that this needs to be done
-	// separately from Step 4 i.e. we can't have this loop within Step 4 itself.
-	// This is because we first want to merge all policies of the same-hierarchy
-	// together and then move to the next hierarchy of Backend and
-	// Backend-namespace.
-	for gatewayRef := range result {
-		// Merge all hierarchial policies.
-		result[gatewayRef], err = policymanager.MergePoliciesOfDifferentHierarchy(result[gatewayRef], backendNamespacePoliciesByKind)
-		if err != nil {
-			return nil, err
-		}
-
-		result[gatewayRef], err = policymanager.MergePoliciesOfDifferentHierarchy(result[gatewayRef], backendPoliciesByKind)
-		if err != nil {
-			return nil, err
-		}
+	// Step 3: Merge the Backend and Backend-namespace specific policies. Note
+	// that this needs to be done separately from Step 2 i.e. we can't have this
+	// within Step 2 itself. This is because we first want to merge all policies
+	// of the same-hierarchy together and then move to the next hierarchy of
+	// Backend and Backend-namespace.
+	backendRef := policymanager.ObjRef{
+		Group:     backend.GroupVersionKind().Group,
+		Kind:      backend.GroupVersionKind().Kind,
+		Name:      backend.GetName(),
+		Namespace: backend.GetNamespace(),
 	}
-
-	return result, nil
+	return httproutes.MergeBackendPolicies(ctx, params, result, backendRef)
 }
 
-func httpRoutesForBackend(ctx context.Context, params *types.Params, backend unstructured.Unstructured) ([]gatewayv1beta1.HTTPRoute, error) {
-	allHTTPRoutes, err := httproutes.List(ctx, params, "")
-	if err != nil {
-		return nil, err
+func httpRoutesForBackend(params *types.Params, backend unstructured.Unstructured, allHTTPRoutes []gatewayv1beta1.HTTPRoute) []gatewayv1beta1.HTTPRoute {
+	backendObjRef := policymanager.ObjRef{
+		Group:     backend.GroupVersionKind().Group,
+		Kind:      backend.GroupVersionKind().Kind,
+		Name:      backend.GetName(),
+		Namespace: backend.GetNamespace(),
 	}
 
 	var filteredHTTPRoutes []gatewayv1beta1.HTTPRoute
@@ -177,23 +162,13 @@ func httpRoutesForBackend(ctx context.Context, params *types.Params, backend uns
 
 		for _, rule := range httpRoute.Spec.Rules {
 			for _, backendRef := range rule.BackendRefs {
-				if *backendRef.Group != gatewayv1beta1.Group(backend.GroupVersionKind().Group) {
-					continue
-				}
-				if *backendRef.Kind != gatewayv1beta1.Kind(backend.GroupVersionKind().Kind) {
-					continue
-				}
-				if backendRef.Name != gatewayv1beta1.ObjectName(backend.GetName()) {
+				objRef := policymanager.BackendRefToObjRef(backendRef.BackendObjectReference, httpRoute.GetNamespace())
+				if objRef != backendObjRef {
 					continue
 				}
-				var ns string
-				if backendRef.Namespace != nil {
-					ns = string(*backendRef.Namespace)
-				}
-				if ns == "" {
-					ns = httpRoute.GetNamespace()
-				}
-				if ns != backend.GetNamespace() {
+				// A cross-namespace reference is only valid if a ReferenceGrant
+				// permits it.
+				if permitted, _ := params.PolicyManager.IsBackendRefPermitted(httpRoute, objRef); !permitted {
 					continue
 				}
 				found = true
@@ -209,58 +184,106 @@ func httpRoutesForBackend(ctx context.Context, params *types.Params, backend uns
 		}
 	}
 
-	return filteredHTTPRoutes, nil
+	return filteredHTTPRoutes
 }
 
 type describeView struct {
-	Group                    string                                                        `json:",omitempty"`
-	Kind                     string                                                        `json:",omitempty"`
-	Name                     string                                                        `json:",omitempty"`
-	Namespace                string                                                        `json:",omitempty"`
-	DirectlyAttachedPolicies []policymanager.ObjRef                                        `json:",omitempty"`
-	EffectivePolicies        map[string]map[policymanager.PolicyCrdID]policymanager.Policy `json:",omitempty"`
+	Group                    string                 `json:",omitempty"`
+	Kind                     string                 `json:",omitempty"`
+	Name                     string                 `json:",omitempty"`
+	Namespace                string                 `json:",omitempty"`
+	DirectlyAttachedPolicies []policymanager.ObjRef `json:",omitempty"`
+	// DirectPolicies are the direct policies attached to the Backend.
+	DirectPolicies map[policymanager.PolicyCrdID]policymanager.Policy `json:",omitempty"`
+	// EffectivePolicies are the inherited policies which apply to the
+	// Backend, partitioned by Gateway.
+	EffectivePolicies map[string]map[policymanager.PolicyCrdID]policymanager.Policy `json:",omitempty"`
+	// EffectivePolicySummaries are human readable summaries of the
+	// EffectivePolicies, partitioned by Gateway.
+	EffectivePolicySummaries map[string]map[policymanager.PolicyCrdID]string `json:",omitempty"`
 }
 
-func PrintDescribeView(ctx context.Context, params *types.Params, backendsList []unstructured.Unstructured) {
-	for i, backend := range backendsList {
-		directlyAttachedPolicies, err := GetAttachedPolicies(ctx, params, backend)
-		if err != nil {
-			panic(err)
-		}
-		effectivePolicies, err := GetEffectivePolicies(ctx, params, backend)
-		if err != nil {
-			panic(err)
-		}
+func PrintDescribeView(ctx context.Context, params *types.Params, backendsList []unstructured.Unstructured) error {
+	allHTTPRoutes, err := httproutes.List(ctx, params, "")
+	if err != nil {
+		return err
+	}
 
-		views := []describeView{
-			{
-				Group:     backend.GroupVersionKind().Group,
-				Kind:      backend.GroupVersionKind().Kind,
-				Name:      backend.GetName(),
-				Namespace: backend.GetNamespace(),
-			},
-		}
-		if policyRefs := policymanager.ToPolicyRefs(directlyAttachedPolicies); len(policyRefs) != 0 {
-			views = append(views, describeView{
-				DirectlyAttachedPolicies: policyRefs,
-			})
-		}
-		if len(effectivePolicies) != 0 {
-			views = append(views, describeView{
-				EffectivePolicies: effectivePolicies,
-			})
-		}
+	// The views of the Backends are built concurrently, since each needs
+	// requests of its own.
+	viewsOfBackends := make([][]describeView, len(backendsList))
+	err = fetch.ForEach(ctx, len(backendsList), func(ctx context.Context, i int) error {
+		var err error
+		viewsOfBackends[i], err = newDescribeViews(ctx, params, backendsList[i], allHTTPRoutes)
+		return err
+	})
+	if err != nil {
+		return err
+	}
 
+	for i, views := range viewsOfBackends {
 		for _, view := range views {
-			b, err := yaml.Marshal(view)
+			b, err := common.Marshal(params.Output, view)
 			if err != nil {
-				panic(err)
+				return err
 			}
-			fmt.Print(string(b))
+			fmt.Fprint(params.Out, string(b))
 		}
 
 		if i+1 != len(backendsList) {
-			fmt.Printf("\n\n")
+			fmt.Fprintf(params.Out, "\n\n")
+		}
+	}
+	return nil
+}
+
+func newDescribeViews(ctx context.Context, params *types.Params, backend unstructured.Unstructured, allHTTPRoutes []gatewayv1beta1.HTTPRoute) ([]describeView, error) {
+	directlyAttachedPolicies, err := GetAttachedPolicies(ctx, params, backend)
+	if err != nil {
+		return nil, err
+	}
+	directPolicies, err := GetDirectPolicies(ctx, params, backend)
+	if err != nil {
+		return nil, err
+	}
+	effectivePolicies, err := GetEffectivePolicies(ctx, params, backend, allHTTPRoutes)
+	if err != nil {
+		return nil, err
+	}
+
+	views := []describeView{
+		{
+			Group:     backend.GroupVersionKind().Group,
+			Kind:      backend.GroupVersionKind().Kind,
+			Name:      backend.GetName(),
+			Namespace: backend.GetNamespace(),
+		},
+	}
+	if policyRefs := policymanager.ToPolicyRefs(directlyAttachedPolicies); len(policyRefs) != 0 {
+		views = append(views, describeView{
+			DirectlyAttachedPolicies: policyRefs,
+		})
+	}
+	if len(directPolicies) != 0 {
+		views = append(views, describeView{
+			DirectPolicies: directPolicies,
+		})
+	}
+	if len(effectivePolicies) != 0 {
+		views = append(views, describeView{
+			EffectivePolicies: effectivePolicies,
+		})
+		summaries := make(map[string]map[policymanager.PolicyCrdID]string)
+		for gateway, policies := range effectivePolicies {
+			if len(policies) != 0 {
+				summaries[gateway] = renderers.Summaries(policies)
+			}
+		}
+		if len(summaries) != 0 {
+			views = append(views, describeView{
+				EffectivePolicySummaries: summaries,
+			})
 		}
 	}
+	return views, nil
 }
@@ -0,0 +1,83 @@
+package backends
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// FuzzPrintDescribeView's bits set a backendRef, its rule and the Gateway.
+func FuzzPrintDescribeView(f *testing.F) {
+	seeds := []common.FuzzSeed{
+		{Mask: 0xff, Value: "default"},
+		{Mask: 0x55, Value: "Service"},
+	}
+	common.FuzzObjects(f, seeds, func(t *testing.T, isSet func(bit int) bool, value string) {
+		backendRef := gatewayv1beta1.HTTPBackendRef{
+			BackendRef: gatewayv1beta1.BackendRef{
+				BackendObjectReference: gatewayv1beta1.BackendObjectReference{Name: "foo-svc"},
+			},
+		}
+		if isSet(0) {
+			backendRef.Group = common.PtrTo(gatewayv1beta1.Group(""))
+		}
+		if isSet(1) {
+			backendRef.Kind = common.PtrTo(gatewayv1beta1.Kind(value))
+		}
+		if isSet(2) {
+			backendRef.Namespace = common.PtrTo(gatewayv1beta1.Namespace(value))
+		}
+		if isSet(3) {
+			backendRef.Port = common.PtrTo(gatewayv1beta1.PortNumber(80))
+		}
+
+		httpRoute := &gatewayv1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-httproute",
+				Namespace: "default",
+			},
+		}
+		if isSet(4) {
+			httpRoute.Spec.ParentRefs = []gatewayv1beta1.ParentReference{{Name: "foo-gateway"}}
+		}
+		if isSet(5) {
+			httpRoute.Spec.Rules = []gatewayv1beta1.HTTPRouteRule{{BackendRefs: []gatewayv1beta1.HTTPBackendRef{backendRef}}}
+		}
+
+		objects := []runtime.Object{httpRoute}
+		if isSet(6) {
+			objects = append(objects, &gatewayv1beta1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo-gateway",
+					Namespace: "default",
+				},
+				Spec: gatewayv1beta1.GatewaySpec{
+					Listeners: []gatewayv1beta1.Listener{{Name: "http", Port: 80, Protocol: gatewayv1beta1.HTTPProtocolType}},
+				},
+			})
+		}
+
+		backend := unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Service",
+				"metadata": map[string]interface{}{
+					"name":      "foo-svc",
+					"namespace": "default",
+				},
+			},
+		}
+
+		params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
+		if err := PrintDescribeView(context.Background(), params, []unstructured.Unstructured{backend}); err != nil {
+			t.Fatalf("PrintDescribeView() failed: %v", err)
+		}
+	})
+}
@@ -5,12 +5,12 @@ import (
 	_ "embed"
 	"fmt"
 
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
 	"github.com/gauravkghildiyal/gwctl/pkg/policymanager"
 	"github.com/gauravkghildiyal/gwctl/pkg/types"
 
 	apimachinerytypes "k8s.io/apimachinery/pkg/types"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
-	"sigs.k8s.io/yaml"
 )
 
 func List(ctx context.Context, params *types.Params) ([]gatewayv1beta1.GatewayClass, error) {
@@ -51,6 +51,51 @@ func GetAllPolicies(ctx context.Context, params *types.Params, name string) ([]p
 	return GetAttachedPolicies(ctx, params, name)
 }
 
+// GetGateways returns all Gateways (across all namespaces) which belong to the
+// GatewayClass.
+func GetGateways(ctx context.Context, params *types.Params, name string) ([]gatewayv1beta1.Gateway, error) {
+	gwList := &gatewayv1beta1.GatewayList{}
+	if err := params.Client.List(ctx, gwList); err != nil {
+		return []gatewayv1beta1.Gateway{}, err
+	}
+
+	var result []gatewayv1beta1.Gateway
+	for _, gw := range gwList.Items {
+		if string(gw.Spec.GatewayClassName) == name {
+			result = append(result, gw)
+		}
+	}
+	return result, nil
+}
+
+// GetEnforcedOverrides returns the "spec.override" values of the inherited
+// policies attached to the GatewayClass, partitioned by the policy kind. These
+// are the values which the GatewayClass forces onto every resource below it in
+// the hierarchy.
+func GetEnforcedOverrides(ctx context.Context, params *types.Params, name string) (map[policymanager.PolicyCrdID]map[string]interface{}, error) {
+	policies, err := GetAttachedPolicies(ctx, params, name)
+	if err != nil {
+		return nil, err
+	}
+	policiesByKind, err := policymanager.MergePoliciesOfSimilarKind(policies)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[policymanager.PolicyCrdID]map[string]interface{})
+	for policyCrdID, policy := range policiesByKind {
+		if !policy.IsInherited() {
+			continue
+		}
+		override, ok := policy.Spec()["override"].(map[string]interface{})
+		if !ok || len(override) == 0 {
+			continue
+		}
+		result[policyCrdID] = override
+	}
+	return result, nil
+}
+
 type describeView struct {
 	// GatewayClass name
 	Name           string `json:",omitempty"`
@@ -58,13 +103,16 @@ type describeView struct {
 	// GatewayClass description
 	Description              string                 `json:",omitempty"`
 	DirectlyAttachedPolicies []policymanager.ObjRef `json:",omitempty"`
+	// Gateways of this GatewayClass, formatted as "<namespace>/<name>".
+	Gateways          []string                                             `json:",omitempty"`
+	EnforcedOverrides map[policymanager.PolicyCrdID]map[string]interface{} `json:",omitempty"`
 }
 
-func PrintDescribeView(ctx context.Context, params *types.Params, gwClasses []gatewayv1beta1.GatewayClass) {
+func PrintDescribeView(ctx context.Context, params *types.Params, gwClasses []gatewayv1beta1.GatewayClass) error {
 	for i, gwc := range gwClasses {
 		directlyAttachedPolicies, err := GetAttachedPolicies(ctx, params, gwc.Name)
 		if err != nil {
-			panic(err)
+			return err
 		}
 
 		policyRefs := policymanager.ToPolicyRefs(directlyAttachedPolicies)
@@ -75,19 +123,45 @@ func PrintDescribeView(ctx context.Context, params *types.Params, gwClasses []ga
 			},
 			{
 				ControllerName: string(gwc.Spec.ControllerName),
-				Description:    *gwc.Spec.Description,
 			},
 		}
+		if gwc.Spec.Description != nil {
+			views[1].Description = *gwc.Spec.Description
+		}
 		if len(policyRefs) != 0 {
 			views = append(views, describeView{
 				DirectlyAttachedPolicies: policyRefs,
 			})
 		}
 
+		gws, err := GetGateways(ctx, params, gwc.Name)
+		if err != nil {
+			return err
+		}
+		if len(gws) != 0 {
+			var gwIDs []string
+			for _, gw := range gws {
+				gwIDs = append(gwIDs, fmt.Sprintf("%v/%v", gw.GetNamespace(), gw.GetName()))
+			}
+			views = append(views, describeView{
+				Gateways: gwIDs,
+			})
+		}
+
+		enforcedOverrides, err := GetEnforcedOverrides(ctx, params, gwc.Name)
+		if err != nil {
+			return err
+		}
+		if len(enforcedOverrides) != 0 {
+			views = append(views, describeView{
+				EnforcedOverrides: enforcedOverrides,
+			})
+		}
+
 		for _, view := range views {
-			b, err := yaml.Marshal(view)
+			b, err := common.Marshal(params.Output, view)
 			if err != nil {
-				panic(err)
+				return err
 			}
 			fmt.Fprint(params.Out, string(b))
 		}
@@ -96,4 +170,5 @@ func PrintDescribeView(ctx context.Context, params *types.Params, gwClasses []ga
 			fmt.Fprintf(params.Out, "\n\n")
 		}
 	}
+	return nil
 }
@@ -59,6 +59,74 @@ func TestPrintDescribeView(t *testing.T) {
 				},
 			},
 		},
+
+		&apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "timeoutpolicies.bar.com",
+				Labels: map[string]string{
+					common.GatewayPolicyLabelKey: "inherited",
+				},
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Scope:    apiextensionsv1.ClusterScoped,
+				Group:    "bar.com",
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1"}},
+				Names: apiextensionsv1.CustomResourceDefinitionNames{
+					Plural: "timeoutpolicies",
+					Kind:   "TimeoutPolicy",
+				},
+			},
+		},
+		&unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "bar.com/v1",
+				"kind":       "TimeoutPolicy",
+				"metadata": map[string]interface{}{
+					"name": "timeout-policy-gatewayclass",
+				},
+				"spec": map[string]interface{}{
+					"override": map[string]interface{}{
+						"seconds": int64(30),
+					},
+					"default": map[string]interface{}{
+						"condition": "path=/abc",
+					},
+					"targetRef": map[string]interface{}{
+						"group": "gateway.networking.k8s.io",
+						"kind":  "GatewayClass",
+						"name":  "foo-gatewayclass",
+					},
+				},
+			},
+		},
+
+		&gatewayv1beta1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-gateway",
+				Namespace: "default",
+			},
+			Spec: gatewayv1beta1.GatewaySpec{
+				GatewayClassName: "foo-gatewayclass",
+			},
+		},
+		&gatewayv1beta1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "bar-gateway",
+				Namespace: "ns1",
+			},
+			Spec: gatewayv1beta1.GatewaySpec{
+				GatewayClassName: "foo-gatewayclass",
+			},
+		},
+		&gatewayv1beta1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "other-gateway",
+				Namespace: "default",
+			},
+			Spec: gatewayv1beta1.GatewaySpec{
+				GatewayClassName: "other-gatewayclass",
+			},
+		},
 	}
 
 	params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
@@ -66,7 +134,9 @@ func TestPrintDescribeView(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to List GatewayClasses: %v", err)
 	}
-	PrintDescribeView(context.Background(), params, gws)
+	if err := PrintDescribeView(context.Background(), params, gws); err != nil {
+		t.Fatalf("PrintDescribeView() failed: %v", err)
+	}
 
 	got := params.Out.(*bytes.Buffer).String()
 	want := `
@@ -77,8 +147,62 @@ DirectlyAttachedPolicies:
 - Group: foo.com
   Kind: HealthCheckPolicy
   Name: policy-name
+- Group: bar.com
+  Kind: TimeoutPolicy
+  Name: timeout-policy-gatewayclass
+Gateways:
+- default/foo-gateway
+- ns1/bar-gateway
+EnforcedOverrides:
+  TimeoutPolicy.bar.com:
+    seconds: 30
 `
 	if diff := cmp.Diff(common.YamlString(want), common.YamlString(got), common.YamlStringTransformer); diff != "" {
 		t.Errorf("Unexpected diff\ngot=\n%v\nwant=\n%v\ndiff (-want +got)=\n%v", got, want, diff)
 	}
 }
+
+// FuzzPrintDescribeView's bits set the GatewayClass spec and a Gateway.
+func FuzzPrintDescribeView(f *testing.F) {
+	seeds := []common.FuzzSeed{
+		{Mask: 0xff, Value: "example.net/gateway-controller"},
+	}
+	common.FuzzObjects(f, seeds, func(t *testing.T, isSet func(bit int) bool, value string) {
+		gwc := &gatewayv1beta1.GatewayClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "foo-gatewayclass",
+			},
+		}
+		if isSet(0) {
+			gwc.Spec.ControllerName = gatewayv1beta1.GatewayController(value)
+		}
+		if isSet(1) {
+			gwc.Spec.Description = common.PtrTo(value)
+		}
+		if isSet(2) {
+			gwc.Spec.ParametersRef = &gatewayv1beta1.ParametersReference{Name: value}
+		}
+
+		objects := []runtime.Object{gwc}
+		if isSet(3) {
+			objects = append(objects, &gatewayv1beta1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo-gateway",
+					Namespace: "default",
+				},
+				Spec: gatewayv1beta1.GatewaySpec{
+					GatewayClassName: "foo-gatewayclass",
+				},
+			})
+		}
+
+		params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
+		gwcs, err := List(context.Background(), params)
+		if err != nil {
+			t.Fatalf("Failed to List GatewayClasses: %v", err)
+		}
+		if err := PrintDescribeView(context.Background(), params, gwcs); err != nil {
+			t.Fatalf("PrintDescribeView() failed: %v", err)
+		}
+	})
+}
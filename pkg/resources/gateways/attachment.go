@@ -0,0 +1,290 @@
+package gateways
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/resources/namespaces"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+)
+
+// ListHTTPRoutesForListeners returns the HTTPRoutes which attach to each
+// listener of the Gateway, keyed by the listener name. An HTTPRoute attaches to
+// a listener only if:
+//   - One of its parentRefs references the Gateway (and the listener, if a
+//     sectionName or port is specified).
+//   - The listener allows routes of kind HTTPRoute.
+//   - The listener allows routes from the namespace of the HTTPRoute.
+//   - The hostnames of the HTTPRoute intersect with the hostname of the
+//     listener.
+func ListHTTPRoutesForListeners(ctx context.Context, params *types.Params, gw gatewayv1beta1.Gateway) (map[gatewayv1beta1.SectionName][]gatewayv1beta1.HTTPRoute, error) {
+	allHTTPRoutes := &gatewayv1beta1.HTTPRouteList{}
+	if err := params.Client.List(ctx, allHTTPRoutes); err != nil {
+		return nil, err
+	}
+
+	result := make(map[gatewayv1beta1.SectionName][]gatewayv1beta1.HTTPRoute)
+	for _, listener := range gw.Spec.Listeners {
+		for _, httpRoute := range allHTTPRoutes.Items {
+			attached, err := isHTTPRouteAttachedToListener(ctx, params, gw, listener, httpRoute)
+			if err != nil {
+				return nil, err
+			}
+			if attached {
+				result[listener.Name] = append(result[listener.Name], httpRoute)
+			}
+		}
+	}
+	return result, nil
+}
+
+// ParentRefAttachment describes whether a parentRef of an HTTPRoute actually
+// binds to the Gateway it references.
+type ParentRefAttachment struct {
+	ParentRef gatewayv1beta1.ParentReference
+	// GatewayNamespace and GatewayName identify the referenced Gateway after
+	// defaulting the namespace of the parentRef.
+	GatewayNamespace string
+	GatewayName      string
+	// Gateway is the referenced Gateway, or nil if it was not found.
+	Gateway *gatewayv1beta1.Gateway
+	// Listeners contains the names of the listeners to which the HTTPRoute
+	// attaches through this parentRef. It is empty if the parentRef is not
+	// attached.
+	Listeners []gatewayv1beta1.SectionName
+	// Reason and Message explain why the parentRef is not attached. They are
+	// empty if the parentRef is attached.
+	Reason  gatewayv1beta1.RouteConditionReason
+	Message string
+}
+
+// IsAttached returns true if the HTTPRoute attaches to at least one listener
+// through the parentRef.
+func (a ParentRefAttachment) IsAttached() bool {
+	return len(a.Listeners) != 0
+}
+
+// ResolveHTTPRouteParentRefs determines for each parentRef of the HTTPRoute
+// whether it binds to the referenced Gateway, and if not, why. The results are
+// in the same order as the parentRefs.
+func ResolveHTTPRouteParentRefs(ctx context.Context, params *types.Params, httpRoute gatewayv1beta1.HTTPRoute) ([]ParentRefAttachment, error) {
+	var result []ParentRefAttachment
+	for _, parentRef := range httpRoute.Spec.ParentRefs {
+		attachment := ParentRefAttachment{
+			ParentRef:        parentRef,
+			GatewayNamespace: httpRoute.GetNamespace(),
+			GatewayName:      string(parentRef.Name),
+		}
+		if parentRef.Namespace != nil && *parentRef.Namespace != "" {
+			attachment.GatewayNamespace = string(*parentRef.Namespace)
+		}
+
+		if (parentRef.Group != nil && *parentRef.Group != gatewayv1beta1.GroupName) || (parentRef.Kind != nil && *parentRef.Kind != "Gateway") {
+			attachment.Reason = gatewayv1beta1.RouteReasonUnsupportedValue
+			attachment.Message = "only parentRefs of kind Gateway are supported"
+			result = append(result, attachment)
+			continue
+		}
+
+		gw, err := Get(ctx, params, attachment.GatewayNamespace, attachment.GatewayName)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			attachment.Reason = gatewayv1beta1.RouteReasonNoMatchingParent
+			attachment.Message = fmt.Sprintf("Gateway %v/%v not found", attachment.GatewayNamespace, attachment.GatewayName)
+			result = append(result, attachment)
+			continue
+		}
+		attachment.Gateway = &gw
+
+		// Default reason, applicable when no listener is selected by the
+		// sectionName and port of the parentRef. It gets replaced by a more
+		// specific reason when a listener is selected but rejects the HTTPRoute.
+		attachment.Reason = gatewayv1beta1.RouteReasonNoMatchingParent
+		attachment.Message = "no listener matches the sectionName and port of the parentRef"
+		for _, listener := range gw.Spec.Listeners {
+			if !ParentRefMatchesListener(parentRef, httpRoute.GetNamespace(), gw, listener) {
+				continue
+			}
+			reason, message, err := listenerRejectionReason(ctx, params, gw, listener, httpRoute)
+			if err != nil {
+				return nil, err
+			}
+			if reason == "" {
+				attachment.Listeners = append(attachment.Listeners, listener.Name)
+				continue
+			}
+			// Prefer reporting a hostname mismatch over other reasons since it
+			// means the listener was otherwise willing to accept the HTTPRoute.
+			if attachment.Reason != gatewayv1beta1.RouteReasonNoMatchingListenerHostname {
+				attachment.Reason, attachment.Message = reason, message
+			}
+		}
+		if attachment.IsAttached() {
+			attachment.Reason, attachment.Message = "", ""
+		}
+
+		result = append(result, attachment)
+	}
+	return result, nil
+}
+
+func isHTTPRouteAttachedToListener(ctx context.Context, params *types.Params, gw gatewayv1beta1.Gateway, listener gatewayv1beta1.Listener, httpRoute gatewayv1beta1.HTTPRoute) (bool, error) {
+	referenced := false
+	for _, parentRef := range httpRoute.Spec.ParentRefs {
+		if ParentRefMatchesListener(parentRef, httpRoute.GetNamespace(), gw, listener) {
+			referenced = true
+			break
+		}
+	}
+	if !referenced {
+		return false, nil
+	}
+
+	reason, _, err := listenerRejectionReason(ctx, params, gw, listener, httpRoute)
+	if err != nil {
+		return false, err
+	}
+	return reason == "", nil
+}
+
+// listenerRejectionReason returns the reason (and a message) for why the
+// listener does not accept the HTTPRoute. An empty reason is returned if the
+// listener accepts the HTTPRoute.
+func listenerRejectionReason(ctx context.Context, params *types.Params, gw gatewayv1beta1.Gateway, listener gatewayv1beta1.Listener, httpRoute gatewayv1beta1.HTTPRoute) (gatewayv1beta1.RouteConditionReason, string, error) {
+	if !ListenerAllowsHTTPRoutes(listener) {
+		return gatewayv1beta1.RouteReasonNotAllowedByListeners, fmt.Sprintf("listener %q does not allow routes of kind HTTPRoute", listener.Name), nil
+	}
+	allowed, err := ListenerAllowsNamespace(ctx, params, gw, listener, httpRoute.GetNamespace())
+	if err != nil {
+		return "", "", err
+	}
+	if !allowed {
+		return gatewayv1beta1.RouteReasonNotAllowedByListeners, fmt.Sprintf("listener %q does not allow routes from namespace %q", listener.Name, httpRoute.GetNamespace()), nil
+	}
+	if !HostnamesIntersect(listener.Hostname, httpRoute.Spec.Hostnames) {
+		return gatewayv1beta1.RouteReasonNoMatchingListenerHostname, fmt.Sprintf("hostnames do not intersect with hostname of listener %q", listener.Name), nil
+	}
+	return "", "", nil
+}
+
+// ParentRefReferencesGateway returns true if the parentRef (belonging to a
+// route in routeNamespace) references the Gateway.
+func ParentRefReferencesGateway(parentRef gatewayv1beta1.ParentReference, routeNamespace string, gw gatewayv1beta1.Gateway) bool {
+	if parentRef.Group != nil && *parentRef.Group != gatewayv1beta1.GroupName {
+		return false
+	}
+	if parentRef.Kind != nil && *parentRef.Kind != "Gateway" {
+		return false
+	}
+	ns := routeNamespace
+	if parentRef.Namespace != nil && *parentRef.Namespace != "" {
+		ns = string(*parentRef.Namespace)
+	}
+	return ns == gw.GetNamespace() && string(parentRef.Name) == gw.GetName()
+}
+
+// ParentRefMatchesListener returns true if the parentRef references the
+// Gateway and selects the listener through its sectionName and port (if
+// specified).
+func ParentRefMatchesListener(parentRef gatewayv1beta1.ParentReference, routeNamespace string, gw gatewayv1beta1.Gateway, listener gatewayv1beta1.Listener) bool {
+	if !ParentRefReferencesGateway(parentRef, routeNamespace, gw) {
+		return false
+	}
+	if parentRef.SectionName != nil && *parentRef.SectionName != listener.Name {
+		return false
+	}
+	if parentRef.Port != nil && *parentRef.Port != listener.Port {
+		return false
+	}
+	return true
+}
+
+// ListenerAllowsHTTPRoutes returns true if routes of kind HTTPRoute are allowed
+// to attach to the listener. When the listener does not specify the allowed
+// kinds, it is inferred from the protocol of the listener.
+func ListenerAllowsHTTPRoutes(listener gatewayv1beta1.Listener) bool {
+	if listener.AllowedRoutes == nil || len(listener.AllowedRoutes.Kinds) == 0 {
+		return listener.Protocol == gatewayv1beta1.HTTPProtocolType || listener.Protocol == gatewayv1beta1.HTTPSProtocolType
+	}
+	for _, routeGroupKind := range listener.AllowedRoutes.Kinds {
+		if routeGroupKind.Group != nil && *routeGroupKind.Group != gatewayv1beta1.GroupName {
+			continue
+		}
+		if routeGroupKind.Kind == "HTTPRoute" {
+			return true
+		}
+	}
+	return false
+}
+
+// ListenerAllowsNamespace returns true if routes from routeNamespace are allowed
+// to attach to the listener.
+func ListenerAllowsNamespace(ctx context.Context, params *types.Params, gw gatewayv1beta1.Gateway, listener gatewayv1beta1.Listener, routeNamespace string) (bool, error) {
+	from := gatewayv1beta1.NamespacesFromSame
+	var selector *metav1.LabelSelector
+	if listener.AllowedRoutes != nil && listener.AllowedRoutes.Namespaces != nil {
+		if listener.AllowedRoutes.Namespaces.From != nil {
+			from = *listener.AllowedRoutes.Namespaces.From
+		}
+		selector = listener.AllowedRoutes.Namespaces.Selector
+	}
+
+	switch from {
+	case gatewayv1beta1.NamespacesFromAll:
+		return true, nil
+	case gatewayv1beta1.NamespacesFromSelector:
+		if selector == nil {
+			return false, nil
+		}
+		labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			return false, err
+		}
+		ns, err := namespaces.Get(ctx, params, routeNamespace)
+		if err != nil {
+			return false, err
+		}
+		return labelSelector.Matches(labels.Set(ns.GetLabels())), nil
+	default:
+		return routeNamespace == gw.GetNamespace(), nil
+	}
+}
+
+// HostnamesIntersect returns true if any of the route hostnames intersects with
+// the listener hostname. A listener without a hostname, or a route without any
+// hostnames, matches all hostnames.
+func HostnamesIntersect(listenerHostname *gatewayv1beta1.Hostname, routeHostnames []gatewayv1beta1.Hostname) bool {
+	if listenerHostname == nil || *listenerHostname == "" || len(routeHostnames) == 0 {
+		return true
+	}
+	for _, routeHostname := range routeHostnames {
+		if hostnameMatches(string(*listenerHostname), string(routeHostname)) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostnameMatches returns true if the two hostnames intersect. Either of the
+// hostnames can be a wildcard hostname (like "*.example.com"), in which case it
+// matches all hostnames with the same suffix.
+func hostnameMatches(a, b string) bool {
+	if a == b {
+		return true
+	}
+	if strings.HasPrefix(a, "*.") && strings.HasSuffix(b, a[1:]) {
+		return true
+	}
+	if strings.HasPrefix(b, "*.") && strings.HasSuffix(a, b[1:]) {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,293 @@
+package gateways
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+func TestListHTTPRoutesForListeners(t *testing.T) {
+	gw := &gatewayv1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo-gateway",
+			Namespace: "default",
+		},
+		Spec: gatewayv1beta1.GatewaySpec{
+			GatewayClassName: "foo-gatewayclass",
+			Listeners: []gatewayv1beta1.Listener{
+				{
+					Name:     "same-namespace",
+					Port:     80,
+					Protocol: gatewayv1beta1.HTTPProtocolType,
+				},
+				{
+					Name:     "selector",
+					Port:     8080,
+					Protocol: gatewayv1beta1.HTTPProtocolType,
+					AllowedRoutes: &gatewayv1beta1.AllowedRoutes{
+						Namespaces: &gatewayv1beta1.RouteNamespaces{
+							From: common.PtrTo(gatewayv1beta1.NamespacesFromSelector),
+							Selector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{"expose": "true"},
+							},
+						},
+					},
+				},
+				{
+					Name:     "wildcard-hostname",
+					Port:     443,
+					Protocol: gatewayv1beta1.HTTPSProtocolType,
+					Hostname: common.PtrTo(gatewayv1beta1.Hostname("*.example.com")),
+					AllowedRoutes: &gatewayv1beta1.AllowedRoutes{
+						Namespaces: &gatewayv1beta1.RouteNamespaces{
+							From: common.PtrTo(gatewayv1beta1.NamespacesFromAll),
+						},
+					},
+				},
+				{
+					Name:     "tcp-only",
+					Port:     9000,
+					Protocol: gatewayv1beta1.HTTPProtocolType,
+					AllowedRoutes: &gatewayv1beta1.AllowedRoutes{
+						Kinds: []gatewayv1beta1.RouteGroupKind{{Kind: "TCPRoute"}},
+					},
+				},
+			},
+		},
+	}
+
+	httpRoute := func(namespace, name string, parentRef gatewayv1beta1.ParentReference, hostnames ...gatewayv1beta1.Hostname) *gatewayv1beta1.HTTPRoute {
+		return &gatewayv1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: gatewayv1beta1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
+					ParentRefs: []gatewayv1beta1.ParentReference{parentRef},
+				},
+				Hostnames: hostnames,
+			},
+		}
+	}
+	gwRef := gatewayv1beta1.ParentReference{
+		Name:      "foo-gateway",
+		Namespace: common.PtrTo(gatewayv1beta1.Namespace("default")),
+	}
+	gwRefWithSection := gwRef
+	gwRefWithSection.SectionName = common.PtrTo(gatewayv1beta1.SectionName("wildcard-hostname"))
+	gwRefWithPort := gwRef
+	gwRefWithPort.Port = common.PtrTo(gatewayv1beta1.PortNumber(8080))
+	gwRefWrongKind := gwRef
+	gwRefWrongKind.Kind = common.PtrTo(gatewayv1beta1.Kind("Service"))
+
+	objects := []runtime.Object{
+		gw,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "exposed", Labels: map[string]string{"expose": "true"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "hidden"}},
+
+		// Same namespace, no hostnames.
+		httpRoute("default", "route-1", gatewayv1beta1.ParentReference{Name: "foo-gateway"}),
+		// Namespace selected by the label selector.
+		httpRoute("exposed", "route-2", gwRef),
+		// Namespace allowed only on the listener with "All", and hostname
+		// intersects with wildcard.
+		httpRoute("hidden", "route-3", gwRef, "foo.example.com"),
+		// Hostname does not intersect with wildcard.
+		httpRoute("hidden", "route-4", gwRef, "example.com"),
+		// Route wildcard hostname intersects with listener wildcard hostname.
+		httpRoute("hidden", "route-5", gwRef, "*.foo.example.com"),
+		// sectionName restricts to a single listener.
+		httpRoute("default", "route-6", gwRefWithSection),
+		// port restricts to a single listener.
+		httpRoute("exposed", "route-7", gwRefWithPort),
+		// parentRef of a different kind.
+		httpRoute("default", "route-8", gwRefWrongKind),
+		// parentRef for a Gateway in a different namespace.
+		httpRoute("exposed", "route-9", gatewayv1beta1.ParentReference{Name: "foo-gateway"}),
+	}
+
+	params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
+	result, err := ListHTTPRoutesForListeners(context.Background(), params, *gw)
+	if err != nil {
+		t.Fatalf("ListHTTPRoutesForListeners returned err=%v; want no error", err)
+	}
+
+	got := make(map[gatewayv1beta1.SectionName][]string)
+	for listenerName, httpRoutes := range result {
+		for _, httpRoute := range httpRoutes {
+			got[listenerName] = append(got[listenerName], httpRoute.GetNamespace()+"/"+httpRoute.GetName())
+		}
+	}
+	want := map[gatewayv1beta1.SectionName][]string{
+		"same-namespace":    {"default/route-1"},
+		"selector":          {"exposed/route-2", "exposed/route-7"},
+		"wildcard-hostname": {"default/route-1", "default/route-6", "exposed/route-2", "hidden/route-3", "hidden/route-5"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListHTTPRoutesForListeners returned unexpected diff (-want, +got): \n%v", diff)
+	}
+}
+
+func TestHostnamesIntersect(t *testing.T) {
+	testCases := []struct {
+		listenerHostname *gatewayv1beta1.Hostname
+		routeHostnames   []gatewayv1beta1.Hostname
+		want             bool
+	}{
+		{listenerHostname: nil, routeHostnames: []gatewayv1beta1.Hostname{"foo.com"}, want: true},
+		{listenerHostname: common.PtrTo(gatewayv1beta1.Hostname("foo.com")), routeHostnames: nil, want: true},
+		{listenerHostname: common.PtrTo(gatewayv1beta1.Hostname("foo.com")), routeHostnames: []gatewayv1beta1.Hostname{"foo.com"}, want: true},
+		{listenerHostname: common.PtrTo(gatewayv1beta1.Hostname("foo.com")), routeHostnames: []gatewayv1beta1.Hostname{"bar.com"}, want: false},
+		{listenerHostname: common.PtrTo(gatewayv1beta1.Hostname("*.foo.com")), routeHostnames: []gatewayv1beta1.Hostname{"a.foo.com"}, want: true},
+		{listenerHostname: common.PtrTo(gatewayv1beta1.Hostname("*.foo.com")), routeHostnames: []gatewayv1beta1.Hostname{"a.b.foo.com"}, want: true},
+		{listenerHostname: common.PtrTo(gatewayv1beta1.Hostname("*.foo.com")), routeHostnames: []gatewayv1beta1.Hostname{"foo.com"}, want: false},
+		{listenerHostname: common.PtrTo(gatewayv1beta1.Hostname("a.foo.com")), routeHostnames: []gatewayv1beta1.Hostname{"*.foo.com"}, want: true},
+		{listenerHostname: common.PtrTo(gatewayv1beta1.Hostname("*.foo.com")), routeHostnames: []gatewayv1beta1.Hostname{"*.a.foo.com"}, want: true},
+		{listenerHostname: common.PtrTo(gatewayv1beta1.Hostname("*.foo.com")), routeHostnames: []gatewayv1beta1.Hostname{"bar.com", "b.foo.com"}, want: true},
+	}
+
+	for _, tc := range testCases {
+		if got := HostnamesIntersect(tc.listenerHostname, tc.routeHostnames); got != tc.want {
+			listenerHostname := "<nil>"
+			if tc.listenerHostname != nil {
+				listenerHostname = string(*tc.listenerHostname)
+			}
+			t.Errorf("HostnamesIntersect(%v, %v) = %v; want %v", listenerHostname, tc.routeHostnames, got, tc.want)
+		}
+	}
+}
+
+func TestResolveHTTPRouteParentRefs(t *testing.T) {
+	objects := []runtime.Object{
+		&gatewayv1beta1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-gateway",
+				Namespace: "default",
+			},
+			Spec: gatewayv1beta1.GatewaySpec{
+				GatewayClassName: "foo-gatewayclass",
+				Listeners: []gatewayv1beta1.Listener{
+					{
+						Name:     "http",
+						Port:     80,
+						Protocol: gatewayv1beta1.HTTPProtocolType,
+						Hostname: common.PtrTo(gatewayv1beta1.Hostname("*.example.com")),
+					},
+					{
+						Name:     "tcp",
+						Port:     9000,
+						Protocol: gatewayv1beta1.TCPProtocolType,
+					},
+				},
+			},
+		},
+	}
+	params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
+
+	testCases := []struct {
+		name           string
+		namespace      string
+		parentRef      gatewayv1beta1.ParentReference
+		hostnames      []gatewayv1beta1.Hostname
+		wantListeners  []gatewayv1beta1.SectionName
+		wantReason     gatewayv1beta1.RouteConditionReason
+		wantGatewayRef string
+	}{
+		{
+			name:           "attached",
+			namespace:      "default",
+			parentRef:      gatewayv1beta1.ParentReference{Name: "foo-gateway"},
+			hostnames:      []gatewayv1beta1.Hostname{"foo.example.com"},
+			wantListeners:  []gatewayv1beta1.SectionName{"http"},
+			wantGatewayRef: "default/foo-gateway",
+		},
+		{
+			name:           "unsupported parent kind",
+			namespace:      "default",
+			parentRef:      gatewayv1beta1.ParentReference{Name: "foo-gateway", Kind: common.PtrTo(gatewayv1beta1.Kind("Service"))},
+			wantReason:     gatewayv1beta1.RouteReasonUnsupportedValue,
+			wantGatewayRef: "default/foo-gateway",
+		},
+		{
+			name:           "gateway not found",
+			namespace:      "ns1",
+			parentRef:      gatewayv1beta1.ParentReference{Name: "foo-gateway"},
+			wantReason:     gatewayv1beta1.RouteReasonNoMatchingParent,
+			wantGatewayRef: "ns1/foo-gateway",
+		},
+		{
+			name:           "namespace not allowed",
+			namespace:      "ns1",
+			parentRef:      gatewayv1beta1.ParentReference{Name: "foo-gateway", Namespace: common.PtrTo(gatewayv1beta1.Namespace("default"))},
+			wantReason:     gatewayv1beta1.RouteReasonNotAllowedByListeners,
+			wantGatewayRef: "default/foo-gateway",
+		},
+		{
+			name:           "hostname does not intersect",
+			namespace:      "default",
+			parentRef:      gatewayv1beta1.ParentReference{Name: "foo-gateway"},
+			hostnames:      []gatewayv1beta1.Hostname{"foo.com"},
+			wantReason:     gatewayv1beta1.RouteReasonNoMatchingListenerHostname,
+			wantGatewayRef: "default/foo-gateway",
+		},
+		{
+			name:           "sectionName selects listener which does not allow HTTPRoute",
+			namespace:      "default",
+			parentRef:      gatewayv1beta1.ParentReference{Name: "foo-gateway", SectionName: common.PtrTo(gatewayv1beta1.SectionName("tcp"))},
+			wantReason:     gatewayv1beta1.RouteReasonNotAllowedByListeners,
+			wantGatewayRef: "default/foo-gateway",
+		},
+		{
+			name:           "sectionName does not match any listener",
+			namespace:      "default",
+			parentRef:      gatewayv1beta1.ParentReference{Name: "foo-gateway", SectionName: common.PtrTo(gatewayv1beta1.SectionName("https"))},
+			wantReason:     gatewayv1beta1.RouteReasonNoMatchingParent,
+			wantGatewayRef: "default/foo-gateway",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			httpRoute := gatewayv1beta1.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo-httproute",
+					Namespace: tc.namespace,
+				},
+				Spec: gatewayv1beta1.HTTPRouteSpec{
+					CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
+						ParentRefs: []gatewayv1beta1.ParentReference{tc.parentRef},
+					},
+					Hostnames: tc.hostnames,
+				},
+			}
+			attachments, err := ResolveHTTPRouteParentRefs(context.Background(), params, httpRoute)
+			if err != nil {
+				t.Fatalf("ResolveHTTPRouteParentRefs returned err=%v; want no error", err)
+			}
+			if len(attachments) != 1 {
+				t.Fatalf("ResolveHTTPRouteParentRefs returned %v attachments; want 1", len(attachments))
+			}
+			got := attachments[0]
+
+			if diff := cmp.Diff(tc.wantListeners, got.Listeners); diff != "" {
+				t.Errorf("Unexpected Listeners (-want, +got): \n%v", diff)
+			}
+			if got.Reason != tc.wantReason {
+				t.Errorf("Reason = %q; want %q (Message = %q)", got.Reason, tc.wantReason, got.Message)
+			}
+			if gotGatewayRef := got.GatewayNamespace + "/" + got.GatewayName; gotGatewayRef != tc.wantGatewayRef {
+				t.Errorf("Gateway = %q; want %q", gotGatewayRef, tc.wantGatewayRef)
+			}
+		})
+	}
+}
@@ -5,12 +5,15 @@ import (
 	_ "embed"
 	"fmt"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apimachinerytypes "k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
-	"sigs.k8s.io/yaml"
 
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+	"github.com/gauravkghildiyal/gwctl/pkg/fetch"
 	"github.com/gauravkghildiyal/gwctl/pkg/policymanager"
+	"github.com/gauravkghildiyal/gwctl/pkg/renderers"
 	"github.com/gauravkghildiyal/gwctl/pkg/resources/gatewayclasses"
 	"github.com/gauravkghildiyal/gwctl/pkg/resources/namespaces"
 	"github.com/gauravkghildiyal/gwctl/pkg/types"
@@ -52,30 +55,25 @@ func GetAttachedPolicies(ctx context.Context, params *types.Params, namespace, n
 }
 
 // GetGatewayClassPolicies will get the policies attached to the GatewayClass of the given Gateway.
-func GetGatewayClassPolicies(ctx context.Context, params *types.Params, namespace, name string) ([]policymanager.Policy, error) {
-	gw, err := Get(ctx, params, namespace, name)
-	if err != nil {
-		return []policymanager.Policy{}, err
-	}
-
+func GetGatewayClassPolicies(ctx context.Context, params *types.Params, gw gatewayv1beta1.Gateway) ([]policymanager.Policy, error) {
 	return gatewayclasses.GetAllPolicies(ctx, params, string(gw.Spec.GatewayClassName))
 }
 
-func GetAllPolicies(ctx context.Context, params *types.Params, namespace, name string) ([]policymanager.Policy, error) {
+func GetAllPolicies(ctx context.Context, params *types.Params, gw gatewayv1beta1.Gateway) ([]policymanager.Policy, error) {
 	var result []policymanager.Policy
-	policies, err := GetAttachedPolicies(ctx, params, namespace, name)
+	policies, err := GetAttachedPolicies(ctx, params, gw.Namespace, gw.Name)
 	if err != nil {
 		return result, err
 	}
 	result = append(result, policies...)
 
-	policies, err = namespaces.GetAttachedPolicies(ctx, params, namespace)
+	policies, err = namespaces.GetAttachedPolicies(ctx, params, gw.Namespace)
 	if err != nil {
 		return result, err
 	}
 	result = append(result, policies...)
 
-	policies, err = GetGatewayClassPolicies(ctx, params, namespace, name)
+	policies, err = GetGatewayClassPolicies(ctx, params, gw)
 	if err != nil {
 		return result, err
 	}
@@ -83,21 +81,27 @@ func GetAllPolicies(ctx context.Context, params *types.Params, namespace, name s
 	return result, nil
 }
 
-func GetEffectivePolicies(ctx context.Context, params *types.Params, namespace, name string) (map[policymanager.PolicyCrdID]policymanager.Policy, error) {
+func GetEffectivePolicies(ctx context.Context, params *types.Params, gw gatewayv1beta1.Gateway) (map[policymanager.PolicyCrdID]policymanager.Policy, error) {
 	// Fetch all policies.
-	gatewayClassPolicies, err := GetGatewayClassPolicies(ctx, params, namespace, name)
+	gatewayClassPolicies, err := GetGatewayClassPolicies(ctx, params, gw)
 	if err != nil {
 		return nil, err
 	}
-	gatewayNamespacePolicies, err := namespaces.GetAttachedPolicies(ctx, params, namespace)
+	gatewayNamespacePolicies, err := namespaces.GetAttachedPolicies(ctx, params, gw.Namespace)
 	if err != nil {
 		return nil, err
 	}
-	gatewayPolicies, err := GetAttachedPolicies(ctx, params, namespace, name)
+	gatewayPolicies, err := GetAttachedPolicies(ctx, params, gw.Namespace, gw.Name)
 	if err != nil {
 		return nil, err
 	}
 
+	// Only inherited policies apply below their target, while direct policies
+	// only apply to their exact target, see GetDirectPolicies.
+	gatewayClassPolicies = policymanager.InheritedPolicies(gatewayClassPolicies)
+	gatewayNamespacePolicies = policymanager.InheritedPolicies(gatewayNamespacePolicies)
+	gatewayPolicies = policymanager.InheritedPolicies(gatewayPolicies)
+
 	// Merge policies by their kind.
 	gatewayClassPoliciesByKind, err := policymanager.MergePoliciesOfSimilarKind(gatewayClassPolicies)
 	if err != nil {
@@ -126,51 +130,161 @@ func GetEffectivePolicies(ctx context.Context, params *types.Params, namespace,
 	return result, nil
 }
 
+// GetDirectPolicies returns the direct policies attached to the Gateway, merged
+// by their kind. Unlike inherited policies, these do not apply to any resource
+// below the Gateway.
+func GetDirectPolicies(ctx context.Context, params *types.Params, namespace, name string) (map[policymanager.PolicyCrdID]policymanager.Policy, error) {
+	policies, err := GetAttachedPolicies(ctx, params, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return policymanager.MergePoliciesOfSimilarKind(policymanager.DirectPolicies(policies))
+}
+
+// GetHTTPRouteEffectivePolicies returns the effective policies of the
+// HTTPRoute when it is attached to the given Gateway.
+func GetHTTPRouteEffectivePolicies(ctx context.Context, params *types.Params, gw gatewayv1beta1.Gateway, httpRoute gatewayv1beta1.HTTPRoute) (map[policymanager.PolicyCrdID]policymanager.Policy, error) {
+	gvks, _, err := params.Client.Scheme().ObjectKinds(&httpRoute)
+	if err != nil {
+		return nil, err
+	}
+	httpRouteRef := policymanager.ObjRef{
+		Group:     gvks[0].Group,
+		Kind:      gvks[0].Kind,
+		Name:      httpRoute.GetName(),
+		Namespace: httpRoute.GetNamespace(),
+	}
+
+	// Fetch all policies.
+	gatewayPoliciesByKind, err := GetEffectivePolicies(ctx, params, gw)
+	if err != nil {
+		return nil, err
+	}
+	httpRouteNamespacePolicies, err := namespaces.GetAttachedPolicies(ctx, params, httpRoute.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	httpRoutePolicies := params.PolicyManager.PoliciesAttachedTo(httpRouteRef)
+
+	// Only inherited policies apply below their target.
+	httpRouteNamespacePolicies = policymanager.InheritedPolicies(httpRouteNamespacePolicies)
+	httpRoutePolicies = policymanager.InheritedPolicies(httpRoutePolicies)
+
+	// Merge policies by their kind.
+	httpRouteNamespacePoliciesByKind, err := policymanager.MergePoliciesOfSimilarKind(httpRouteNamespacePolicies)
+	if err != nil {
+		return nil, err
+	}
+	httpRoutePoliciesByKind, err := policymanager.MergePoliciesOfSimilarKind(httpRoutePolicies)
+	if err != nil {
+		return nil, err
+	}
+
+	// Merge all hierarchial policies.
+	result, err := policymanager.MergePoliciesOfDifferentHierarchy(gatewayPoliciesByKind, httpRouteNamespacePoliciesByKind)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err = policymanager.MergePoliciesOfDifferentHierarchy(result, httpRoutePoliciesByKind)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 type describeView struct {
 	// Gateway name
 	Name string `json:",omitempty"`
 	// Gateway namespace
-	Namespace         string                                             `json:",omitempty"`
-	GatewayClass      string                                             `json:",omitempty"`
-	AllPolicies       []policymanager.ObjRef                             `json:",omitempty"`
+	Namespace    string                 `json:",omitempty"`
+	GatewayClass string                 `json:",omitempty"`
+	Listeners    []listenerView         `json:",omitempty"`
+	AllPolicies  []policymanager.ObjRef `json:",omitempty"`
+	// DirectPolicies are the direct policies attached to the Gateway.
+	DirectPolicies map[policymanager.PolicyCrdID]policymanager.Policy `json:",omitempty"`
+	// EffectivePolicies are the inherited policies which apply to the
+	// Gateway.
 	EffectivePolicies map[policymanager.PolicyCrdID]policymanager.Policy `json:",omitempty"`
+	// EffectivePolicySummaries are human readable summaries of the
+	// EffectivePolicies.
+	EffectivePolicySummaries map[policymanager.PolicyCrdID]string `json:",omitempty"`
+	// HTTPRouteEffectivePolicies contains the effective policies of each
+	// HTTPRoute attached to the Gateway, keyed by "<namespace>/<name>" of the
+	// HTTPRoute.
+	HTTPRouteEffectivePolicies map[string]map[policymanager.PolicyCrdID]policymanager.Policy `json:",omitempty"`
 }
 
-func PrintDescribeView(ctx context.Context, params *types.Params, gws []gatewayv1beta1.Gateway) {
-	for i, gw := range gws {
-		allPolicies, err := GetAllPolicies(ctx, params, gw.Namespace, gw.Name)
-		if err != nil {
-			panic(err)
-		}
-		effectivePolicies, err := GetEffectivePolicies(ctx, params, gw.Namespace, gw.Name)
-		if err != nil {
-			panic(err)
-		}
+type listenerView struct {
+	Name          string                    `json:",omitempty"`
+	Port          gatewayv1beta1.PortNumber `json:",omitempty"`
+	Protocol      string                    `json:",omitempty"`
+	Hostname      string                    `json:",omitempty"`
+	TLSMode       string                    `json:",omitempty"`
+	AllowedRoutes *allowedRoutesView        `json:",omitempty"`
+	// AttachedHTTPRoutes are formatted as "<namespace>/<name>".
+	AttachedHTTPRoutes []string `json:",omitempty"`
+}
 
-		views := []describeView{
-			{
-				Name:      gw.GetName(),
-				Namespace: gw.GetNamespace(),
-			},
-			{
-				GatewayClass: string(gw.Spec.GatewayClassName),
-			},
+type allowedRoutesView struct {
+	Namespaces string   `json:",omitempty"`
+	Kinds      []string `json:",omitempty"`
+}
+
+func newListenerView(listener gatewayv1beta1.Listener, attachedHTTPRoutes []gatewayv1beta1.HTTPRoute) listenerView {
+	view := listenerView{
+		Name:     string(listener.Name),
+		Port:     listener.Port,
+		Protocol: string(listener.Protocol),
+	}
+	if listener.Hostname != nil {
+		view.Hostname = string(*listener.Hostname)
+	}
+	if listener.TLS != nil {
+		view.TLSMode = string(gatewayv1beta1.TLSModeTerminate)
+		if listener.TLS.Mode != nil {
+			view.TLSMode = string(*listener.TLS.Mode)
 		}
-		if policyRefs := policymanager.ToPolicyRefs(allPolicies); len(policyRefs) != 0 {
-			views = append(views, describeView{
-				AllPolicies: policyRefs,
-			})
+	}
+
+	view.AllowedRoutes = &allowedRoutesView{Namespaces: string(gatewayv1beta1.NamespacesFromSame)}
+	if allowedRoutes := listener.AllowedRoutes; allowedRoutes != nil {
+		if allowedRoutes.Namespaces != nil && allowedRoutes.Namespaces.From != nil {
+			view.AllowedRoutes.Namespaces = string(*allowedRoutes.Namespaces.From)
+			if *allowedRoutes.Namespaces.From == gatewayv1beta1.NamespacesFromSelector && allowedRoutes.Namespaces.Selector != nil {
+				view.AllowedRoutes.Namespaces = fmt.Sprintf("%v(%v)", view.AllowedRoutes.Namespaces, metav1.FormatLabelSelector(allowedRoutes.Namespaces.Selector))
+			}
 		}
-		if len(effectivePolicies) != 0 {
-			views = append(views, describeView{
-				EffectivePolicies: effectivePolicies,
-			})
+		for _, routeGroupKind := range allowedRoutes.Kinds {
+			view.AllowedRoutes.Kinds = append(view.AllowedRoutes.Kinds, string(routeGroupKind.Kind))
 		}
+	}
+
+	for _, httpRoute := range attachedHTTPRoutes {
+		view.AttachedHTTPRoutes = append(view.AttachedHTTPRoutes, fmt.Sprintf("%v/%v", httpRoute.GetNamespace(), httpRoute.GetName()))
+	}
+	return view
+}
+
+func PrintDescribeView(ctx context.Context, params *types.Params, gws []gatewayv1beta1.Gateway) error {
+	// The views of the Gateways are built concurrently, since each needs
+	// requests of its own.
+	viewsOfGateways := make([][]describeView, len(gws))
+	err := fetch.ForEach(ctx, len(gws), func(ctx context.Context, i int) error {
+		var err error
+		viewsOfGateways[i], err = newDescribeViews(ctx, params, gws[i])
+		return err
+	})
+	if err != nil {
+		return err
+	}
 
+	for i, views := range viewsOfGateways {
 		for _, view := range views {
-			b, err := yaml.Marshal(view)
+			b, err := common.Marshal(params.Output, view)
 			if err != nil {
-				panic(err)
+				return err
 			}
 			fmt.Fprint(params.Out, string(b))
 		}
@@ -179,4 +293,85 @@ func PrintDescribeView(ctx context.Context, params *types.Params, gws []gatewayv
 			fmt.Fprintf(params.Out, "\n\n")
 		}
 	}
+	return nil
+}
+
+func newDescribeViews(ctx context.Context, params *types.Params, gw gatewayv1beta1.Gateway) ([]describeView, error) {
+	allPolicies, err := GetAllPolicies(ctx, params, gw)
+	if err != nil {
+		return nil, err
+	}
+	directPolicies, err := GetDirectPolicies(ctx, params, gw.Namespace, gw.Name)
+	if err != nil {
+		return nil, err
+	}
+	effectivePolicies, err := GetEffectivePolicies(ctx, params, gw)
+	if err != nil {
+		return nil, err
+	}
+	httpRoutesByListener, err := ListHTTPRoutesForListeners(ctx, params, gw)
+	if err != nil {
+		return nil, err
+	}
+
+	var listeners []listenerView
+	httpRouteEffectivePolicies := make(map[string]map[policymanager.PolicyCrdID]policymanager.Policy)
+	for _, listener := range gw.Spec.Listeners {
+		listeners = append(listeners, newListenerView(listener, httpRoutesByListener[listener.Name]))
+
+		for _, httpRoute := range httpRoutesByListener[listener.Name] {
+			httpRouteID := fmt.Sprintf("%v/%v", httpRoute.GetNamespace(), httpRoute.GetName())
+			if _, ok := httpRouteEffectivePolicies[httpRouteID]; ok {
+				continue
+			}
+			httpRouteEffectivePolicies[httpRouteID], err = GetHTTPRouteEffectivePolicies(ctx, params, gw, httpRoute)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	views := []describeView{
+		{
+			Name:      gw.GetName(),
+			Namespace: gw.GetNamespace(),
+		},
+		{
+			GatewayClass: string(gw.Spec.GatewayClassName),
+		},
+	}
+	if len(listeners) != 0 {
+		views = append(views, describeView{
+			Listeners: listeners,
+		})
+	}
+	if policyRefs := policymanager.ToPolicyRefs(allPolicies); len(policyRefs) != 0 {
+		views = append(views, describeView{
+			AllPolicies: policyRefs,
+		})
+	}
+	if len(directPolicies) != 0 {
+		views = append(views, describeView{
+			DirectPolicies: directPolicies,
+		})
+	}
+	if len(effectivePolicies) != 0 {
+		views = append(views, describeView{
+			EffectivePolicies: effectivePolicies,
+		}, describeView{
+			EffectivePolicySummaries: renderers.Summaries(effectivePolicies),
+		})
+	}
+	// Skip HTTPRoutes which do not have any effective policies.
+	for httpRouteID, policies := range httpRouteEffectivePolicies {
+		if len(policies) == 0 {
+			delete(httpRouteEffectivePolicies, httpRouteID)
+		}
+	}
+	if len(httpRouteEffectivePolicies) != 0 {
+		views = append(views, describeView{
+			HTTPRouteEffectivePolicies: httpRouteEffectivePolicies,
+		})
+	}
+	return views, nil
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/gauravkghildiyal/gwctl/pkg/types"
 	"github.com/google/go-cmp/cmp"
 
+	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -30,10 +31,75 @@ func TestPrintDescribeView(t *testing.T) {
 
 		&gatewayv1beta1.Gateway{
 			ObjectMeta: metav1.ObjectMeta{
-				Name: "foo-gateway",
+				Name:      "foo-gateway",
+				Namespace: "default",
 			},
 			Spec: gatewayv1beta1.GatewaySpec{
 				GatewayClassName: "foo-gatewayclass",
+				Listeners: []gatewayv1beta1.Listener{
+					{
+						Name:     "http",
+						Port:     80,
+						Protocol: gatewayv1beta1.HTTPProtocolType,
+					},
+					{
+						Name:     "https",
+						Port:     443,
+						Protocol: gatewayv1beta1.HTTPSProtocolType,
+						Hostname: common.PtrTo(gatewayv1beta1.Hostname("*.example.com")),
+						TLS:      &gatewayv1beta1.GatewayTLSConfig{},
+						AllowedRoutes: &gatewayv1beta1.AllowedRoutes{
+							Namespaces: &gatewayv1beta1.RouteNamespaces{
+								From: common.PtrTo(gatewayv1beta1.NamespacesFromAll),
+							},
+						},
+					},
+				},
+			},
+		},
+
+		&gatewayv1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-httproute",
+				Namespace: "default",
+			},
+			Spec: gatewayv1beta1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
+					ParentRefs: []gatewayv1beta1.ParentReference{{
+						Name: "foo-gateway",
+					}},
+				},
+				Hostnames: []gatewayv1beta1.Hostname{"foo.example.com"},
+			},
+		},
+		&gatewayv1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "bar-httproute",
+				Namespace: "ns2",
+			},
+			Spec: gatewayv1beta1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
+					ParentRefs: []gatewayv1beta1.ParentReference{{
+						Name:      "foo-gateway",
+						Namespace: common.PtrTo(gatewayv1beta1.Namespace("default")),
+					}},
+				},
+				Hostnames: []gatewayv1beta1.Hostname{"bar.com"},
+			},
+		},
+		&gatewayv1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "baz-httproute",
+				Namespace: "ns2",
+			},
+			Spec: gatewayv1beta1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
+					ParentRefs: []gatewayv1beta1.ParentReference{{
+						Name:      "foo-gateway",
+						Namespace: common.PtrTo(gatewayv1beta1.Namespace("default")),
+					}},
+				},
+				Hostnames: []gatewayv1beta1.Hostname{"baz.example.com"},
 			},
 		},
 
@@ -138,6 +204,25 @@ func TestPrintDescribeView(t *testing.T) {
 				},
 			},
 		},
+		&unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "bar.com/v1",
+				"kind":       "TimeoutPolicy",
+				"metadata": map[string]interface{}{
+					"name": "timeout-policy-gateway",
+				},
+				"spec": map[string]interface{}{
+					"condition": "path=/def",
+					"seconds":   int64(60),
+					"targetRef": map[string]interface{}{
+						"group":     "gateway.networking.k8s.io",
+						"kind":      "Gateway",
+						"name":      "foo-gateway",
+						"namespace": "default",
+					},
+				},
+			},
+		},
 	}
 
 	params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
@@ -145,22 +230,50 @@ func TestPrintDescribeView(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to List Gateways: %v", err)
 	}
-	PrintDescribeView(context.Background(), params, gws)
+	if err := PrintDescribeView(context.Background(), params, gws); err != nil {
+		t.Fatalf("PrintDescribeView() failed: %v", err)
+	}
 
 	got := params.Out.(*bytes.Buffer).String()
 	want := `
 Name: foo-gateway
+Namespace: default
 GatewayClass: foo-gatewayclass
+Listeners:
+- AllowedRoutes:
+    Namespaces: Same
+  AttachedHTTPRoutes:
+  - default/foo-httproute
+  Name: http
+  Port: 80
+  Protocol: HTTP
+- AllowedRoutes:
+    Namespaces: All
+  AttachedHTTPRoutes:
+  - default/foo-httproute
+  - ns2/baz-httproute
+  Hostname: '*.example.com'
+  Name: https
+  Port: 443
+  Protocol: HTTPS
+  TLSMode: Terminate
 AllPolicies:
 - Group: foo.com
   Kind: HealthCheckPolicy
   Name: health-check-gateway
+- Group: bar.com
+  Kind: TimeoutPolicy
+  Name: timeout-policy-gateway
 - Group: bar.com
   Kind: TimeoutPolicy
   Name: timeout-policy-namespace
 - Group: foo.com
   Kind: HealthCheckPolicy
   Name: health-check-gatewayclass
+DirectPolicies:
+  TimeoutPolicy.bar.com:
+    condition: path=/def
+    seconds: 60
 EffectivePolicies:
   HealthCheckPolicy.foo.com:
     key1: value-parent-1
@@ -168,11 +281,113 @@ EffectivePolicies:
     key3: value-parent-3
     key4: value-parent-4
     key5: value-parent-5
-  TimeoutPolicy.bar.com:
-    condition: path=/abc
-    seconds: 30
+EffectivePolicySummaries:
+  HealthCheckPolicy.foo.com: key1=value-parent-1, key3=value-parent-3, key5=value-parent-5
+    (override from GatewayClass); key2=value-child-2 (default from Gateway); key4=value-parent-4
+    (default from GatewayClass)
+HTTPRouteEffectivePolicies:
+  default/foo-httproute:
+    HealthCheckPolicy.foo.com:
+      key1: value-parent-1
+      key2: value-child-2
+      key3: value-parent-3
+      key4: value-parent-4
+      key5: value-parent-5
+  ns2/baz-httproute:
+    HealthCheckPolicy.foo.com:
+      key1: value-parent-1
+      key2: value-child-2
+      key3: value-parent-3
+      key4: value-parent-4
+      key5: value-parent-5
 `
 	if diff := cmp.Diff(common.YamlString(want), common.YamlString(got), common.YamlStringTransformer); diff != "" {
 		t.Errorf("Unexpected diff\ngot=\n%v\nwant=\n%v\ndiff (-want +got)=\n%v", got, want, diff)
 	}
 }
+
+// FuzzPrintDescribeView's bits set a listener, addresses, HTTPRoute and class.
+func FuzzPrintDescribeView(f *testing.F) {
+	seeds := []common.FuzzSeed{
+		{Mask: 0xff, Value: "*.example.com"},
+		{Mask: 0x55, Value: "foo.example.com"},
+		{Mask: 0xaa, Value: "10.0.0.1"},
+	}
+	common.FuzzObjects(f, seeds, func(t *testing.T, isSet func(bit int) bool, value string) {
+		listener := gatewayv1beta1.Listener{Name: "http", Port: 80, Protocol: gatewayv1beta1.HTTPProtocolType}
+		if isSet(0) {
+			listener.Hostname = common.PtrTo(gatewayv1beta1.Hostname(value))
+		}
+		if isSet(1) {
+			listener.TLS = &gatewayv1beta1.GatewayTLSConfig{}
+		}
+		if isSet(2) {
+			listener.AllowedRoutes = &gatewayv1beta1.AllowedRoutes{}
+		}
+		if isSet(3) {
+			listener.AllowedRoutes = &gatewayv1beta1.AllowedRoutes{
+				Namespaces: &gatewayv1beta1.RouteNamespaces{From: common.PtrTo(gatewayv1beta1.NamespacesFromSelector)},
+				Kinds:      []gatewayv1beta1.RouteGroupKind{{Kind: "HTTPRoute"}},
+			}
+		}
+
+		gw := &gatewayv1beta1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-gateway",
+				Namespace: "default",
+			},
+			Spec: gatewayv1beta1.GatewaySpec{
+				GatewayClassName: "foo-gatewayclass",
+				Listeners:        []gatewayv1beta1.Listener{listener},
+			},
+		}
+		if isSet(4) {
+			gw.Spec.Addresses = []gatewayv1beta1.GatewayAddress{{Value: value}}
+		}
+
+		httpRoute := &gatewayv1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-httproute",
+				Namespace: "default",
+			},
+			Spec: gatewayv1beta1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
+					ParentRefs: []gatewayv1beta1.ParentReference{{Name: "foo-gateway"}},
+				},
+			},
+		}
+		if isSet(5) {
+			httpRoute.Spec.ParentRefs[0].SectionName = common.PtrTo(gatewayv1beta1.SectionName("http"))
+			httpRoute.Spec.ParentRefs[0].Port = common.PtrTo(gatewayv1beta1.PortNumber(80))
+		}
+		if isSet(6) {
+			httpRoute.Spec.Hostnames = []gatewayv1beta1.Hostname{gatewayv1beta1.Hostname(value)}
+		}
+
+		objects := []runtime.Object{
+			&corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "default",
+				},
+			},
+			gw,
+			httpRoute,
+		}
+		if isSet(7) {
+			objects = append(objects, &gatewayv1beta1.GatewayClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "foo-gatewayclass",
+				},
+			})
+		}
+
+		params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
+		gws, err := List(context.Background(), params, "")
+		if err != nil {
+			t.Fatalf("Failed to List Gateways: %v", err)
+		}
+		if err := PrintDescribeView(context.Background(), params, gws); err != nil {
+			t.Fatalf("PrintDescribeView() failed: %v", err)
+		}
+	})
+}
@@ -4,16 +4,16 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
-	"os"
 	"strings"
-	"text/tabwriter"
 
 	apimachinerytypes "k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
-	"sigs.k8s.io/yaml"
 
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+	"github.com/gauravkghildiyal/gwctl/pkg/fetch"
 	"github.com/gauravkghildiyal/gwctl/pkg/policymanager"
+	"github.com/gauravkghildiyal/gwctl/pkg/renderers"
 	"github.com/gauravkghildiyal/gwctl/pkg/resources/gateways"
 	"github.com/gauravkghildiyal/gwctl/pkg/resources/namespaces"
 	"github.com/gauravkghildiyal/gwctl/pkg/types"
@@ -54,74 +54,132 @@ func GetAttachedPolicies(ctx context.Context, params *types.Params, namespace, n
 	return params.PolicyManager.PoliciesAttachedTo(objRef), nil
 }
 
-func GetEffectivePolicies(ctx context.Context, params *types.Params, namespace, name string) (map[string]map[policymanager.PolicyCrdID]policymanager.Policy, error) {
+func GetEffectivePolicies(ctx context.Context, params *types.Params, httpRoute gatewayv1beta1.HTTPRoute) (map[string]map[policymanager.PolicyCrdID]policymanager.Policy, error) {
 	result := make(map[string]map[policymanager.PolicyCrdID]policymanager.Policy)
 
-	// Step 1: Aggregate all policies of the HTTPRoute and the HTTPRoute-namespace.
-	httpRoutePolicies, err := GetAttachedPolicies(ctx, params, namespace, name)
+	// Step 1: Determine which of the parentRefs actually attach to a Gateway.
+	// Policies are only inherited from Gateways to which the HTTPRoute is
+	// attached.
+	attachments, err := gateways.ResolveHTTPRouteParentRefs(ctx, params, httpRoute)
 	if err != nil {
-		return nil, err
+		return result, err
+	}
+
+	// Step 2: Loop through all attached Gateways and merge policies for each
+	// Gateway. End result is we get policies partitioned by each Gateway.
+	for _, attachment := range attachments {
+		if !attachment.IsAttached() {
+			continue
+		}
+		gatewayID := fmt.Sprintf("%v/%v", attachment.GatewayNamespace, attachment.GatewayName)
+		if _, ok := result[gatewayID]; ok {
+			// Gateway was already processed through another parentRef.
+			continue
+		}
+
+		mergedPolicies, err := gateways.GetHTTPRouteEffectivePolicies(ctx, params, *attachment.Gateway, httpRoute)
+		if err != nil {
+			return result, err
+		}
+		result[gatewayID] = mergedPolicies
 	}
-	httpRouteNamespacePolicies, err := namespaces.GetAttachedPolicies(ctx, params, namespace)
+
+	return result, nil
+}
+
+// GetDirectPolicies returns the direct policies attached to the HTTPRoute,
+// merged by their kind. Unlike inherited policies, these do not apply to the
+// backends of the HTTPRoute.
+func GetDirectPolicies(ctx context.Context, params *types.Params, namespace, name string) (map[policymanager.PolicyCrdID]policymanager.Policy, error) {
+	policies, err := GetAttachedPolicies(ctx, params, namespace, name)
 	if err != nil {
 		return nil, err
 	}
+	return policymanager.MergePoliciesOfSimilarKind(policymanager.DirectPolicies(policies))
+}
+
+// MergeBackendPolicies merges the policies attached to the backend and to the
+// namespace of the backend into the given policies, which are partitioned by
+// Gateway. backendRef is expected to be normalized.
+func MergeBackendPolicies(ctx context.Context, params *types.Params, policiesByGateway map[string]map[policymanager.PolicyCrdID]policymanager.Policy, backendRef policymanager.ObjRef) (map[string]map[policymanager.PolicyCrdID]policymanager.Policy, error) {
+	result := make(map[string]map[policymanager.PolicyCrdID]policymanager.Policy)
 
-	// Step 2: Merge HTTPRoute and HTTPRoute-namespace policies by their kind.
-	httpRoutePoliciesByKind, err := policymanager.MergePoliciesOfSimilarKind(httpRoutePolicies)
+	// Step 1: Merge the inherited Backend and Backend-namespace policies by
+	// their kind. Direct policies only apply to their exact target.
+	backendPolicies := policymanager.InheritedPolicies(params.PolicyManager.PoliciesAttachedTo(backendRef))
+	backendPoliciesByKind, err := policymanager.MergePoliciesOfSimilarKind(backendPolicies)
 	if err != nil {
 		return nil, err
 	}
-	httpRouteNamespacePoliciesByKind, err := policymanager.MergePoliciesOfSimilarKind(httpRouteNamespacePolicies)
+	backendNamespacePolicies, err := namespaces.GetAttachedPolicies(ctx, params, backendRef.Namespace)
 	if err != nil {
 		return nil, err
 	}
-
-	// Step 3: Fetch the HTTPRoute to identify the Gateways it is attached to.
-	httpRoute, err := Get(ctx, params, namespace, name)
+	backendNamespacePoliciesByKind, err := policymanager.MergePoliciesOfSimilarKind(policymanager.InheritedPolicies(backendNamespacePolicies))
 	if err != nil {
-		return result, err
+		return nil, err
 	}
 
-	// Step 4: Loop through all Gateways and merge policies for each Gateway. End
-	// result is we get policies partitioned by each Gateway.
-	for _, gatewayRef := range httpRoute.Spec.ParentRefs {
-		ns := namespace
-		if gatewayRef.Namespace != nil {
-			ns = string(*gatewayRef.Namespace)
-		}
-		if ns == "" {
-			ns = "default"
-		}
-
-		gatewayPoliciesByKind, err := gateways.GetEffectivePolicies(ctx, params, string(ns), string(gatewayRef.Name))
-		if err != nil {
-			return result, err
-		}
-
-		// Merge all hierarchial policies.
-		mergedPolicies, err := policymanager.MergePoliciesOfDifferentHierarchy(gatewayPoliciesByKind, httpRouteNamespacePoliciesByKind)
+	// Step 2: Loop through all Gateways and merge the hierarchial policies.
+	for gatewayRef, policies := range policiesByGateway {
+		result[gatewayRef], err = policymanager.MergePoliciesOfDifferentHierarchy(policies, backendNamespacePoliciesByKind)
 		if err != nil {
 			return nil, err
 		}
 
-		mergedPolicies, err = policymanager.MergePoliciesOfDifferentHierarchy(mergedPolicies, httpRoutePoliciesByKind)
+		result[gatewayRef], err = policymanager.MergePoliciesOfDifferentHierarchy(result[gatewayRef], backendPoliciesByKind)
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		gatewayID := fmt.Sprintf("%v/%v", ns, gatewayRef.Name)
-		result[gatewayID] = mergedPolicies
+	return result, nil
+}
+
+// GetBackendEffectivePolicies returns the effective policies of each backend
+// referenced by the HTTPRoute. The result is keyed by "<kind>/<namespace>/<name>"
+// of the backend, and then partitioned by Gateway. Backends referenced from
+// another namespace without being permitted by a ReferenceGrant are skipped.
+func GetBackendEffectivePolicies(ctx context.Context, params *types.Params, httpRoute gatewayv1beta1.HTTPRoute) (map[string]map[string]map[policymanager.PolicyCrdID]policymanager.Policy, error) {
+	result := make(map[string]map[string]map[policymanager.PolicyCrdID]policymanager.Policy)
+
+	httpRoutePoliciesByGateway, err := GetEffectivePolicies(ctx, params, httpRoute)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range httpRoute.Spec.Rules {
+		for _, backendRef := range rule.BackendRefs {
+			objRef := policymanager.BackendRefToObjRef(backendRef.BackendObjectReference, httpRoute.GetNamespace())
+			backendID := fmt.Sprintf("%v/%v/%v", objRef.Kind, objRef.Namespace, objRef.Name)
+			if _, ok := result[backendID]; ok {
+				continue
+			}
+			if permitted, _ := params.PolicyManager.IsBackendRefPermitted(httpRoute, objRef); !permitted {
+				continue
+			}
+
+			result[backendID], err = MergeBackendPolicies(ctx, params, httpRoutePoliciesByGateway, objRef)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return result, nil
 }
 
-func Print(httpRoutes []gatewayv1beta1.HTTPRoute) {
-	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	row := []string{"NAME", "HOSTNAMES"}
-	tw.Write([]byte(strings.Join(row, "\t") + "\n"))
+func Print(params *types.Params, httpRoutes []gatewayv1beta1.HTTPRoute) {
+	header, rows := Table(httpRoutes)
+	if err := common.WriteTable(params.Out, header, rows, params.Config.TableColumns("httproutes")); err != nil {
+		panic(err)
+	}
+}
 
+// Table returns the header and rows printed by Print.
+func Table(httpRoutes []gatewayv1beta1.HTTPRoute) ([]string, [][]string) {
+	header := []string{"NAME", "HOSTNAMES"}
+	var rows [][]string
 	for _, httpRoute := range httpRoutes {
 		var hostNames []string
 		for _, hostName := range httpRoute.Spec.Hostnames {
@@ -132,57 +190,59 @@ func Print(httpRoutes []gatewayv1beta1.HTTPRoute) {
 			hostNamesOutput = fmt.Sprintf("%v + %v more", strings.Join(hostNames[:2], ","), cnt-2)
 		}
 
-		row := []string{httpRoute.Name, hostNamesOutput}
-		tw.Write([]byte(strings.Join(row, "\t") + "\n"))
+		rows = append(rows, []string{httpRoute.Name, hostNamesOutput})
 	}
-	tw.Flush()
+	return header, rows
 }
 
 type describeView struct {
-	Name                     string                                                        `json:",omitempty"`
-	Namespace                string                                                        `json:",omitempty"`
-	Hostnames                []gatewayv1beta1.Hostname                                     `json:",omitempty"`
-	ParentRefs               []gatewayv1beta1.ParentReference                              `json:",omitempty"`
-	DirectlyAttachedPolicies []policymanager.ObjRef                                        `json:",omitempty"`
-	EffectivePolicies        map[string]map[policymanager.PolicyCrdID]policymanager.Policy `json:",omitempty"`
+	Name                     string                           `json:",omitempty"`
+	Namespace                string                           `json:",omitempty"`
+	Hostnames                []gatewayv1beta1.Hostname        `json:",omitempty"`
+	ParentRefs               []gatewayv1beta1.ParentReference `json:",omitempty"`
+	UnattachedParents        []unattachedParentView           `json:",omitempty"`
+	Rules                    []ruleView                       `json:",omitempty"`
+	DirectlyAttachedPolicies []policymanager.ObjRef           `json:",omitempty"`
+	// DirectPolicies are the direct policies attached to the HTTPRoute.
+	DirectPolicies map[policymanager.PolicyCrdID]policymanager.Policy `json:",omitempty"`
+	// EffectivePolicies are the inherited policies which apply to the
+	// HTTPRoute, partitioned by Gateway.
+	EffectivePolicies map[string]map[policymanager.PolicyCrdID]policymanager.Policy `json:",omitempty"`
+	// EffectivePolicySummaries are human readable summaries of the
+	// EffectivePolicies, partitioned by Gateway.
+	EffectivePolicySummaries map[string]map[policymanager.PolicyCrdID]string `json:",omitempty"`
+	// BackendEffectivePolicies is keyed by "<kind>/<namespace>/<name>" of the
+	// backend and then partitioned by Gateway.
+	BackendEffectivePolicies map[string]map[string]map[policymanager.PolicyCrdID]policymanager.Policy `json:",omitempty"`
 }
 
-func PrintDescribeView(ctx context.Context, params *types.Params, httpRoutes []gatewayv1beta1.HTTPRoute) {
-	for i, httpRoute := range httpRoutes {
-		directlyAttachedPolicies, err := GetAttachedPolicies(ctx, params, httpRoute.Namespace, httpRoute.Name)
-		if err != nil {
-			panic(err)
-		}
-		effectivePolicies, err := GetEffectivePolicies(ctx, params, httpRoute.Namespace, httpRoute.Name)
-		if err != nil {
-			panic(err)
-		}
+type unattachedParentView struct {
+	// Gateway is formatted as "<namespace>/<name>".
+	Gateway     string                      `json:",omitempty"`
+	SectionName *gatewayv1beta1.SectionName `json:",omitempty"`
+	Port        *gatewayv1beta1.PortNumber  `json:",omitempty"`
+	Reason      string                      `json:",omitempty"`
+	Message     string                      `json:",omitempty"`
+}
 
-		views := []describeView{
-			{
-				Name:      httpRoute.GetName(),
-				Namespace: httpRoute.GetNamespace(),
-			},
-			{
-				Hostnames:  httpRoute.Spec.Hostnames,
-				ParentRefs: httpRoute.Spec.ParentRefs,
-			},
-		}
-		if policyRefs := policymanager.ToPolicyRefs(directlyAttachedPolicies); len(policyRefs) != 0 {
-			views = append(views, describeView{
-				DirectlyAttachedPolicies: policyRefs,
-			})
-		}
-		if len(effectivePolicies) != 0 {
-			views = append(views, describeView{
-				EffectivePolicies: effectivePolicies,
-			})
-		}
+func PrintDescribeView(ctx context.Context, params *types.Params, httpRoutes []gatewayv1beta1.HTTPRoute) error {
+	// The views of the HTTPRoutes are built concurrently, since each needs
+	// requests of its own.
+	viewsOfHTTPRoutes := make([][]describeView, len(httpRoutes))
+	err := fetch.ForEach(ctx, len(httpRoutes), func(ctx context.Context, i int) error {
+		var err error
+		viewsOfHTTPRoutes[i], err = newDescribeViews(ctx, params, httpRoutes[i])
+		return err
+	})
+	if err != nil {
+		return err
+	}
 
+	for i, views := range viewsOfHTTPRoutes {
 		for _, view := range views {
-			b, err := yaml.Marshal(view)
+			b, err := common.Marshal(params.Output, view)
 			if err != nil {
-				panic(err)
+				return err
 			}
 			fmt.Fprint(params.Out, string(b))
 		}
@@ -191,4 +251,98 @@ func PrintDescribeView(ctx context.Context, params *types.Params, httpRoutes []g
 			fmt.Fprintf(params.Out, "\n\n")
 		}
 	}
+	return nil
+}
+
+func newDescribeViews(ctx context.Context, params *types.Params, httpRoute gatewayv1beta1.HTTPRoute) ([]describeView, error) {
+	directlyAttachedPolicies, err := GetAttachedPolicies(ctx, params, httpRoute.Namespace, httpRoute.Name)
+	if err != nil {
+		return nil, err
+	}
+	directPolicies, err := GetDirectPolicies(ctx, params, httpRoute.Namespace, httpRoute.Name)
+	if err != nil {
+		return nil, err
+	}
+	effectivePolicies, err := GetEffectivePolicies(ctx, params, httpRoute)
+	if err != nil {
+		return nil, err
+	}
+	backendEffectivePolicies, err := GetBackendEffectivePolicies(ctx, params, httpRoute)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := newRuleViews(ctx, params, httpRoute)
+	if err != nil {
+		return nil, err
+	}
+	attachments, err := gateways.ResolveHTTPRouteParentRefs(ctx, params, httpRoute)
+	if err != nil {
+		return nil, err
+	}
+	var unattachedParents []unattachedParentView
+	for _, attachment := range attachments {
+		if attachment.IsAttached() {
+			continue
+		}
+		unattachedParents = append(unattachedParents, unattachedParentView{
+			Gateway:     fmt.Sprintf("%v/%v", attachment.GatewayNamespace, attachment.GatewayName),
+			SectionName: attachment.ParentRef.SectionName,
+			Port:        attachment.ParentRef.Port,
+			Reason:      string(attachment.Reason),
+			Message:     attachment.Message,
+		})
+	}
+
+	views := []describeView{
+		{
+			Name:      httpRoute.GetName(),
+			Namespace: httpRoute.GetNamespace(),
+		},
+		{
+			Hostnames:  httpRoute.Spec.Hostnames,
+			ParentRefs: httpRoute.Spec.ParentRefs,
+		},
+	}
+	if len(unattachedParents) != 0 {
+		views = append(views, describeView{
+			UnattachedParents: unattachedParents,
+		})
+	}
+	if len(rules) != 0 {
+		views = append(views, describeView{
+			Rules: rules,
+		})
+	}
+	if policyRefs := policymanager.ToPolicyRefs(directlyAttachedPolicies); len(policyRefs) != 0 {
+		views = append(views, describeView{
+			DirectlyAttachedPolicies: policyRefs,
+		})
+	}
+	if len(directPolicies) != 0 {
+		views = append(views, describeView{
+			DirectPolicies: directPolicies,
+		})
+	}
+	if len(effectivePolicies) != 0 {
+		views = append(views, describeView{
+			EffectivePolicies: effectivePolicies,
+		})
+		summaries := make(map[string]map[policymanager.PolicyCrdID]string)
+		for gateway, policies := range effectivePolicies {
+			if len(policies) != 0 {
+				summaries[gateway] = renderers.Summaries(policies)
+			}
+		}
+		if len(summaries) != 0 {
+			views = append(views, describeView{
+				EffectivePolicySummaries: summaries,
+			})
+		}
+	}
+	if len(backendEffectivePolicies) != 0 {
+		views = append(views, describeView{
+			BackendEffectivePolicies: backendEffectivePolicies,
+		})
+	}
+	return views, nil
 }
@@ -9,10 +9,12 @@ import (
 	"github.com/gauravkghildiyal/gwctl/pkg/types"
 	"github.com/google/go-cmp/cmp"
 
+	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
@@ -60,6 +62,11 @@ func TestPrintDescribeView(t *testing.T) {
 			},
 			Spec: gatewayv1beta1.GatewaySpec{
 				GatewayClassName: "foo-gatewayclass",
+				Listeners: []gatewayv1beta1.Listener{{
+					Name:     "http",
+					Port:     80,
+					Protocol: gatewayv1beta1.HTTPProtocolType,
+				}},
 			},
 		},
 		&unstructured.Unstructured{
@@ -89,15 +96,107 @@ func TestPrintDescribeView(t *testing.T) {
 
 		&gatewayv1beta1.HTTPRoute{
 			ObjectMeta: metav1.ObjectMeta{
-				Name: "foo-httproute",
+				Name:      "foo-httproute",
+				Namespace: "default",
 			},
 			Spec: gatewayv1beta1.HTTPRouteSpec{
 				CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
-					ParentRefs: []gatewayv1beta1.ParentReference{{
-						Kind:  common.PtrTo(gatewayv1beta1.Kind("Gateway")),
-						Group: common.PtrTo(gatewayv1beta1.Group("gateway.networking.k8s.io")),
-						Name:  "foo-gateway",
-					}},
+					ParentRefs: []gatewayv1beta1.ParentReference{
+						{
+							Kind:  common.PtrTo(gatewayv1beta1.Kind("Gateway")),
+							Group: common.PtrTo(gatewayv1beta1.Group("gateway.networking.k8s.io")),
+							Name:  "foo-gateway",
+						},
+						{
+							Name: "missing-gateway",
+						},
+						{
+							Name:        "foo-gateway",
+							SectionName: common.PtrTo(gatewayv1beta1.SectionName("https")),
+						},
+					},
+				},
+				Rules: []gatewayv1beta1.HTTPRouteRule{
+					{
+						Matches: []gatewayv1beta1.HTTPRouteMatch{{
+							Path: &gatewayv1beta1.HTTPPathMatch{
+								Type:  common.PtrTo(gatewayv1beta1.PathMatchPathPrefix),
+								Value: common.PtrTo("/foo"),
+							},
+							Headers: []gatewayv1beta1.HTTPHeaderMatch{{
+								Name:  "version",
+								Value: "v2",
+							}},
+							Method: common.PtrTo(gatewayv1beta1.HTTPMethodGet),
+						}},
+						Filters: []gatewayv1beta1.HTTPRouteFilter{{
+							Type: gatewayv1beta1.HTTPRouteFilterRequestHeaderModifier,
+							RequestHeaderModifier: &gatewayv1beta1.HTTPHeaderFilter{
+								Set:    []gatewayv1beta1.HTTPHeader{{Name: "X-A", Value: "1"}},
+								Remove: []string{"X-B"},
+							},
+						}},
+						BackendRefs: []gatewayv1beta1.HTTPBackendRef{
+							{
+								BackendRef: gatewayv1beta1.BackendRef{
+									BackendObjectReference: gatewayv1beta1.BackendObjectReference{
+										Name: "foo-svc",
+										Port: common.PtrTo(gatewayv1beta1.PortNumber(80)),
+									},
+									Weight: common.PtrTo(int32(90)),
+								},
+							},
+							{
+								BackendRef: gatewayv1beta1.BackendRef{
+									BackendObjectReference: gatewayv1beta1.BackendObjectReference{
+										Name: "bar-svc",
+										Port: common.PtrTo(gatewayv1beta1.PortNumber(8080)),
+									},
+									Weight: common.PtrTo(int32(10)),
+								},
+							},
+						},
+					},
+					{
+						Filters: []gatewayv1beta1.HTTPRouteFilter{{
+							Type: gatewayv1beta1.HTTPRouteFilterRequestRedirect,
+							RequestRedirect: &gatewayv1beta1.HTTPRequestRedirectFilter{
+								Scheme:     common.PtrTo("https"),
+								StatusCode: common.PtrTo(301),
+							},
+						}},
+					},
+				},
+			},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-svc",
+				Namespace: "default",
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{
+					Name:       "http",
+					Port:       80,
+					Protocol:   corev1.ProtocolTCP,
+					TargetPort: intstr.FromInt(8080),
+				}},
+			},
+		},
+		&unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "bar.com/v1",
+				"kind":       "TimeoutPolicy",
+				"metadata": map[string]interface{}{
+					"name": "timeout-policy-backend",
+				},
+				"spec": map[string]interface{}{
+					"seconds": int64(90),
+					"targetRef": map[string]interface{}{
+						"kind":      "Service",
+						"name":      "foo-svc",
+						"namespace": "default",
+					},
 				},
 			},
 		},
@@ -112,9 +211,10 @@ func TestPrintDescribeView(t *testing.T) {
 					"condition": "path=/def",
 					"seconds":   int64(60),
 					"targetRef": map[string]interface{}{
-						"group": "gateway.networking.k8s.io",
-						"kind":  "HTTPRoute",
-						"name":  "foo-httproute",
+						"group":     "gateway.networking.k8s.io",
+						"kind":      "HTTPRoute",
+						"name":      "foo-httproute",
+						"namespace": "default",
 					},
 				},
 			},
@@ -177,21 +277,63 @@ func TestPrintDescribeView(t *testing.T) {
 	params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
 	httpRoutes, err := List(context.Background(), params, "")
 	if err != nil {
-		t.Fatalf("Failed to List HTTPRoutespkg/resources/httproutes/httproutes_test.go: %v", err)
+		t.Fatalf("Failed to List HTTPRoutes: %v", err)
+	}
+	if err := PrintDescribeView(context.Background(), params, httpRoutes); err != nil {
+		t.Fatalf("PrintDescribeView() failed: %v", err)
 	}
-	PrintDescribeView(context.Background(), params, httpRoutes)
 
 	got := params.Out.(*bytes.Buffer).String()
 	want := `
 Name: foo-httproute
+Namespace: default
 ParentRefs:
 - group: gateway.networking.k8s.io
   kind: Gateway
   name: foo-gateway
+- group: gateway.networking.k8s.io
+  kind: Gateway
+  name: missing-gateway
+- group: gateway.networking.k8s.io
+  kind: Gateway
+  name: foo-gateway
+  sectionName: https
+UnattachedParents:
+- Gateway: default/missing-gateway
+  Message: Gateway default/missing-gateway not found
+  Reason: NoMatchingParent
+- Gateway: default/foo-gateway
+  Message: no listener matches the sectionName and port of the parentRef
+  Reason: NoMatchingParent
+  SectionName: https
+Rules:
+- BackendRefs:
+  - Kind: Service
+    Name: foo-svc
+    Namespace: default
+    Port: 80/TCP (http) -> 8080
+    Weight: 90
+  - Kind: Service
+    Name: bar-svc
+    Namespace: default
+    Port: 8080 (Service not found)
+    Weight: 10
+  Filters:
+  - 'RequestHeaderModifier: set X-A=1, remove X-B'
+  Matches:
+  - PathPrefix /foo, header version == v2, method GET
+- Filters:
+  - 'RequestRedirect: scheme=https, statusCode=301'
+  Matches:
+  - PathPrefix /
 DirectlyAttachedPolicies:
 - Group: bar.com
   Kind: TimeoutPolicy
   Name: timeout-policy-httproute
+DirectPolicies:
+  TimeoutPolicy.bar.com:
+    condition: path=/def
+    seconds: 60
 EffectivePolicies:
   default/foo-gateway:
     HealthCheckPolicy.foo.com:
@@ -200,11 +342,199 @@ EffectivePolicies:
       key3: value-parent-3
       key4: value-parent-4
       key5: value-parent-5
-    TimeoutPolicy.bar.com:
-      condition: path=/def
-      seconds: 60
+EffectivePolicySummaries:
+  default/foo-gateway:
+    HealthCheckPolicy.foo.com: key1=value-parent-1, key3=value-parent-3, key5=value-parent-5
+      (override from GatewayClass); key2=value-child-2 (default from Gateway); key4=value-parent-4
+      (default from GatewayClass)
+BackendEffectivePolicies:
+  Service/default/bar-svc:
+    default/foo-gateway:
+      HealthCheckPolicy.foo.com:
+        key1: value-parent-1
+        key2: value-child-2
+        key3: value-parent-3
+        key4: value-parent-4
+        key5: value-parent-5
+  Service/default/foo-svc:
+    default/foo-gateway:
+      HealthCheckPolicy.foo.com:
+        key1: value-parent-1
+        key2: value-child-2
+        key3: value-parent-3
+        key4: value-parent-4
+        key5: value-parent-5
 `
 	if diff := cmp.Diff(common.YamlString(want), common.YamlString(got), common.YamlStringTransformer); diff != "" {
 		t.Errorf("Unexpected diff\ngot=\n%v\nwant=\n%v\ndiff (-want +got)=\n%v", got, want, diff)
 	}
 }
+
+// FuzzPrintDescribeView's bits set the matches, filters and backendRefs.
+func FuzzPrintDescribeView(f *testing.F) {
+	seeds := []common.FuzzSeed{
+		{Mask: 0xff, Value: "/foo"},
+		{Mask: 0x55, Value: "*.example.com"},
+		{Mask: 0xaa, Value: "bar"},
+	}
+	common.FuzzObjects(f, seeds, func(t *testing.T, isSet func(bit int) bool, value string) {
+		httpRoute := &gatewayv1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-httproute",
+				Namespace: "default",
+			},
+			Spec: gatewayv1beta1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
+					ParentRefs: []gatewayv1beta1.ParentReference{{Name: "foo-gateway"}},
+				},
+				Rules: []gatewayv1beta1.HTTPRouteRule{{}},
+			},
+		}
+		rule := &httpRoute.Spec.Rules[0]
+		if isSet(0) {
+			rule.Matches = append(rule.Matches, gatewayv1beta1.HTTPRouteMatch{Path: &gatewayv1beta1.HTTPPathMatch{}})
+		}
+		if isSet(1) {
+			rule.Matches = append(rule.Matches, gatewayv1beta1.HTTPRouteMatch{
+				Path:        &gatewayv1beta1.HTTPPathMatch{Value: common.PtrTo(value)},
+				Headers:     []gatewayv1beta1.HTTPHeaderMatch{{Name: "version", Value: value}},
+				QueryParams: []gatewayv1beta1.HTTPQueryParamMatch{{Name: "debug", Value: value}},
+			})
+		}
+		if isSet(2) {
+			// Filters with their type set but without the corresponding
+			// configuration.
+			for _, filterType := range []gatewayv1beta1.HTTPRouteFilterType{
+				gatewayv1beta1.HTTPRouteFilterRequestHeaderModifier,
+				gatewayv1beta1.HTTPRouteFilterResponseHeaderModifier,
+				gatewayv1beta1.HTTPRouteFilterRequestRedirect,
+				gatewayv1beta1.HTTPRouteFilterURLRewrite,
+				gatewayv1beta1.HTTPRouteFilterRequestMirror,
+				gatewayv1beta1.HTTPRouteFilterExtensionRef,
+			} {
+				rule.Filters = append(rule.Filters, gatewayv1beta1.HTTPRouteFilter{Type: filterType})
+			}
+		}
+		if isSet(3) {
+			rule.Filters = append(rule.Filters,
+				gatewayv1beta1.HTTPRouteFilter{
+					Type:            gatewayv1beta1.HTTPRouteFilterRequestRedirect,
+					RequestRedirect: &gatewayv1beta1.HTTPRequestRedirectFilter{Path: &gatewayv1beta1.HTTPPathModifier{Type: gatewayv1beta1.FullPathHTTPPathModifier}},
+				},
+				gatewayv1beta1.HTTPRouteFilter{
+					Type:          gatewayv1beta1.HTTPRouteFilterRequestMirror,
+					RequestMirror: &gatewayv1beta1.HTTPRequestMirrorFilter{BackendRef: gatewayv1beta1.BackendObjectReference{Name: "mirror-svc"}},
+				},
+			)
+		}
+		if isSet(4) {
+			backendRef := gatewayv1beta1.HTTPBackendRef{
+				BackendRef: gatewayv1beta1.BackendRef{
+					BackendObjectReference: gatewayv1beta1.BackendObjectReference{Name: "foo-svc"},
+				},
+			}
+			if isSet(5) {
+				backendRef.Port = common.PtrTo(gatewayv1beta1.PortNumber(80))
+			}
+			rule.BackendRefs = append(rule.BackendRefs, backendRef)
+		}
+		if isSet(6) {
+			httpRoute.Spec.Hostnames = []gatewayv1beta1.Hostname{gatewayv1beta1.Hostname(value)}
+		}
+
+		objects := []runtime.Object{
+			&gatewayv1beta1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo-gateway",
+					Namespace: "default",
+				},
+				Spec: gatewayv1beta1.GatewaySpec{
+					Listeners: []gatewayv1beta1.Listener{{Name: "http", Port: 80, Protocol: gatewayv1beta1.HTTPProtocolType}},
+				},
+			},
+			httpRoute,
+		}
+		if isSet(7) {
+			objects = append(objects, &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo-svc",
+					Namespace: "default",
+				},
+			})
+		}
+
+		params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
+		httpRoutes, err := List(context.Background(), params, "")
+		if err != nil {
+			t.Fatalf("Failed to List HTTPRoutes: %v", err)
+		}
+		if err := PrintDescribeView(context.Background(), params, httpRoutes); err != nil {
+			t.Fatalf("PrintDescribeView() failed: %v", err)
+		}
+	})
+}
+
+func TestPrintDescribeViewReadsObjectsOnce(t *testing.T) {
+	objects := []runtime.Object{
+		&gatewayv1beta1.GatewayClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "foo-gatewayclass",
+			},
+		},
+		&gatewayv1beta1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-gateway",
+				Namespace: "default",
+			},
+			Spec: gatewayv1beta1.GatewaySpec{
+				GatewayClassName: "foo-gatewayclass",
+				Listeners:        []gatewayv1beta1.Listener{{Name: "http", Port: 80, Protocol: gatewayv1beta1.HTTPProtocolType}},
+			},
+		},
+	}
+	for _, name := range []string{"foo-httproute", "bar-httproute", "baz-httproute"} {
+		objects = append(objects, &gatewayv1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: gatewayv1beta1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
+					ParentRefs: []gatewayv1beta1.ParentReference{{Name: "foo-gateway"}, {Name: "unknown-gateway"}},
+				},
+				Rules: []gatewayv1beta1.HTTPRouteRule{{
+					BackendRefs: []gatewayv1beta1.HTTPBackendRef{{
+						BackendRef: gatewayv1beta1.BackendRef{
+							BackendObjectReference: gatewayv1beta1.BackendObjectReference{Name: "foo-svc"},
+						},
+					}},
+				}},
+			},
+		})
+	}
+
+	fakeClients := common.MustClientsForTest(t, objects...)
+	counting := common.NewCountingClient(fakeClients.Client)
+	fakeClients.Client = counting
+	params := types.MustParamsForTest(t, fakeClients)
+
+	httpRoutes, err := List(context.Background(), params, "")
+	if err != nil {
+		t.Fatalf("Failed to List HTTPRoutes: %v", err)
+	}
+	if err := PrintDescribeView(context.Background(), params, httpRoutes); err != nil {
+		t.Fatalf("PrintDescribeView() failed: %v", err)
+	}
+
+	// Every HTTPRoute references both Gateways many times while its effective
+	// policies are computed, yet every object is only read once.
+	requests := counting.Requests()
+	if _, ok := requests["get *v1beta1.Gateway default/foo-gateway"]; !ok {
+		t.Errorf("PrintDescribeView() did not read foo-gateway; requests=%v", requests)
+	}
+	for key, count := range requests {
+		if count != 1 {
+			t.Errorf("PrintDescribeView() issued %q %v times; want once", key, count)
+		}
+	}
+}
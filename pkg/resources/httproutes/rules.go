@@ -0,0 +1,252 @@
+package httproutes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimachinerytypes "k8s.io/apimachinery/pkg/types"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/policymanager"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+)
+
+type ruleView struct {
+	// Matches are rendered in a human readable form. The rule matches a request
+	// if any one of the Matches is satisfied.
+	Matches     []string         `json:",omitempty"`
+	Filters     []string         `json:",omitempty"`
+	BackendRefs []backendRefView `json:",omitempty"`
+}
+
+type backendRefView struct {
+	Group     string `json:",omitempty"`
+	Kind      string `json:",omitempty"`
+	Name      string `json:",omitempty"`
+	Namespace string `json:",omitempty"`
+	// Port is the port of the backendRef, along with the details of the
+	// corresponding Service port if the backend is a Service.
+	Port    string   `json:",omitempty"`
+	Weight  int32    `json:""`
+	Filters []string `json:",omitempty"`
+	// ReferenceGrant is the "<namespace>/<name>" of the ReferenceGrant which
+	// permits a cross-namespace backendRef.
+	ReferenceGrant string `json:",omitempty"`
+	// RefNotPermitted is true for a cross-namespace backendRef which is not
+	// permitted by any ReferenceGrant.
+	RefNotPermitted bool `json:",omitempty"`
+}
+
+func newRuleViews(ctx context.Context, params *types.Params, httpRoute gatewayv1beta1.HTTPRoute) ([]ruleView, error) {
+	var result []ruleView
+	for _, rule := range httpRoute.Spec.Rules {
+		view := ruleView{}
+
+		for _, match := range rule.Matches {
+			view.Matches = append(view.Matches, formatMatch(match))
+		}
+		if len(rule.Matches) == 0 {
+			// A rule without matches will match all requests.
+			view.Matches = []string{formatMatch(gatewayv1beta1.HTTPRouteMatch{})}
+		}
+
+		for _, filter := range rule.Filters {
+			view.Filters = append(view.Filters, formatFilter(filter, httpRoute.GetNamespace()))
+		}
+
+		for _, backendRef := range rule.BackendRefs {
+			objRef := policymanager.BackendRefToObjRef(backendRef.BackendObjectReference, httpRoute.GetNamespace())
+			backendRefView := backendRefView{
+				Group:     objRef.Group,
+				Kind:      objRef.Kind,
+				Name:      objRef.Name,
+				Namespace: objRef.Namespace,
+				Weight:    1,
+			}
+			if backendRef.Weight != nil {
+				backendRefView.Weight = *backendRef.Weight
+			}
+			if permitted, grant := params.PolicyManager.IsBackendRefPermitted(httpRoute, objRef); !permitted {
+				backendRefView.RefNotPermitted = true
+			} else if grant != nil {
+				backendRefView.ReferenceGrant = fmt.Sprintf("%v/%v", grant.GetNamespace(), grant.GetName())
+			}
+			port, err := formatBackendPort(ctx, params, objRef, backendRef.Port)
+			if err != nil {
+				return nil, err
+			}
+			backendRefView.Port = port
+			for _, filter := range backendRef.Filters {
+				backendRefView.Filters = append(backendRefView.Filters, formatFilter(filter, httpRoute.GetNamespace()))
+			}
+			view.BackendRefs = append(view.BackendRefs, backendRefView)
+		}
+
+		result = append(result, view)
+	}
+	return result, nil
+}
+
+// formatMatch renders the match like:
+//
+//	PathPrefix /foo, header version == v2, query debug == true, method GET
+func formatMatch(match gatewayv1beta1.HTTPRouteMatch) string {
+	pathType, pathValue := gatewayv1beta1.PathMatchPathPrefix, "/"
+	if match.Path != nil {
+		if match.Path.Type != nil {
+			pathType = *match.Path.Type
+		}
+		if match.Path.Value != nil {
+			pathValue = *match.Path.Value
+		}
+	}
+	parts := []string{fmt.Sprintf("%v %v", pathType, pathValue)}
+
+	for _, header := range match.Headers {
+		operator := "=="
+		if header.Type != nil && *header.Type == gatewayv1beta1.HeaderMatchRegularExpression {
+			operator = "=~"
+		}
+		parts = append(parts, fmt.Sprintf("header %v %v %v", header.Name, operator, header.Value))
+	}
+	for _, queryParam := range match.QueryParams {
+		operator := "=="
+		if queryParam.Type != nil && *queryParam.Type == gatewayv1beta1.QueryParamMatchRegularExpression {
+			operator = "=~"
+		}
+		parts = append(parts, fmt.Sprintf("query %v %v %v", queryParam.Name, operator, queryParam.Value))
+	}
+	if match.Method != nil {
+		parts = append(parts, fmt.Sprintf("method %v", *match.Method))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatFilter renders the filter like:
+//
+//	RequestRedirect: scheme=https, statusCode=301
+func formatFilter(filter gatewayv1beta1.HTTPRouteFilter, routeNamespace string) string {
+	var parts []string
+	switch filter.Type {
+	case gatewayv1beta1.HTTPRouteFilterRequestHeaderModifier:
+		parts = formatHeaderFilter(filter.RequestHeaderModifier)
+	case gatewayv1beta1.HTTPRouteFilterResponseHeaderModifier:
+		parts = formatHeaderFilter(filter.ResponseHeaderModifier)
+	case gatewayv1beta1.HTTPRouteFilterRequestRedirect:
+		if redirect := filter.RequestRedirect; redirect != nil {
+			if redirect.Scheme != nil {
+				parts = append(parts, fmt.Sprintf("scheme=%v", *redirect.Scheme))
+			}
+			if redirect.Hostname != nil {
+				parts = append(parts, fmt.Sprintf("hostname=%v", *redirect.Hostname))
+			}
+			if redirect.Path != nil {
+				parts = append(parts, fmt.Sprintf("path=%v", formatPathModifier(*redirect.Path)))
+			}
+			if redirect.Port != nil {
+				parts = append(parts, fmt.Sprintf("port=%v", *redirect.Port))
+			}
+			if redirect.StatusCode != nil {
+				parts = append(parts, fmt.Sprintf("statusCode=%v", *redirect.StatusCode))
+			}
+		}
+	case gatewayv1beta1.HTTPRouteFilterURLRewrite:
+		if rewrite := filter.URLRewrite; rewrite != nil {
+			if rewrite.Hostname != nil {
+				parts = append(parts, fmt.Sprintf("hostname=%v", *rewrite.Hostname))
+			}
+			if rewrite.Path != nil {
+				parts = append(parts, fmt.Sprintf("path=%v", formatPathModifier(*rewrite.Path)))
+			}
+		}
+	case gatewayv1beta1.HTTPRouteFilterRequestMirror:
+		if mirror := filter.RequestMirror; mirror != nil {
+			objRef := policymanager.BackendRefToObjRef(mirror.BackendRef, routeNamespace)
+			mirrorTo := fmt.Sprintf("%v %v/%v", objRef.Kind, objRef.Namespace, objRef.Name)
+			if mirror.BackendRef.Port != nil {
+				mirrorTo = fmt.Sprintf("%v:%v", mirrorTo, *mirror.BackendRef.Port)
+			}
+			parts = append(parts, mirrorTo)
+		}
+	case gatewayv1beta1.HTTPRouteFilterExtensionRef:
+		if ref := filter.ExtensionRef; ref != nil {
+			parts = append(parts, fmt.Sprintf("%v/%v %v", ref.Group, ref.Kind, ref.Name))
+		}
+	}
+
+	if len(parts) == 0 {
+		return string(filter.Type)
+	}
+	return fmt.Sprintf("%v: %v", filter.Type, strings.Join(parts, ", "))
+}
+
+func formatHeaderFilter(filter *gatewayv1beta1.HTTPHeaderFilter) []string {
+	if filter == nil {
+		return nil
+	}
+	var parts []string
+	for _, header := range filter.Set {
+		parts = append(parts, fmt.Sprintf("set %v=%v", header.Name, header.Value))
+	}
+	for _, header := range filter.Add {
+		parts = append(parts, fmt.Sprintf("add %v=%v", header.Name, header.Value))
+	}
+	for _, name := range filter.Remove {
+		parts = append(parts, fmt.Sprintf("remove %v", name))
+	}
+	return parts
+}
+
+func formatPathModifier(modifier gatewayv1beta1.HTTPPathModifier) string {
+	switch modifier.Type {
+	case gatewayv1beta1.FullPathHTTPPathModifier:
+		if modifier.ReplaceFullPath != nil {
+			return fmt.Sprintf("%v(%v)", modifier.Type, *modifier.ReplaceFullPath)
+		}
+	case gatewayv1beta1.PrefixMatchHTTPPathModifier:
+		if modifier.ReplacePrefixMatch != nil {
+			return fmt.Sprintf("%v(%v)", modifier.Type, *modifier.ReplacePrefixMatch)
+		}
+	}
+	return string(modifier.Type)
+}
+
+// formatBackendPort renders the port of the backendRef. For Service backends,
+// the port is resolved against the Service to include the name, protocol and
+// target port of the Service port, like:
+//
+//	80/TCP (http) -> 8080
+func formatBackendPort(ctx context.Context, params *types.Params, backendRef policymanager.ObjRef, port *gatewayv1beta1.PortNumber) (string, error) {
+	if port == nil {
+		return "", nil
+	}
+	if backendRef.Group != "" || backendRef.Kind != "Service" {
+		return fmt.Sprintf("%v", *port), nil
+	}
+
+	svc := &corev1.Service{}
+	nn := apimachinerytypes.NamespacedName{Namespace: backendRef.Namespace, Name: backendRef.Name}
+	if err := params.Client.Get(ctx, nn, svc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Sprintf("%v (Service not found)", *port), nil
+		}
+		return "", err
+	}
+	for _, svcPort := range svc.Spec.Ports {
+		if svcPort.Port != int32(*port) {
+			continue
+		}
+		result := fmt.Sprintf("%v/%v", svcPort.Port, svcPort.Protocol)
+		if svcPort.Name != "" {
+			result = fmt.Sprintf("%v (%v)", result, svcPort.Name)
+		}
+		if svcPort.TargetPort.String() != "0" {
+			result = fmt.Sprintf("%v -> %v", result, svcPort.TargetPort.String())
+		}
+		return result, nil
+	}
+	return fmt.Sprintf("%v (port not found in Service)", *port), nil
+}
@@ -2,13 +2,38 @@ package namespaces
 
 import (
 	"context"
+	"fmt"
+	"sort"
 
 	corev1 "k8s.io/api/core/v1"
+	apimachinerytypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
 	"github.com/gauravkghildiyal/gwctl/pkg/policymanager"
 	"github.com/gauravkghildiyal/gwctl/pkg/types"
 )
 
+func List(ctx context.Context, params *types.Params) ([]corev1.Namespace, error) {
+	nsList := &corev1.NamespaceList{}
+	if err := params.Client.List(ctx, nsList); err != nil {
+		return []corev1.Namespace{}, err
+	}
+
+	return nsList.Items, nil
+}
+
+func Get(ctx context.Context, params *types.Params, name string) (corev1.Namespace, error) {
+	ns := &corev1.Namespace{}
+	nn := apimachinerytypes.NamespacedName{Name: name}
+	if err := params.Client.Get(ctx, nn, ns); err != nil {
+		return corev1.Namespace{}, err
+	}
+
+	return *ns, nil
+}
+
 func GetAttachedPolicies(ctx context.Context, params *types.Params, name string) ([]policymanager.Policy, error) {
 	n := &corev1.Namespace{}
 	gvks, _, err := params.Client.Scheme().ObjectKinds(n)
@@ -23,3 +48,129 @@ func GetAttachedPolicies(ctx context.Context, params *types.Params, name string)
 	}
 	return params.PolicyManager.PoliciesAttachedTo(objRef), nil
 }
+
+// GetInheritingResources returns references to all Gateways, HTTPRoutes and
+// Backends within the namespace. These are the resources which inherit the
+// policies attached to the namespace.
+//
+// Backends are discovered through the backendRefs of HTTPRoutes (from any
+// namespace) which point into this namespace. Cross-namespace backendRefs are
+// only considered if they are permitted by a ReferenceGrant.
+func GetInheritingResources(ctx context.Context, params *types.Params, name string) ([]policymanager.ObjRef, error) {
+	var result []policymanager.ObjRef
+
+	gwList := &gatewayv1beta1.GatewayList{}
+	if err := params.Client.List(ctx, gwList, client.InNamespace(name)); err != nil {
+		return nil, err
+	}
+	for _, gw := range gwList.Items {
+		result = append(result, policymanager.ObjRef{
+			Group:     gatewayv1beta1.GroupName,
+			Kind:      "Gateway",
+			Name:      gw.GetName(),
+			Namespace: gw.GetNamespace(),
+		})
+	}
+
+	httpRouteList := &gatewayv1beta1.HTTPRouteList{}
+	if err := params.Client.List(ctx, httpRouteList); err != nil {
+		return nil, err
+	}
+	backendRefs := make(map[policymanager.ObjRef]bool)
+	for _, httpRoute := range httpRouteList.Items {
+		if httpRoute.GetNamespace() == name {
+			result = append(result, policymanager.ObjRef{
+				Group:     gatewayv1beta1.GroupName,
+				Kind:      "HTTPRoute",
+				Name:      httpRoute.GetName(),
+				Namespace: httpRoute.GetNamespace(),
+			})
+		}
+
+		for _, rule := range httpRoute.Spec.Rules {
+			for _, backendRef := range rule.BackendRefs {
+				objRef := policymanager.BackendRefToObjRef(backendRef.BackendObjectReference, httpRoute.GetNamespace())
+				if objRef.Namespace != name {
+					continue
+				}
+				if permitted, _ := params.PolicyManager.IsBackendRefPermitted(httpRoute, objRef); permitted {
+					backendRefs[objRef] = true
+				}
+			}
+		}
+	}
+
+	var backends []policymanager.ObjRef
+	for objRef := range backendRefs {
+		backends = append(backends, objRef)
+	}
+	sort.Slice(backends, func(i, j int) bool {
+		a := fmt.Sprintf("%v/%v/%v", backends[i].Group, backends[i].Kind, backends[i].Name)
+		b := fmt.Sprintf("%v/%v/%v", backends[j].Group, backends[j].Kind, backends[j].Name)
+		return a < b
+	})
+	result = append(result, backends...)
+
+	return result, nil
+}
+
+type describeView struct {
+	// Namespace name
+	Name                     string                 `json:",omitempty"`
+	DirectlyAttachedPolicies []policymanager.ObjRef `json:",omitempty"`
+	// InheritedBy lists the resources within the namespace which inherit the
+	// policies attached to the namespace.
+	InheritedBy []policymanager.ObjRef `json:",omitempty"`
+}
+
+func PrintDescribeView(ctx context.Context, params *types.Params, nsList []corev1.Namespace) error {
+	for i, ns := range nsList {
+		directlyAttachedPolicies, err := GetAttachedPolicies(ctx, params, ns.Name)
+		if err != nil {
+			return err
+		}
+
+		views := []describeView{
+			{
+				Name: ns.GetName(),
+			},
+		}
+		if policyRefs := policymanager.ToPolicyRefs(directlyAttachedPolicies); len(policyRefs) != 0 {
+			views = append(views, describeView{
+				DirectlyAttachedPolicies: policyRefs,
+			})
+		}
+
+		hasInheritedPolicy := false
+		for _, policy := range directlyAttachedPolicies {
+			if policy.IsInherited() {
+				hasInheritedPolicy = true
+				break
+			}
+		}
+		if hasInheritedPolicy {
+			inheritingResources, err := GetInheritingResources(ctx, params, ns.Name)
+			if err != nil {
+				return err
+			}
+			if len(inheritingResources) != 0 {
+				views = append(views, describeView{
+					InheritedBy: inheritingResources,
+				})
+			}
+		}
+
+		for _, view := range views {
+			b, err := common.Marshal(params.Output, view)
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(params.Out, string(b))
+		}
+
+		if i+1 != len(nsList) {
+			fmt.Fprintf(params.Out, "\n\n")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package namespaces
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+func TestPrintDescribeView(t *testing.T) {
+	objects := []runtime.Object{
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+		},
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "ns1",
+			},
+		},
+
+		&gatewayv1beta1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-gateway",
+				Namespace: "default",
+			},
+			Spec: gatewayv1beta1.GatewaySpec{
+				GatewayClassName: "foo-gatewayclass",
+			},
+		},
+		&gatewayv1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-httproute",
+				Namespace: "default",
+			},
+			Spec: gatewayv1beta1.HTTPRouteSpec{
+				Rules: []gatewayv1beta1.HTTPRouteRule{{
+					BackendRefs: []gatewayv1beta1.HTTPBackendRef{{
+						BackendRef: gatewayv1beta1.BackendRef{
+							BackendObjectReference: gatewayv1beta1.BackendObjectReference{
+								Name: "foo-svc",
+							},
+						},
+					}},
+				}},
+			},
+		},
+		&gatewayv1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "bar-httproute",
+				Namespace: "ns1",
+			},
+			Spec: gatewayv1beta1.HTTPRouteSpec{
+				Rules: []gatewayv1beta1.HTTPRouteRule{{
+					BackendRefs: []gatewayv1beta1.HTTPBackendRef{
+						{
+							BackendRef: gatewayv1beta1.BackendRef{
+								BackendObjectReference: gatewayv1beta1.BackendObjectReference{
+									Name:      "bar-svc",
+									Namespace: common.PtrTo(gatewayv1beta1.Namespace("default")),
+								},
+							},
+						},
+						{
+							BackendRef: gatewayv1beta1.BackendRef{
+								BackendObjectReference: gatewayv1beta1.BackendObjectReference{
+									Name: "baz-svc",
+								},
+							},
+						},
+						{
+							// Not permitted by any ReferenceGrant.
+							BackendRef: gatewayv1beta1.BackendRef{
+								BackendObjectReference: gatewayv1beta1.BackendObjectReference{
+									Name:      "qux-svc",
+									Namespace: common.PtrTo(gatewayv1beta1.Namespace("default")),
+								},
+							},
+						},
+					},
+				}},
+			},
+		},
+		&gatewayv1beta1.ReferenceGrant{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "allow-bar-svc",
+				Namespace: "default",
+			},
+			Spec: gatewayv1beta1.ReferenceGrantSpec{
+				From: []gatewayv1beta1.ReferenceGrantFrom{{
+					Group:     gatewayv1beta1.GroupName,
+					Kind:      "HTTPRoute",
+					Namespace: "ns1",
+				}},
+				To: []gatewayv1beta1.ReferenceGrantTo{{
+					Kind: "Service",
+					Name: common.PtrTo(gatewayv1beta1.ObjectName("bar-svc")),
+				}},
+			},
+		},
+
+		&apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "timeoutpolicies.bar.com",
+				Labels: map[string]string{
+					common.GatewayPolicyLabelKey: "inherited",
+				},
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Scope:    apiextensionsv1.ClusterScoped,
+				Group:    "bar.com",
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1"}},
+				Names: apiextensionsv1.CustomResourceDefinitionNames{
+					Plural: "timeoutpolicies",
+					Kind:   "TimeoutPolicy",
+				},
+			},
+		},
+		&unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "bar.com/v1",
+				"kind":       "TimeoutPolicy",
+				"metadata": map[string]interface{}{
+					"name": "timeout-policy-namespace",
+				},
+				"spec": map[string]interface{}{
+					"default": map[string]interface{}{
+						"seconds": int64(30),
+					},
+					"targetRef": map[string]interface{}{
+						"kind": "Namespace",
+						"name": "default",
+					},
+				},
+			},
+		},
+	}
+
+	params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
+	nsList, err := List(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Failed to List Namespaces: %v", err)
+	}
+	if err := PrintDescribeView(context.Background(), params, nsList); err != nil {
+		t.Fatalf("PrintDescribeView() failed: %v", err)
+	}
+
+	got := params.Out.(*bytes.Buffer).String()
+	want := `
+Name: default
+DirectlyAttachedPolicies:
+- Group: bar.com
+  Kind: TimeoutPolicy
+  Name: timeout-policy-namespace
+InheritedBy:
+- Group: gateway.networking.k8s.io
+  Kind: Gateway
+  Name: foo-gateway
+  Namespace: default
+- Group: gateway.networking.k8s.io
+  Kind: HTTPRoute
+  Name: foo-httproute
+  Namespace: default
+- Kind: Service
+  Name: bar-svc
+  Namespace: default
+- Kind: Service
+  Name: foo-svc
+  Namespace: default
+
+
+Name: ns1
+`
+	if diff := cmp.Diff(common.YamlString(want), common.YamlString(got), common.YamlStringTransformer); diff != "" {
+		t.Errorf("Unexpected diff\ngot=\n%v\nwant=\n%v\ndiff (-want +got)=\n%v", got, want, diff)
+	}
+}
+
+// FuzzPrintDescribeView's bits set a backendRef and an attached policy.
+func FuzzPrintDescribeView(f *testing.F) {
+	seeds := []common.FuzzSeed{
+		{Mask: 0xff, Value: "ns1"},
+	}
+	common.FuzzObjects(f, seeds, func(t *testing.T, isSet func(bit int) bool, value string) {
+		backendRef := gatewayv1beta1.HTTPBackendRef{
+			BackendRef: gatewayv1beta1.BackendRef{
+				BackendObjectReference: gatewayv1beta1.BackendObjectReference{Name: "foo-svc"},
+			},
+		}
+		if isSet(0) {
+			backendRef.Namespace = common.PtrTo(gatewayv1beta1.Namespace(value))
+		}
+		if isSet(1) {
+			backendRef.Kind = common.PtrTo(gatewayv1beta1.Kind(value))
+		}
+		httpRoute := &gatewayv1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-httproute",
+				Namespace: "ns1",
+			},
+		}
+		if isSet(2) {
+			httpRoute.Spec.Rules = []gatewayv1beta1.HTTPRouteRule{{BackendRefs: []gatewayv1beta1.HTTPBackendRef{backendRef}}}
+		}
+
+		objects := []runtime.Object{
+			&corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "ns1",
+				},
+			},
+			httpRoute,
+		}
+		if isSet(3) {
+			objects = append(objects,
+				&apiextensionsv1.CustomResourceDefinition{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "timeoutpolicies.bar.com",
+						Labels: map[string]string{
+							common.GatewayPolicyLabelKey: "inherited",
+						},
+					},
+					Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+						Scope:    apiextensionsv1.ClusterScoped,
+						Group:    "bar.com",
+						Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1"}},
+						Names: apiextensionsv1.CustomResourceDefinitionNames{
+							Plural: "timeoutpolicies",
+							Kind:   "TimeoutPolicy",
+						},
+					},
+				},
+				&unstructured.Unstructured{
+					Object: map[string]interface{}{
+						"apiVersion": "bar.com/v1",
+						"kind":       "TimeoutPolicy",
+						"metadata": map[string]interface{}{
+							"name": "timeout-policy-namespace",
+						},
+						"spec": map[string]interface{}{
+							"targetRef": map[string]interface{}{
+								"kind": "Namespace",
+								"name": "ns1",
+							},
+						},
+					},
+				},
+			)
+		}
+
+		params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
+		nsList, err := List(context.Background(), params)
+		if err != nil {
+			t.Fatalf("Failed to List Namespaces: %v", err)
+		}
+		if err := PrintDescribeView(context.Background(), params, nsList); err != nil {
+			t.Fatalf("PrintDescribeView() failed: %v", err)
+		}
+	})
+}
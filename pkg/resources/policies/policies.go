@@ -1,63 +1,169 @@
 package policies
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
 	"sort"
 	"strings"
-	"text/tabwriter"
 
-	"sigs.k8s.io/yaml"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/strings/slices"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
 	"github.com/gauravkghildiyal/gwctl/pkg/policymanager"
+	"github.com/gauravkghildiyal/gwctl/pkg/renderers"
 	"github.com/gauravkghildiyal/gwctl/pkg/types"
+	"github.com/gauravkghildiyal/gwctl/pkg/whatif"
 )
 
-func Print(params *types.Params, policies []policymanager.Policy) {
+func Print(params *types.Params, policies []policymanager.Policy, allNamespaces bool) {
+	header, rows := Table(policies, allNamespaces)
+	if err := common.WriteTable(params.Out, header, rows, params.Config.TableColumns("policies")); err != nil {
+		panic(err)
+	}
+}
+
+// Table returns the header and rows printed by Print, with the policies sorted
+// by namespace, name and kind. The policies of all namespaces are told apart
+// by a NAMESPACE column, which is empty for cluster scoped policies.
+func Table(policies []policymanager.Policy, allNamespaces bool) ([]string, [][]string) {
 	sort.Slice(policies, func(i, j int) bool {
-		a := fmt.Sprintf("%v/%v", policies[i].Unstructured().GetNamespace(), policies[i].Unstructured().GetName())
-		b := fmt.Sprintf("%v/%v", policies[j].Unstructured().GetNamespace(), policies[j].Unstructured().GetName())
+		a := fmt.Sprintf("%v/%v/%v", policies[i].Unstructured().GetNamespace(), policies[i].Unstructured().GetName(), policies[i].PolicyCrdID())
+		b := fmt.Sprintf("%v/%v/%v", policies[j].Unstructured().GetNamespace(), policies[j].Unstructured().GetName(), policies[j].PolicyCrdID())
 		return a < b
 	})
 
-	tw := tabwriter.NewWriter(params.Out, 0, 0, 2, ' ', 0)
-	row := []string{"POLICYNAME", "POLICYKIND", "TARGETNAME", "TARGETKIND"}
-	tw.Write([]byte(strings.Join(row, "\t") + "\n"))
-
+	header := []string{"POLICYNAME", "POLICYKIND", "TARGETNAME", "TARGETKIND", "SUMMARY"}
+	if allNamespaces {
+		header = append([]string{"NAMESPACE"}, header...)
+	}
+	var rows [][]string
 	for _, policy := range policies {
 		row := []string{
 			policy.Unstructured().GetName(),
 			policy.Unstructured().GroupVersionKind().Kind,
 			policy.TargetRef().Name,
 			policy.TargetRef().Kind,
+			renderers.Summary(policy),
 		}
-		tw.Write([]byte(strings.Join(row, "\t") + "\n"))
+		if allNamespaces {
+			row = append([]string{policy.Unstructured().GetNamespace()}, row...)
+		}
+		rows = append(rows, row)
 	}
-	tw.Flush()
+	return header, rows
 }
 
 func PrintCRDs(params *types.Params, policyCRDs []policymanager.PolicyCRD) {
+	header, rows := CRDTable(policyCRDs)
+	if err := common.WriteTable(params.Out, header, rows, params.Config.TableColumns("policycrds")); err != nil {
+		panic(err)
+	}
+}
+
+// CRDTable returns the header and rows printed by PrintCRDs, with the Policy
+// CRDs sorted by name.
+func CRDTable(policyCRDs []policymanager.PolicyCRD) ([]string, [][]string) {
 	sort.Slice(policyCRDs, func(i, j int) bool {
 		a := fmt.Sprintf("%v/%v", policyCRDs[i].CRD().GetNamespace(), policyCRDs[i].CRD().GetName())
 		b := fmt.Sprintf("%v/%v", policyCRDs[j].CRD().GetNamespace(), policyCRDs[j].CRD().GetName())
 		return a < b
 	})
 
-	tw := tabwriter.NewWriter(params.Out, 0, 0, 2, ' ', 0)
-	row := []string{"CRD_NAME", "CRD_GROUP", "CRD_KIND", "CRD_INHERITED", "CRD_SCOPE"}
-	tw.Write([]byte(strings.Join(row, "\t") + "\n"))
-
+	header := []string{"CRD_NAME", "CRD_GROUP", "CRD_KIND", "CRD_INHERITED", "CRD_SCOPE", "CRD_SCHEMA", "CRD_MERGE_STRATEGY"}
+	var rows [][]string
 	for _, policyCRD := range policyCRDs {
-		row := []string{
+		rows = append(rows, []string{
 			policyCRD.CRD().Name,
 			policyCRD.CRD().Spec.Group,
 			policyCRD.CRD().Spec.Names.Kind,
 			fmt.Sprintf("%v", policyCRD.IsInherited()),
 			string(policyCRD.CRD().Spec.Scope),
-		}
-		tw.Write([]byte(strings.Join(row, "\t") + "\n"))
+			schemaSummary(policyCRD.Validate()),
+			mergeStrategy(policyCRD),
+		})
+	}
+	return header, rows
+}
+
+// mergeStrategy formats the MergeStrategy of the Policy CRD, which falls back to
+// the default if it is invalid.
+func mergeStrategy(policyCRD policymanager.PolicyCRD) string {
+	result, err := policyCRD.MergeStrategy()
+	if err != nil {
+		return string(policymanager.MergeStrategyJSONMerge)
+	}
+	return result.String()
+}
+
+// schemaSummary summarizes the schema issues of a Policy CRD, like "Valid",
+// "Valid (1 warning)" or "Invalid (2 errors, 1 warning)".
+func schemaSummary(issues []policymanager.SchemaIssue) string {
+	errors, warnings := policymanager.SplitSchemaIssues(issues)
+	var counts []string
+	if len(errors) != 0 {
+		counts = append(counts, pluralize(len(errors), "error"))
+	}
+	if len(warnings) != 0 {
+		counts = append(counts, pluralize(len(warnings), "warning"))
+	}
+	result := "Valid"
+	if len(errors) != 0 {
+		result = "Invalid"
+	}
+	if len(counts) != 0 {
+		result += fmt.Sprintf(" (%v)", strings.Join(counts, ", "))
+	}
+	return result
+}
+
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%v %v", count, noun)
 	}
-	tw.Flush()
+	return fmt.Sprintf("%v %vs", count, noun)
+}
+
+type crdValidationView struct {
+	Name     string   `json:",omitempty"`
+	Valid    bool     `json:""`
+	Errors   []string `json:",omitempty"`
+	Warnings []string `json:",omitempty"`
+}
+
+// PrintCRDValidation prints the issues of each Policy CRD schema with respect
+// to GEP-713 conventions. A CRD is valid if it has no errors.
+func PrintCRDValidation(params *types.Params, policyCRDs []policymanager.PolicyCRD) {
+	sort.Slice(policyCRDs, func(i, j int) bool {
+		return policyCRDs[i].CRD().GetName() < policyCRDs[j].CRD().GetName()
+	})
+
+	var views []crdValidationView
+	for _, policyCRD := range policyCRDs {
+		errors, warnings := policymanager.SplitSchemaIssues(policyCRD.Validate())
+		views = append(views, crdValidationView{
+			Name:     policyCRD.CRD().GetName(),
+			Valid:    len(errors) == 0,
+			Errors:   errors,
+			Warnings: warnings,
+		})
+	}
+
+	b, err := common.Marshal(params.Output, views)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Fprint(params.Out, string(b))
 }
 
 type describeView struct {
@@ -66,9 +172,252 @@ type describeView struct {
 	Group     string                `json:",omitempty"`
 	Kind      string                `json:",omitempty"`
 	TargetRef *policymanager.ObjRef `json:",omitempty"`
+	// ReferenceGrant is the "<namespace>/<name>" of the ReferenceGrant which
+	// permits a cross-namespace targetRef.
+	ReferenceGrant string `json:",omitempty"`
+	// RefNotPermitted is true for a cross-namespace targetRef which is not
+	// permitted by any ReferenceGrant. Such a policy does not apply to its
+	// target.
+	RefNotPermitted bool `json:",omitempty"`
+	// TargetExists is nil if the kind of the target is unknown, such that its
+	// existence cannot be checked.
+	TargetExists *bool                  `json:",omitempty"`
+	Spec         map[string]interface{} `json:",omitempty"`
+	// EffectiveSpec is the spec which the policy contributes to the resources
+	// below its target, i.e. the merged spec.default and spec.override of an
+	// inherited policy.
+	EffectiveSpec      map[string]interface{} `json:",omitempty"`
+	EffectiveSpecError string                 `json:",omitempty"`
+	// Summary is a human readable summary of the EffectiveSpec.
+	Summary string                 `json:",omitempty"`
+	Status  map[string]interface{} `json:",omitempty"`
+	// AffectedResources are the resources which inherit from the policy.
+	AffectedResources []affectedResourceView `json:",omitempty"`
+}
+
+type affectedResourceView struct {
+	Resource string `json:",omitempty"`
+	Gateway  string `json:",omitempty"`
+	// Shadowed is true if policies of higher precedence override all values
+	// of the policy for the resource.
+	Shadowed bool `json:",omitempty"`
+}
+
+func PrintDescribeView(ctx context.Context, params *types.Params, policies []policymanager.Policy) error {
+	return printDescribeView(ctx, params, policies, nil)
+}
+
+// Select returns the policies referenced by ref, which is one of:
+//
+//   - "<name>"
+//   - "<namespace>/<name>"
+//   - "<kind>/<name>"
+//   - "<kind>/<namespace>/<name>"
+//
+// where kind is the kind, plural or "<kind>.<group>" of a Policy CRD (matched
+// case-insensitively). A ref with two parts is "<kind>/<name>" if the first
+// part matches a Policy CRD, and "<namespace>/<name>" otherwise. Policies
+// within namespace are selected if the ref has no namespace, where an empty
+// namespace selects all namespaces. Cluster scoped policies are selected
+// regardless of the namespace.
+func Select(params *types.Params, ref, namespace string) []policymanager.Policy {
+	var kind, name string
+	parts := strings.Split(ref, "/")
+	switch len(parts) {
+	case 1:
+		name = parts[0]
+	case 2:
+		if len(matchingCRDs(params, parts[0])) != 0 {
+			kind, name = parts[0], parts[1]
+		} else {
+			namespace, name = parts[0], parts[1]
+		}
+	case 3:
+		kind, namespace, name = parts[0], parts[1], parts[2]
+	default:
+		return nil
+	}
+
+	var crdIDs map[policymanager.PolicyCrdID]bool
+	if kind != "" {
+		crdIDs = matchingCRDs(params, kind)
+	}
+
+	var result []policymanager.Policy
+	for _, policy := range params.PolicyManager.GetPolicies() {
+		u := policy.Unstructured()
+		if u.GetName() != name {
+			continue
+		}
+		if namespace != "" && u.GetNamespace() != "" && u.GetNamespace() != namespace {
+			continue
+		}
+		if crdIDs != nil && !crdIDs[policy.PolicyCrdID()] {
+			continue
+		}
+		result = append(result, policy)
+	}
+	return result
 }
 
-func PrintDescribeView(params *types.Params, policies []policymanager.Policy) {
+// Filter selects policies. Unset fields select all policies.
+type Filter struct {
+	// Namespace selects the policies within the namespace. Cluster scoped
+	// policies are selected regardless of the namespace.
+	Namespace string
+	// Kind selects the policies of the Policy CRDs whose kind, plural or
+	// "<kind>.<group>" matches case-insensitively.
+	Kind string
+	// TargetKind and TargetName select the policies by their targetRef. The
+	// kind matches case-insensitively.
+	TargetKind string
+	TargetName string
+	Labels     labels.Selector
+	// Fields selects the policies by the supportedFieldSelectors.
+	Fields fields.Selector
+}
+
+// supportedFieldSelectors are the fields by which policies can be selected.
+var supportedFieldSelectors = []string{
+	"metadata.name",
+	"metadata.namespace",
+	"kind",
+	"spec.targetRef.group",
+	"spec.targetRef.kind",
+	"spec.targetRef.name",
+	"spec.targetRef.namespace",
+}
+
+// ParseFieldSelector parses a field selector over the fields which are
+// supported for policies.
+func ParseFieldSelector(selector string) (fields.Selector, error) {
+	result, err := fields.ParseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	for _, requirement := range result.Requirements() {
+		if !slices.Contains(supportedFieldSelectors, requirement.Field) {
+			return nil, fmt.Errorf("field label %q not supported for policies, must be one of [%v]", requirement.Field, strings.Join(supportedFieldSelectors, ", "))
+		}
+	}
+	return result, nil
+}
+
+func policyFieldSet(policy policymanager.Policy) fields.Set {
+	targetRef := policy.TargetRef()
+	return fields.Set{
+		"metadata.name":            policy.Unstructured().GetName(),
+		"metadata.namespace":       policy.Unstructured().GetNamespace(),
+		"kind":                     policy.Unstructured().GetKind(),
+		"spec.targetRef.group":     targetRef.Group,
+		"spec.targetRef.kind":      targetRef.Kind,
+		"spec.targetRef.name":      targetRef.Name,
+		"spec.targetRef.namespace": targetRef.Namespace,
+	}
+}
+
+// Apply returns the policies selected by the Filter.
+func (f Filter) Apply(params *types.Params, policies []policymanager.Policy) []policymanager.Policy {
+	var crdIDs map[policymanager.PolicyCrdID]bool
+	if f.Kind != "" {
+		crdIDs = matchingCRDs(params, f.Kind)
+	}
+
+	var result []policymanager.Policy
+	for _, policy := range policies {
+		u := policy.Unstructured()
+		if f.Namespace != "" && u.GetNamespace() != "" && u.GetNamespace() != f.Namespace {
+			continue
+		}
+		if crdIDs != nil && !crdIDs[policy.PolicyCrdID()] {
+			continue
+		}
+		if f.TargetKind != "" && !strings.EqualFold(policy.TargetRef().Kind, f.TargetKind) {
+			continue
+		}
+		if f.TargetName != "" && policy.TargetRef().Name != f.TargetName {
+			continue
+		}
+		if f.Labels != nil && !f.Labels.Matches(labels.Set(u.GetLabels())) {
+			continue
+		}
+		if f.Fields != nil && !f.Fields.Matches(policyFieldSet(policy)) {
+			continue
+		}
+		result = append(result, policy)
+	}
+	return result
+}
+
+// matchingCRDs returns the IDs of the Policy CRDs whose kind, plural or
+// "<kind>.<group>" matches kind case-insensitively.
+func matchingCRDs(params *types.Params, kind string) map[policymanager.PolicyCrdID]bool {
+	result := make(map[policymanager.PolicyCrdID]bool)
+	for _, policyCRD := range params.PolicyManager.GetCRDs() {
+		crd := policyCRD.CRD()
+		for _, choice := range []string{crd.Spec.Names.Kind, crd.Spec.Names.Plural, string(policyCRD.ID())} {
+			if strings.EqualFold(choice, kind) {
+				result[policyCRD.ID()] = true
+			}
+		}
+	}
+	return result
+}
+
+// targetExists returns whether the object referenced by targetRef exists, or
+// nil if the kind of the object is unknown.
+func targetExists(ctx context.Context, params *types.Params, targetRef policymanager.ObjRef) (*bool, error) {
+	var obj client.Object
+	switch (schema.GroupKind{Group: targetRef.Group, Kind: targetRef.Kind}) {
+	case schema.GroupKind{Group: gatewayv1beta1.GroupName, Kind: "GatewayClass"}:
+		obj = &gatewayv1beta1.GatewayClass{}
+	case schema.GroupKind{Group: gatewayv1beta1.GroupName, Kind: "Gateway"}:
+		obj = &gatewayv1beta1.Gateway{}
+	case schema.GroupKind{Group: gatewayv1beta1.GroupName, Kind: "HTTPRoute"}:
+		obj = &gatewayv1beta1.HTTPRoute{}
+	case schema.GroupKind{Group: "", Kind: "Namespace"}:
+		obj = &corev1.Namespace{}
+	case schema.GroupKind{Group: "", Kind: "Service"}:
+		obj = &corev1.Service{}
+	default:
+		// Other kinds, like custom backends, are only checked if the cluster
+		// knows them.
+		mapping, err := params.Client.RESTMapper().RESTMapping(schema.GroupKind{Group: targetRef.Group, Kind: targetRef.Kind})
+		if err != nil {
+			if meta.IsNoMatchError(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		partial := &metav1.PartialObjectMetadata{}
+		partial.SetGroupVersionKind(mapping.GroupVersionKind)
+		obj = partial
+	}
+
+	err := params.Client.Get(ctx, client.ObjectKey{Namespace: targetRef.Namespace, Name: targetRef.Name}, obj)
+	if apierrors.IsNotFound(err) {
+		return common.PtrTo(false), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return common.PtrTo(true), nil
+}
+
+// PrintDescribeViewWithAffected is like PrintDescribeView, but additionally
+// prints the Gateways, HTTPRoutes and Backends affected by each policy, and
+// whether the policy is shadowed for them.
+func PrintDescribeViewWithAffected(ctx context.Context, params *types.Params, policies []policymanager.Policy) error {
+	baseline, err := whatif.NewBaseline(ctx, params)
+	if err != nil {
+		return err
+	}
+	return printDescribeView(ctx, params, policies, func(policy policymanager.Policy) ([]whatif.AffectedResource, error) {
+		return whatif.Affected(ctx, params, baseline, policy)
+	})
+}
+
+func printDescribeView(ctx context.Context, params *types.Params, policies []policymanager.Policy, affected func(policymanager.Policy) ([]whatif.AffectedResource, error)) error {
 	sort.Slice(policies, func(i, j int) bool {
 		a := fmt.Sprintf("%v/%v", policies[i].Unstructured().GetNamespace(), policies[i].Unstructured().GetName())
 		b := fmt.Sprintf("%v/%v", policies[j].Unstructured().GetNamespace(), policies[j].Unstructured().GetName())
@@ -88,11 +437,46 @@ func PrintDescribeView(params *types.Params, policies []policymanager.Policy) {
 				TargetRef: &targetRef,
 			},
 		}
+		if permitted, grant := params.PolicyManager.IsTargetRefPermitted(policy); !permitted {
+			views = append(views, describeView{
+				RefNotPermitted: true,
+			})
+		} else if grant != nil {
+			views = append(views, describeView{
+				ReferenceGrant: fmt.Sprintf("%v/%v", grant.GetNamespace(), grant.GetName()),
+			})
+		}
+
+		targetExists, err := targetExists(ctx, params, targetRef)
+		if err != nil {
+			return err
+		}
+		views = append(views, describeView{TargetExists: targetExists}, describeView{Spec: policy.Spec()})
+		if effectiveSpec, err := policy.EffectiveSpec(); err != nil {
+			views = append(views, describeView{EffectiveSpecError: err.Error()})
+		} else {
+			views = append(views, describeView{EffectiveSpec: effectiveSpec}, describeView{Summary: renderers.Summary(policy)})
+		}
+		if status, ok, _ := unstructured.NestedMap(policy.Unstructured().Object, "status"); ok {
+			views = append(views, describeView{Status: status})
+		}
+
+		if affected != nil {
+			affectedResources, err := affected(policy)
+			if err != nil {
+				return err
+			}
+			view := describeView{}
+			for _, resource := range affectedResources {
+				view.AffectedResources = append(view.AffectedResources, affectedResourceView(resource))
+			}
+			views = append(views, view)
+		}
 
 		for _, view := range views {
-			b, err := yaml.Marshal(view)
+			b, err := common.Marshal(params.Output, view)
 			if err != nil {
-				panic(err)
+				return err
 			}
 			fmt.Fprint(params.Out, string(b))
 		}
@@ -101,4 +485,5 @@ func PrintDescribeView(params *types.Params, policies []policymanager.Policy) {
 			fmt.Fprintf(params.Out, "\n\n")
 		}
 	}
+	return nil
 }
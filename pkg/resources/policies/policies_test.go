@@ -2,16 +2,22 @@ package policies
 
 import (
 	"bytes"
+	"context"
+	"sort"
 	"testing"
 
 	"github.com/gauravkghildiyal/gwctl/pkg/common"
+	"github.com/gauravkghildiyal/gwctl/pkg/policymanager"
 	"github.com/gauravkghildiyal/gwctl/pkg/types"
 	"github.com/google/go-cmp/cmp"
 
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
 func Test_Print_And_PrintDescribeView(t *testing.T) {
@@ -80,6 +86,20 @@ func Test_Print_And_PrintDescribeView(t *testing.T) {
 						"namespace": "default",
 					},
 				},
+				"status": map[string]interface{}{
+					"ancestors": []interface{}{
+						map[string]interface{}{
+							"ancestorRef":    map[string]interface{}{"name": "foo-gateway"},
+							"controllerName": "example.net/gateway-controller",
+						},
+					},
+				},
+			},
+		},
+		&gatewayv1beta1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-gateway",
+				Namespace: "default",
 			},
 		},
 
@@ -139,21 +159,40 @@ func Test_Print_And_PrintDescribeView(t *testing.T) {
 
 	params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
 
-	Print(params, params.PolicyManager.GetPolicies())
+	Print(params, params.PolicyManager.GetPolicies(), false)
 	got := params.Out.(*bytes.Buffer).String()
 	want := `
-POLICYNAME                 POLICYKIND         TARGETNAME        TARGETKIND
-health-check-gateway       HealthCheckPolicy  foo-gateway       Gateway
-health-check-gatewayclass  HealthCheckPolicy  foo-gatewayclass  GatewayClass
-timeout-policy-httproute   TimeoutPolicy      foo-httproute     HTTPRoute
-timeout-policy-namespace   TimeoutPolicy      default           Namespace
+POLICYNAME                 POLICYKIND         TARGETNAME        TARGETKIND    SUMMARY
+health-check-gateway       HealthCheckPolicy  foo-gateway       Gateway       key1=value-child-1 (override from Gateway); key2=value-child-2, key5=value-child-5 (default from Gateway)
+health-check-gatewayclass  HealthCheckPolicy  foo-gatewayclass  GatewayClass  key1=value-parent-1, key3=value-parent-3, key5=value-parent-5 (override from GatewayClass); key2=value-parent-2, key4=value-parent-4 (default from GatewayClass)
+timeout-policy-httproute   TimeoutPolicy      foo-httproute     HTTPRoute     timeout 60s, condition=path=/def (from HTTPRoute)
+timeout-policy-namespace   TimeoutPolicy      default           Namespace     timeout 30s, condition=path=/abc (from Namespace)
 `
 	if diff := cmp.Diff(common.YamlString(want), common.YamlString(got), common.YamlStringTransformer); diff != "" {
 		t.Errorf("Print: Unexpected diff\ngot=\n%v\nwant=\n%v\ndiff (-want +got)=\n%v", got, want, diff)
 	}
 
+	// The columns of the config file select and order the columns.
 	params.Out = &bytes.Buffer{}
-	PrintDescribeView(params, params.PolicyManager.GetPolicies())
+	params.Config.Columns = map[string][]string{"policies": {"targetKind", "POLICYNAME"}}
+	Print(params, params.PolicyManager.GetPolicies(), false)
+	got = params.Out.(*bytes.Buffer).String()
+	want = `
+TARGETKIND    POLICYNAME
+Gateway       health-check-gateway
+GatewayClass  health-check-gatewayclass
+HTTPRoute     timeout-policy-httproute
+Namespace     timeout-policy-namespace
+`
+	if diff := cmp.Diff(common.YamlString(want), common.YamlString(got), common.YamlStringTransformer); diff != "" {
+		t.Errorf("Print with columns: Unexpected diff\ngot=\n%v\nwant=\n%v\ndiff (-want +got)=\n%v", got, want, diff)
+	}
+	params.Config.Columns = nil
+
+	params.Out = &bytes.Buffer{}
+	if err := PrintDescribeView(context.Background(), params, params.PolicyManager.GetPolicies()); err != nil {
+		t.Fatalf("PrintDescribeView() failed: %v", err)
+	}
 	got = params.Out.(*bytes.Buffer).String()
 	want = `
 Name: health-check-gateway
@@ -164,6 +203,29 @@ TargetRef:
   Kind: Gateway
   Name: foo-gateway
   Namespace: default
+TargetExists: true
+Spec:
+  default:
+    key2: value-child-2
+    key5: value-child-5
+  override:
+    key1: value-child-1
+  targetRef:
+    group: gateway.networking.k8s.io
+    kind: Gateway
+    name: foo-gateway
+    namespace: default
+EffectiveSpec:
+  key1: value-child-1
+  key2: value-child-2
+  key5: value-child-5
+Summary: key1=value-child-1 (override from Gateway); key2=value-child-2, key5=value-child-5
+  (default from Gateway)
+Status:
+  ancestors:
+  - ancestorRef:
+      name: foo-gateway
+    controllerName: example.net/gateway-controller
 
 
 Name: health-check-gatewayclass
@@ -173,6 +235,27 @@ TargetRef:
   Group: gateway.networking.k8s.io
   Kind: GatewayClass
   Name: foo-gatewayclass
+TargetExists: false
+Spec:
+  default:
+    key2: value-parent-2
+    key4: value-parent-4
+  override:
+    key1: value-parent-1
+    key3: value-parent-3
+    key5: value-parent-5
+  targetRef:
+    group: gateway.networking.k8s.io
+    kind: GatewayClass
+    name: foo-gatewayclass
+EffectiveSpec:
+  key1: value-parent-1
+  key2: value-parent-2
+  key3: value-parent-3
+  key4: value-parent-4
+  key5: value-parent-5
+Summary: key1=value-parent-1, key3=value-parent-3, key5=value-parent-5 (override from
+  GatewayClass); key2=value-parent-2, key4=value-parent-4 (default from GatewayClass)
 
 
 Name: timeout-policy-httproute
@@ -182,6 +265,18 @@ TargetRef:
   Group: gateway.networking.k8s.io
   Kind: HTTPRoute
   Name: foo-httproute
+TargetExists: false
+Spec:
+  condition: path=/def
+  seconds: 60
+  targetRef:
+    group: gateway.networking.k8s.io
+    kind: HTTPRoute
+    name: foo-httproute
+EffectiveSpec:
+  condition: path=/def
+  seconds: 60
+Summary: timeout 60s, condition=path=/def (from HTTPRoute)
 
 
 Name: timeout-policy-namespace
@@ -190,12 +285,254 @@ Kind: TimeoutPolicy
 TargetRef:
   Kind: Namespace
   Name: default
+TargetExists: false
+Spec:
+  condition: path=/abc
+  seconds: 30
+  targetRef:
+    kind: Namespace
+    name: default
+EffectiveSpec:
+  condition: path=/abc
+  seconds: 30
+Summary: timeout 30s, condition=path=/abc (from Namespace)
 `
 	if diff := cmp.Diff(common.YamlString(want), common.YamlString(got), common.YamlStringTransformer); diff != "" {
 		t.Errorf("PrintDescribeView: Unexpected diff\ngot=\n%v\nwant=\n%v\ndiff (-want +got)=\n%v", got, want, diff)
 	}
 }
 
+func TestSelect(t *testing.T) {
+	policy := func(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+		metadata := map[string]interface{}{"name": name}
+		if namespace != "" {
+			metadata["namespace"] = namespace
+		}
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": apiVersion,
+				"kind":       kind,
+				"metadata":   metadata,
+				"spec": map[string]interface{}{
+					"targetRef": map[string]interface{}{"kind": "Namespace", "name": "default"},
+				},
+			},
+		}
+	}
+	policyCRD := func(group, kind, plural string, scope apiextensionsv1.ResourceScope) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   plural + "." + group,
+				Labels: map[string]string{common.GatewayPolicyLabelKey: "direct"},
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Scope:    scope,
+				Group:    group,
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1"}},
+				Names:    apiextensionsv1.CustomResourceDefinitionNames{Plural: plural, Kind: kind},
+			},
+		}
+	}
+
+	objects := []runtime.Object{
+		policyCRD("foo.com", "HealthCheckPolicy", "healthcheckpolicies", apiextensionsv1.ClusterScoped),
+		policy("foo.com/v1", "HealthCheckPolicy", "", "common"),
+		policyCRD("bar.com", "TimeoutPolicy", "timeoutpolicies", apiextensionsv1.NamespaceScoped),
+		policy("bar.com/v1", "TimeoutPolicy", "default", "common"),
+		policy("bar.com/v1", "TimeoutPolicy", "ns2", "common"),
+		policy("bar.com/v1", "TimeoutPolicy", "ns2", "timeout"),
+	}
+	params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
+
+	testCases := []struct {
+		ref       string
+		namespace string
+		want      []string
+	}{
+		{ref: "common", namespace: "default", want: []string{"/common", "default/common"}},
+		{ref: "common", namespace: "", want: []string{"/common", "default/common", "ns2/common"}},
+		{ref: "ns2/common", namespace: "default", want: []string{"/common", "ns2/common"}},
+		{ref: "timeoutpolicy/common", namespace: "ns2", want: []string{"ns2/common"}},
+		{ref: "timeoutpolicies/common", namespace: "", want: []string{"default/common", "ns2/common"}},
+		{ref: "HealthCheckPolicy.foo.com/common", namespace: "default", want: []string{"/common"}},
+		{ref: "TimeoutPolicy/ns2/timeout", namespace: "default", want: []string{"ns2/timeout"}},
+		{ref: "timeout", namespace: "default"},
+		{ref: "a/b/c/d", namespace: "default"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.ref, func(t *testing.T) {
+			var got []string
+			for _, policy := range Select(params, tc.ref, tc.namespace) {
+				got = append(got, policy.Unstructured().GetNamespace()+"/"+policy.Unstructured().GetName())
+			}
+			sort.Strings(got)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Select(%q, %q) returned unexpected diff (-want +got):\n%v", tc.ref, tc.namespace, diff)
+			}
+		})
+	}
+}
+
+func TestTable_AllNamespaces(t *testing.T) {
+	objects := []runtime.Object{
+		&apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "timeoutpolicies.bar.com",
+				Labels: map[string]string{common.GatewayPolicyLabelKey: "direct"},
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Scope:    apiextensionsv1.NamespaceScoped,
+				Group:    "bar.com",
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1"}},
+				Names:    apiextensionsv1.CustomResourceDefinitionNames{Plural: "timeoutpolicies", Kind: "TimeoutPolicy"},
+			},
+		},
+	}
+	for _, namespace := range []string{"ns2", "default"} {
+		objects = append(objects, &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "bar.com/v1",
+				"kind":       "TimeoutPolicy",
+				"metadata":   map[string]interface{}{"name": "timeout", "namespace": namespace},
+				"spec": map[string]interface{}{
+					"targetRef": map[string]interface{}{"kind": "Namespace", "name": namespace},
+				},
+			},
+		})
+	}
+	params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
+
+	params.Config.Columns = map[string][]string{"policies": {"NAMESPACE", "POLICYNAME", "TARGETNAME"}}
+	Print(params, params.PolicyManager.GetPolicies(), true)
+	got := params.Out.(*bytes.Buffer).String()
+	want := `
+NAMESPACE  POLICYNAME  TARGETNAME
+default    timeout     default
+ns2        timeout     ns2
+`
+	if diff := cmp.Diff(common.YamlString(want), common.YamlString(got), common.YamlStringTransformer); diff != "" {
+		t.Errorf("Print: Unexpected diff\ngot=\n%v\nwant=\n%v\ndiff (-want +got)=\n%v", got, want, diff)
+	}
+
+	if header, _ := Table(params.PolicyManager.GetPolicies(), false); header[0] != "POLICYNAME" {
+		t.Errorf("Table() within a namespace returned header %v; want no NAMESPACE column", header)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	policy := func(apiVersion, kind, namespace, name string, policyLabels map[string]interface{}, targetRef map[string]interface{}) *unstructured.Unstructured {
+		metadata := map[string]interface{}{"name": name, "labels": policyLabels}
+		if namespace != "" {
+			metadata["namespace"] = namespace
+		}
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": apiVersion,
+				"kind":       kind,
+				"metadata":   metadata,
+				"spec":       map[string]interface{}{"targetRef": targetRef},
+			},
+		}
+	}
+	policyCRD := func(group, kind, plural string, scope apiextensionsv1.ResourceScope) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   plural + "." + group,
+				Labels: map[string]string{common.GatewayPolicyLabelKey: "direct"},
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Scope:    scope,
+				Group:    group,
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1"}},
+				Names:    apiextensionsv1.CustomResourceDefinitionNames{Plural: plural, Kind: kind},
+			},
+		}
+	}
+	gatewayRef := map[string]interface{}{"group": "gateway.networking.k8s.io", "kind": "Gateway", "name": "foo-gateway"}
+
+	objects := []runtime.Object{
+		policyCRD("foo.com", "HealthCheckPolicy", "healthcheckpolicies", apiextensionsv1.ClusterScoped),
+		policy("foo.com/v1", "HealthCheckPolicy", "", "health-check-namespace", map[string]interface{}{"team": "a"}, map[string]interface{}{"kind": "Namespace", "name": "default"}),
+		policyCRD("bar.com", "TimeoutPolicy", "timeoutpolicies", apiextensionsv1.NamespaceScoped),
+		policy("bar.com/v1", "TimeoutPolicy", "default", "timeout-gateway", map[string]interface{}{"team": "a"}, gatewayRef),
+		policy("bar.com/v1", "TimeoutPolicy", "ns2", "timeout-gateway", map[string]interface{}{"team": "b"}, gatewayRef),
+		policy("bar.com/v1", "TimeoutPolicy", "ns2", "timeout-httproute", nil, map[string]interface{}{"group": "gateway.networking.k8s.io", "kind": "HTTPRoute", "name": "foo-httproute"}),
+	}
+	params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
+
+	mustParseLabels := func(selector string) labels.Selector {
+		result, err := labels.Parse(selector)
+		if err != nil {
+			t.Fatalf("labels.Parse(%q) failed: %v", selector, err)
+		}
+		return result
+	}
+	mustParseFields := func(selector string) fields.Selector {
+		result, err := ParseFieldSelector(selector)
+		if err != nil {
+			t.Fatalf("ParseFieldSelector(%q) failed: %v", selector, err)
+		}
+		return result
+	}
+
+	testCases := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{
+			name:   "no filter",
+			filter: Filter{},
+			want:   []string{"/health-check-namespace", "default/timeout-gateway", "ns2/timeout-gateway", "ns2/timeout-httproute"},
+		},
+		{
+			name:   "namespace includes cluster scoped policies",
+			filter: Filter{Namespace: "ns2"},
+			want:   []string{"/health-check-namespace", "ns2/timeout-gateway", "ns2/timeout-httproute"},
+		},
+		{
+			name:   "kind",
+			filter: Filter{Kind: "timeoutpolicies"},
+			want:   []string{"default/timeout-gateway", "ns2/timeout-gateway", "ns2/timeout-httproute"},
+		},
+		{
+			name:   "target kind and name",
+			filter: Filter{TargetKind: "gateway", TargetName: "foo-gateway"},
+			want:   []string{"default/timeout-gateway", "ns2/timeout-gateway"},
+		},
+		{
+			name:   "label selector",
+			filter: Filter{Labels: mustParseLabels("team in (a)")},
+			want:   []string{"/health-check-namespace", "default/timeout-gateway"},
+		},
+		{
+			name:   "field selector",
+			filter: Filter{Fields: mustParseFields("spec.targetRef.kind!=Gateway,metadata.namespace=ns2")},
+			want:   []string{"ns2/timeout-httproute"},
+		},
+		{
+			name:   "unknown kind",
+			filter: Filter{Kind: "RetryPolicy"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, policy := range tc.filter.Apply(params, params.PolicyManager.GetPolicies()) {
+				got = append(got, policy.Unstructured().GetNamespace()+"/"+policy.Unstructured().GetName())
+			}
+			sort.Strings(got)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Apply() returned unexpected diff (-want +got):\n%v", diff)
+			}
+		})
+	}
+
+	if _, err := ParseFieldSelector("spec.seconds=30"); err == nil {
+		t.Errorf("ParseFieldSelector() with an unsupported field succeeded; want error")
+	}
+}
+
 func TestPrintCRDs(t *testing.T) {
 	objects := []runtime.Object{
 		&apiextensionsv1.CustomResourceDefinition{
@@ -246,11 +583,15 @@ func TestPrintCRDs(t *testing.T) {
 				Labels: map[string]string{
 					common.GatewayPolicyLabelKey: "direct",
 				},
+				Annotations: map[string]string{
+					policymanager.MergeStrategyAnnotation: "strategic",
+					policymanager.MergeListKeysAnnotation: "rules=name",
+				},
 			},
 			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
 				Scope:    apiextensionsv1.ClusterScoped,
 				Group:    "bar.com",
-				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1"}},
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1", Served: true}},
 				Names: apiextensionsv1.CustomResourceDefinitionNames{
 					Plural: "timeoutpolicies",
 					Kind:   "TimeoutPolicy",
@@ -281,11 +622,96 @@ func TestPrintCRDs(t *testing.T) {
 
 	got := params.Out.(*bytes.Buffer).String()
 	want := `
-CRD_NAME                     CRD_GROUP  CRD_KIND           CRD_INHERITED  CRD_SCOPE
-healthcheckpolicies.foo.com  foo.com    HealthCheckPolicy  true           Cluster
-timeoutpolicies.bar.com      bar.com    TimeoutPolicy      false          Cluster
+CRD_NAME                     CRD_GROUP  CRD_KIND           CRD_INHERITED  CRD_SCOPE  CRD_SCHEMA         CRD_MERGE_STRATEGY
+healthcheckpolicies.foo.com  foo.com    HealthCheckPolicy  true           Cluster    Valid              json-merge
+timeoutpolicies.bar.com      bar.com    TimeoutPolicy      false          Cluster    Valid (1 warning)  strategic(rules=name)
+`
+	if diff := cmp.Diff(common.YamlString(want), common.YamlString(got), common.YamlStringTransformer); diff != "" {
+		t.Errorf("Unexpected diff\ngot=\n%v\nwant=\n%v\ndiff (-want +got)=\n%v", got, want, diff)
+	}
+
+	params.Out.(*bytes.Buffer).Reset()
+	PrintCRDValidation(params, params.PolicyManager.GetCRDs())
+
+	got = params.Out.(*bytes.Buffer).String()
+	want = `
+- Name: healthcheckpolicies.foo.com
+  Valid: true
+- Name: timeoutpolicies.bar.com
+  Valid: true
+  Warnings:
+  - 'v1: no schema, so the conventions cannot be verified'
 `
 	if diff := cmp.Diff(common.YamlString(want), common.YamlString(got), common.YamlStringTransformer); diff != "" {
 		t.Errorf("Unexpected diff\ngot=\n%v\nwant=\n%v\ndiff (-want +got)=\n%v", got, want, diff)
 	}
 }
+
+// FuzzPrintDescribeView's bits set the targetRef, default, override and scope.
+func FuzzPrintDescribeView(f *testing.F) {
+	seeds := []common.FuzzSeed{
+		{Mask: 0xff, Value: "ns1"},
+	}
+	common.FuzzObjects(f, seeds, func(t *testing.T, isSet func(bit int) bool, value string) {
+		targetRef := map[string]interface{}{
+			"kind": "Gateway",
+			"name": "foo-gateway",
+		}
+		if isSet(0) {
+			targetRef["group"] = "gateway.networking.k8s.io"
+		}
+		if isSet(1) {
+			targetRef["namespace"] = value
+		}
+		spec := map[string]interface{}{
+			"targetRef": targetRef,
+		}
+		if isSet(2) {
+			spec["default"] = map[string]interface{}{"key": value}
+		}
+		if isSet(3) {
+			spec["override"] = map[string]interface{}{"key": value}
+		}
+		metadata := map[string]interface{}{
+			"name": "health-check",
+		}
+		scope := apiextensionsv1.ClusterScoped
+		if isSet(4) {
+			metadata["namespace"] = "default"
+			scope = apiextensionsv1.NamespaceScoped
+		}
+
+		objects := []runtime.Object{
+			&apiextensionsv1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "healthcheckpolicies.foo.com",
+					Labels: map[string]string{
+						common.GatewayPolicyLabelKey: "inherited",
+					},
+				},
+				Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+					Scope:    scope,
+					Group:    "foo.com",
+					Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1"}},
+					Names: apiextensionsv1.CustomResourceDefinitionNames{
+						Plural: "healthcheckpolicies",
+						Kind:   "HealthCheckPolicy",
+					},
+				},
+			},
+			&unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": "foo.com/v1",
+					"kind":       "HealthCheckPolicy",
+					"metadata":   metadata,
+					"spec":       spec,
+				},
+			},
+		}
+
+		params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
+		if err := PrintDescribeView(context.Background(), params, params.PolicyManager.GetPolicies()); err != nil {
+			t.Fatalf("PrintDescribeView() failed: %v", err)
+		}
+	})
+}
@@ -0,0 +1,229 @@
+// Package snapshot captures the Gateway API objects and policies of a cluster
+// along with the effective policies computed from them, such that the
+// effective policies can be compared across time (like before and after a
+// policy rollout) or across environments.
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+	"github.com/gauravkghildiyal/gwctl/pkg/policymanager"
+	"github.com/gauravkghildiyal/gwctl/pkg/resources/gatewayclasses"
+	"github.com/gauravkghildiyal/gwctl/pkg/resources/gateways"
+	"github.com/gauravkghildiyal/gwctl/pkg/resources/httproutes"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+	"github.com/gauravkghildiyal/gwctl/pkg/whatif"
+)
+
+type Snapshot struct {
+	// Time is when the snapshot was taken.
+	Time            metav1.Time                                `json:""`
+	GatewayClasses  []gatewayv1beta1.GatewayClass              `json:",omitempty"`
+	Gateways        []gatewayv1beta1.Gateway                   `json:",omitempty"`
+	HTTPRoutes      []gatewayv1beta1.HTTPRoute                 `json:",omitempty"`
+	ReferenceGrants []gatewayv1beta1.ReferenceGrant            `json:",omitempty"`
+	PolicyCRDs      []apiextensionsv1.CustomResourceDefinition `json:",omitempty"`
+	Policies        []unstructured.Unstructured                `json:",omitempty"`
+	// EffectivePolicies are computed from the objects above when the snapshot
+	// is taken, so comparing snapshots does not depend on how a later version
+	// of gwctl (or a different config) would merge the policies.
+	EffectivePolicies []whatif.EffectivePolicy `json:",omitempty"`
+}
+
+// Take captures the objects known to params along with their effective
+// policies. The objects are sorted, such that snapshots of the same state are
+// identical apart from their Time.
+func Take(ctx context.Context, params *types.Params) (Snapshot, error) {
+	result := Snapshot{Time: metav1.Now()}
+
+	var err error
+	if result.GatewayClasses, err = gatewayclasses.List(ctx, params); err != nil {
+		return Snapshot{}, err
+	}
+	sort.Slice(result.GatewayClasses, func(i, j int) bool {
+		return result.GatewayClasses[i].Name < result.GatewayClasses[j].Name
+	})
+	if result.Gateways, err = gateways.List(ctx, params, ""); err != nil {
+		return Snapshot{}, err
+	}
+	sort.Slice(result.Gateways, func(i, j int) bool {
+		return less(&result.Gateways[i], &result.Gateways[j])
+	})
+	if result.HTTPRoutes, err = httproutes.List(ctx, params, ""); err != nil {
+		return Snapshot{}, err
+	}
+	sort.Slice(result.HTTPRoutes, func(i, j int) bool {
+		return less(&result.HTTPRoutes[i], &result.HTTPRoutes[j])
+	})
+	result.ReferenceGrants = append(result.ReferenceGrants, params.PolicyManager.ReferenceGrants()...)
+	sort.Slice(result.ReferenceGrants, func(i, j int) bool {
+		return less(&result.ReferenceGrants[i], &result.ReferenceGrants[j])
+	})
+
+	for _, policyCRD := range params.PolicyManager.GetCRDs() {
+		result.PolicyCRDs = append(result.PolicyCRDs, *policyCRD.CRD())
+	}
+	sort.Slice(result.PolicyCRDs, func(i, j int) bool {
+		return result.PolicyCRDs[i].Name < result.PolicyCRDs[j].Name
+	})
+	for _, policy := range params.PolicyManager.GetPolicies() {
+		result.Policies = append(result.Policies, *policy.Unstructured())
+	}
+	sort.Slice(result.Policies, func(i, j int) bool {
+		a, b := result.Policies[i], result.Policies[j]
+		if a.GetKind() != b.GetKind() {
+			return a.GetKind() < b.GetKind()
+		}
+		return less(&a, &b)
+	})
+
+	if result.EffectivePolicies, err = whatif.EffectivePolicies(ctx, params); err != nil {
+		return Snapshot{}, err
+	}
+	return result, nil
+}
+
+func less(a, b metav1.Object) bool {
+	if a.GetNamespace() != b.GetNamespace() {
+		return a.GetNamespace() < b.GetNamespace()
+	}
+	return a.GetName() < b.GetName()
+}
+
+// Write writes the snapshot in the output format, see common.Marshal.
+func Write(w io.Writer, snapshot Snapshot, format string) error {
+	b, err := common.Marshal(format, snapshot)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// Read reads a snapshot written by Write, in either format.
+func Read(r io.Reader) (Snapshot, error) {
+	var result Snapshot
+	if err := utilyaml.NewYAMLOrJSONDecoder(r, 4096).Decode(&result); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to decode snapshot: %v", err)
+	}
+	return result, nil
+}
+
+// Change is the change in the effective policy of a single policy kind for a
+// resource between two snapshots.
+type Change struct {
+	whatif.Diff
+	// Fields are the fields of the effective spec which changed, sorted by
+	// their path.
+	Fields []FieldChange
+}
+
+// FieldChange is the change of a single field of an effective spec. A field
+// which is added (or removed) has a nil Before (or After).
+type FieldChange struct {
+	// Path is the path of the field within the effective spec, like
+	// "retry.attempts".
+	Path   string
+	Before interface{}
+	After  interface{}
+}
+
+// Compare returns how the effective policies changed from before to after,
+// sorted by resource, Gateway and policy kind.
+func Compare(before, after Snapshot) []Change {
+	var result []Change
+	for _, diff := range whatif.DiffEffectivePolicies(before.EffectivePolicies, after.EffectivePolicies) {
+		result = append(result, Change{Diff: diff, Fields: changedFields("", diff.Before, diff.After)})
+	}
+	return result
+}
+
+// changedFields returns the fields which differ between before and after.
+// Maps are compared recursively, while other values (including lists) are
+// compared as a whole.
+func changedFields(prefix string, before, after map[string]interface{}) []FieldChange {
+	var result []FieldChange
+	fields := make(map[string]bool)
+	for field := range before {
+		fields[field] = true
+	}
+	for field := range after {
+		fields[field] = true
+	}
+	for field := range fields {
+		path := prefix + field
+		beforeMap, beforeIsMap := before[field].(map[string]interface{})
+		afterMap, afterIsMap := after[field].(map[string]interface{})
+		switch {
+		case beforeIsMap && afterIsMap:
+			result = append(result, changedFields(path+".", beforeMap, afterMap)...)
+		case !reflect.DeepEqual(before[field], after[field]):
+			result = append(result, FieldChange{Path: path, Before: before[field], After: after[field]})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
+
+type resourceView struct {
+	Resource string             `json:",omitempty"`
+	Changes  []policyChangeView `json:",omitempty"`
+}
+
+type policyChangeView struct {
+	Gateway string                    `json:",omitempty"`
+	Policy  policymanager.PolicyCrdID `json:",omitempty"`
+	Fields  []fieldChangeView         `json:",omitempty"`
+}
+
+type fieldChangeView struct {
+	Field  string      `json:",omitempty"`
+	Before interface{} `json:",omitempty"`
+	After  interface{} `json:",omitempty"`
+}
+
+// Print prints the changes grouped by resource, with the values of each
+// changed field before and after.
+func Print(params *types.Params, changes []Change) {
+	// Like whatif.Print, JSON is printed as an empty list instead.
+	if len(changes) == 0 && params.Output != "json" {
+		fmt.Fprintln(params.Out, "No effective policies changed")
+		return
+	}
+
+	views := []resourceView{}
+	for _, change := range changes {
+		if len(views) == 0 || views[len(views)-1].Resource != change.Resource {
+			views = append(views, resourceView{Resource: change.Resource})
+		}
+		view := &views[len(views)-1]
+		policyView := policyChangeView{Gateway: change.Gateway, Policy: change.PolicyCrdID}
+		for _, field := range change.Fields {
+			policyView.Fields = append(policyView.Fields, fieldChangeView{
+				Field:  field.Path,
+				Before: field.Before,
+				After:  field.After,
+			})
+		}
+		view.Changes = append(view.Changes, policyView)
+	}
+
+	b, err := common.Marshal(params.Output, views)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Fprint(params.Out, string(b))
+}
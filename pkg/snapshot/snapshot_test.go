@@ -0,0 +1,199 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+)
+
+func TestTakeAndCompare(t *testing.T) {
+	healthCheckPolicy := func(interval int64, key1 string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "foo.com/v1",
+				"kind":       "HealthCheckPolicy",
+				"metadata": map[string]interface{}{
+					"name": "health-check-gateway",
+				},
+				"spec": map[string]interface{}{
+					"default": map[string]interface{}{
+						"key1":  key1,
+						"probe": map[string]interface{}{"interval": interval, "path": "/healthz"},
+					},
+					"targetRef": map[string]interface{}{
+						"group":     "gateway.networking.k8s.io",
+						"kind":      "Gateway",
+						"name":      "foo-gateway",
+						"namespace": "default",
+					},
+				},
+			},
+		}
+	}
+	timeoutPolicy := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "bar.com/v1",
+			"kind":       "TimeoutPolicy",
+			"metadata": map[string]interface{}{
+				"name":      "timeout-httproute",
+				"namespace": "default",
+			},
+			"spec": map[string]interface{}{
+				"seconds": int64(30),
+				"targetRef": map[string]interface{}{
+					"group": "gateway.networking.k8s.io",
+					"kind":  "HTTPRoute",
+					"name":  "foo-httproute",
+				},
+			},
+		},
+	}
+	objects := []runtime.Object{
+		&gatewayv1beta1.GatewayClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "foo-gatewayclass",
+			},
+			Spec: gatewayv1beta1.GatewayClassSpec{
+				ControllerName: "example.net/gateway-controller",
+			},
+		},
+		&gatewayv1beta1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-gateway",
+				Namespace: "default",
+			},
+			Spec: gatewayv1beta1.GatewaySpec{
+				GatewayClassName: "foo-gatewayclass",
+				Listeners:        []gatewayv1beta1.Listener{{Name: "http", Port: 80, Protocol: gatewayv1beta1.HTTPProtocolType}},
+			},
+		},
+		&gatewayv1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-httproute",
+				Namespace: "default",
+			},
+			Spec: gatewayv1beta1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
+					ParentRefs: []gatewayv1beta1.ParentReference{{Name: "foo-gateway"}},
+				},
+			},
+		},
+
+		&apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "healthcheckpolicies.foo.com",
+				Labels: map[string]string{
+					common.GatewayPolicyLabelKey: "inherited",
+				},
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Scope:    apiextensionsv1.ClusterScoped,
+				Group:    "foo.com",
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1"}},
+				Names: apiextensionsv1.CustomResourceDefinitionNames{
+					Plural: "healthcheckpolicies",
+					Kind:   "HealthCheckPolicy",
+				},
+			},
+		},
+		healthCheckPolicy(10, "value-1"),
+
+		&apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "timeoutpolicies.bar.com",
+				Labels: map[string]string{
+					common.GatewayPolicyLabelKey: "direct",
+				},
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Scope:    apiextensionsv1.NamespaceScoped,
+				Group:    "bar.com",
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1"}},
+				Names: apiextensionsv1.CustomResourceDefinitionNames{
+					Plural: "timeoutpolicies",
+					Kind:   "TimeoutPolicy",
+				},
+			},
+		},
+		timeoutPolicy,
+	}
+
+	params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
+
+	before, err := Take(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Take() failed: %v", err)
+	}
+	if len(before.GatewayClasses) != 1 || len(before.Gateways) != 1 || len(before.HTTPRoutes) != 1 || len(before.PolicyCRDs) != 2 || len(before.Policies) != 2 {
+		t.Errorf("Take() did not capture all objects: %+v", before)
+	}
+
+	// A snapshot which is written and read back compares equal to the
+	// original.
+	before.Time = metav1.NewTime(time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC))
+	var read Snapshot
+	for _, format := range common.OutputFormats {
+		var buf bytes.Buffer
+		if err := Write(&buf, before, format); err != nil {
+			t.Fatalf("Write(%v) failed: %v", format, err)
+		}
+		read, err = Read(&buf)
+		if err != nil {
+			t.Fatalf("Read(%v) failed: %v", format, err)
+		}
+		if diff := cmp.Diff(before, read); diff != "" {
+			t.Errorf("Read(%v) returned unexpected diff (-want +got)=\n%v", format, diff)
+		}
+	}
+	if changes := Compare(read, before); len(changes) != 0 {
+		t.Errorf("Compare() of identical snapshots = %v; want no changes", changes)
+	}
+
+	// Roll out a change to the HealthCheckPolicy and remove the TimeoutPolicy.
+	if err := params.PolicyManager.AddPolicy(*healthCheckPolicy(5, "value-1")); err != nil {
+		t.Fatalf("AddPolicy() failed: %v", err)
+	}
+	params.PolicyManager.RemovePolicy(*timeoutPolicy)
+	after, err := Take(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Take() failed: %v", err)
+	}
+
+	Print(params, Compare(read, after))
+	got := params.Out.(*bytes.Buffer).String()
+	want := `
+- Changes:
+  - Fields:
+    - After: 5
+      Before: 10
+      Field: probe.interval
+    Policy: HealthCheckPolicy.foo.com
+  Resource: Gateway/default/foo-gateway
+- Changes:
+  - Fields:
+    - Before: 30
+      Field: seconds
+    Policy: TimeoutPolicy.bar.com
+  - Fields:
+    - After: 5
+      Before: 10
+      Field: probe.interval
+    Gateway: default/foo-gateway
+    Policy: HealthCheckPolicy.foo.com
+  Resource: HTTPRoute/default/foo-httproute
+`
+	if diff := cmp.Diff(common.YamlString(want), common.YamlString(got), common.YamlStringTransformer); diff != "" {
+		t.Errorf("Print: Unexpected diff\ngot=\n%v\nwant=\n%v\ndiff (-want +got)=\n%v", got, want, diff)
+	}
+}
@@ -11,24 +11,47 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/gauravkghildiyal/gwctl/pkg/common"
+	"github.com/gauravkghildiyal/gwctl/pkg/config"
+	"github.com/gauravkghildiyal/gwctl/pkg/defaults"
+	"github.com/gauravkghildiyal/gwctl/pkg/fetch"
 	"github.com/gauravkghildiyal/gwctl/pkg/policymanager"
 )
 
 type Params struct {
+	// Client reads every object at most once for the invocation of gwctl, see
+	// fetch.NewClient.
 	Client          client.Client
 	DC              dynamic.Interface
 	DiscoveryClient discovery.DiscoveryInterface
 	PolicyManager   *policymanager.PolicyManager
 	Out             io.Writer
+	// Output is the format of the commands which print YAML or JSON, see
+	// common.Marshal. It is set from the --output flag of such commands.
+	Output string
+	// Config holds the defaults of the user from the config file.
+	Config config.Config
+	// Clusters loads the Params of other kubeconfig contexts, for the commands
+	// which run across clusters.
+	Clusters ClusterLoader
+}
+
+// ClusterLoader loads the Params of the contexts of the kubeconfig.
+type ClusterLoader interface {
+	// Contexts returns the names of all contexts, sorted.
+	Contexts() ([]string, error)
+	// Load returns the Params of the context, or of the current context if
+	// name is empty, whose PolicyManager is initialized.
+	Load(ctx context.Context, name string) (*Params, error)
 }
 
 func MustParamsForTest(t *testing.T, fakeClients *common.FakeClients) *Params {
-	policyManager := policymanager.New(fakeClients.DC)
+	c := fetch.NewClient(defaults.NewClient(fakeClients.Client))
+	policyManager := policymanager.New(fakeClients.DC, c)
 	if err := policyManager.Init(context.Background()); err != nil {
 		t.Fatalf("failed to initialize PolicyManager: %v", err)
 	}
 	return &Params{
-		Client:          fakeClients.Client,
+		Client:          c,
 		DC:              fakeClients.DC,
 		DiscoveryClient: fakeClients.DiscoveryClient,
 		PolicyManager:   policyManager,
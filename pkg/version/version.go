@@ -0,0 +1,88 @@
+// Package version provides the build information of gwctl.
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// These are set at build time through ldflags, like:
+//
+//	go build -ldflags "-X github.com/gauravkghildiyal/gwctl/pkg/version.gitVersion=v0.1.0" ...
+//
+// Unset values are filled in from the build information embedded by the Go
+// toolchain where possible.
+var (
+	gitVersion = ""
+	gitCommit  = ""
+	buildDate  = ""
+)
+
+const gatewayAPIModulePath = "sigs.k8s.io/gateway-api"
+
+// Info describes the build of gwctl.
+type Info struct {
+	GitVersion string `json:",omitempty"`
+	GitCommit  string `json:",omitempty"`
+	BuildDate  string `json:",omitempty"`
+	GoVersion  string `json:",omitempty"`
+	// GatewayAPIModuleVersion is the version of the sigs.k8s.io/gateway-api
+	// module compiled into gwctl.
+	GatewayAPIModuleVersion string `json:",omitempty"`
+}
+
+// Get returns the build information of the running gwctl binary.
+func Get() Info {
+	info := Info{
+		GitVersion: gitVersion,
+		GitCommit:  gitCommit,
+		BuildDate:  buildDate,
+		GoVersion:  runtime.Version(),
+	}
+
+	if buildInfo, ok := debug.ReadBuildInfo(); ok {
+		fillFromBuildInfo(&info, buildInfo)
+	}
+	if info.GitVersion == "" {
+		info.GitVersion = "devel"
+	}
+	return info
+}
+
+// fillFromBuildInfo sets the fields of info which were not set through ldflags
+// from the build information embedded by the Go toolchain.
+func fillFromBuildInfo(info *Info, buildInfo *debug.BuildInfo) {
+	if info.GitVersion == "" && buildInfo.Main.Version != "" && buildInfo.Main.Version != "(devel)" {
+		info.GitVersion = buildInfo.Main.Version
+	}
+
+	commitFromBuildInfo, modified := false, false
+	for _, setting := range buildInfo.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.GitCommit == "" {
+				info.GitCommit = setting.Value
+				commitFromBuildInfo = true
+			}
+		case "vcs.time":
+			if info.BuildDate == "" {
+				info.BuildDate = setting.Value
+			}
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if modified && commitFromBuildInfo {
+		info.GitCommit += "-dirty"
+	}
+
+	for _, dep := range buildInfo.Deps {
+		if dep.Path != gatewayAPIModulePath {
+			continue
+		}
+		info.GatewayAPIModuleVersion = dep.Version
+		if dep.Replace != nil {
+			info.GatewayAPIModuleVersion = dep.Replace.Version
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package version
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFillFromBuildInfo(t *testing.T) {
+	testCases := []struct {
+		name      string
+		info      Info
+		buildInfo *debug.BuildInfo
+		want      Info
+	}{
+		{
+			name: "values from build info",
+			buildInfo: &debug.BuildInfo{
+				Main: debug.Module{Version: "v0.1.0"},
+				Settings: []debug.BuildSetting{
+					{Key: "vcs.revision", Value: "abc123"},
+					{Key: "vcs.time", Value: "2023-07-01T00:00:00Z"},
+					{Key: "vcs.modified", Value: "true"},
+				},
+				Deps: []*debug.Module{
+					{Path: "k8s.io/api", Version: "v0.27.3"},
+					{Path: "sigs.k8s.io/gateway-api", Version: "v0.7.1"},
+				},
+			},
+			want: Info{
+				GitVersion:              "v0.1.0",
+				GitCommit:               "abc123-dirty",
+				BuildDate:               "2023-07-01T00:00:00Z",
+				GatewayAPIModuleVersion: "v0.7.1",
+			},
+		},
+		{
+			name: "values from ldflags take precedence",
+			info: Info{GitVersion: "v0.2.0", BuildDate: "2023-08-01T00:00:00Z"},
+			buildInfo: &debug.BuildInfo{
+				Main: debug.Module{Version: "(devel)"},
+				Settings: []debug.BuildSetting{
+					{Key: "vcs.revision", Value: "abc123"},
+					{Key: "vcs.time", Value: "2023-07-01T00:00:00Z"},
+				},
+			},
+			want: Info{
+				GitVersion: "v0.2.0",
+				GitCommit:  "abc123",
+				BuildDate:  "2023-08-01T00:00:00Z",
+			},
+		},
+		{
+			name: "replaced Gateway API module",
+			buildInfo: &debug.BuildInfo{
+				Main: debug.Module{Version: "(devel)"},
+				Deps: []*debug.Module{
+					{Path: "sigs.k8s.io/gateway-api", Version: "v0.7.1", Replace: &debug.Module{Path: "sigs.k8s.io/gateway-api", Version: "v0.8.0"}},
+				},
+			},
+			want: Info{
+				GatewayAPIModuleVersion: "v0.8.0",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.info
+			fillFromBuildInfo(&got, tc.buildInfo)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("fillFromBuildInfo() returned unexpected diff (-want +got)=\n%v", diff)
+			}
+		})
+	}
+}
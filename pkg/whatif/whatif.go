@@ -0,0 +1,559 @@
+// Package whatif simulates the effect of policy changes on the effective
+// policies of Gateways, HTTPRoutes and Backends, without applying the changes
+// to the cluster. Simulating the removal of a policy also gives its blast
+// radius, i.e. the resources which it affects.
+package whatif
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+	"github.com/gauravkghildiyal/gwctl/pkg/policymanager"
+	"github.com/gauravkghildiyal/gwctl/pkg/resources/backends"
+	"github.com/gauravkghildiyal/gwctl/pkg/resources/gatewayclasses"
+	"github.com/gauravkghildiyal/gwctl/pkg/resources/gateways"
+	"github.com/gauravkghildiyal/gwctl/pkg/resources/httproutes"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+)
+
+// Change is a policy which is either created (or modified), or deleted.
+type Change struct {
+	Policy unstructured.Unstructured
+	Delete bool
+}
+
+// Diff is the change in the effective policy of a single policy kind for a
+// resource.
+type Diff struct {
+	// Resource is the "<kind>/<namespace>/<name>" of the Gateway, HTTPRoute or
+	// Backend.
+	Resource string
+	// Gateway is the "<namespace>/<name>" of the Gateway through which the
+	// HTTPRoute or Backend inherits the policy. It is empty for Gateways.
+	Gateway     string
+	PolicyCrdID policymanager.PolicyCrdID
+	// Before and After are the effective specs of the policy. They are nil if
+	// the policy does not apply.
+	Before map[string]interface{}
+	After  map[string]interface{}
+}
+
+// ReadChanges decodes the policies within the YAML or JSON documents of r.
+// Policies of namespaced CRDs which do not specify a namespace get namespace.
+func ReadChanges(r io.Reader, params *types.Params, namespace string, delete bool) ([]Change, error) {
+	var result []Change
+	decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		u := unstructured.Unstructured{}
+		if err := decoder.Decode(&u.Object); err != nil {
+			if err == io.EOF {
+				return result, nil
+			}
+			return nil, fmt.Errorf("failed to decode policy: %v", err)
+		}
+		if len(u.Object) == 0 {
+			// Empty document.
+			continue
+		}
+		id := policymanager.PolicyCrdID(u.GetKind() + "." + u.GroupVersionKind().Group)
+		policyCRD, ok := params.PolicyManager.GetCRD(id)
+		if !ok {
+			return nil, fmt.Errorf("%v %q is not a policy of any known Policy CRD", u.GetKind(), u.GetName())
+		}
+		if u.GetNamespace() == "" && !policyCRD.IsClusterScoped() {
+			u.SetNamespace(namespace)
+		}
+		result = append(result, Change{Policy: u, Delete: delete})
+	}
+}
+
+// Run applies the changes to a copy of the PolicyManager and returns how the
+// effective policies of every Gateway, HTTPRoute and Backend change as a
+// result. The diffs are sorted by resource, Gateway and policy kind.
+func Run(ctx context.Context, params *types.Params, changes []Change) ([]Diff, error) {
+	simulated := *params
+	simulated.PolicyManager = params.PolicyManager.Clone()
+	for _, change := range changes {
+		policy := change.Policy
+		if change.Delete {
+			if !simulated.PolicyManager.RemovePolicy(policy) {
+				return nil, fmt.Errorf("%v %v/%v does not exist", policy.GetKind(), policy.GetNamespace(), policy.GetName())
+			}
+			continue
+		}
+		if err := simulated.PolicyManager.AddPolicy(policy); err != nil {
+			return nil, fmt.Errorf("failed to add %v %v/%v: %v", policy.GetKind(), policy.GetNamespace(), policy.GetName(), err)
+		}
+	}
+
+	before, err := effectivePolicies(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	after, err := effectivePolicies(ctx, &simulated)
+	if err != nil {
+		return nil, err
+	}
+	return diff(before, after), nil
+}
+
+// EffectivePolicy is the effective spec of a single policy kind for a
+// resource.
+type EffectivePolicy struct {
+	// Resource is the "<kind>/<namespace>/<name>" of the Gateway, HTTPRoute or
+	// Backend.
+	Resource string `json:",omitempty"`
+	// Gateway is the "<namespace>/<name>" of the Gateway through which the
+	// HTTPRoute or Backend inherits the policy. It is empty for Gateways and
+	// direct policies.
+	Gateway     string                    `json:",omitempty"`
+	PolicyCrdID policymanager.PolicyCrdID `json:",omitempty"`
+	Spec        map[string]interface{}    `json:",omitempty"`
+}
+
+// EffectivePolicies returns the effective policies of every Gateway,
+// HTTPRoute and Backend (referenced by an HTTPRoute), sorted by resource,
+// Gateway and policy kind. The effective specs are round-tripped through JSON
+// (such that all numbers are float64), so they compare equal to effective
+// specs which are read back from JSON or computed elsewhere.
+func EffectivePolicies(ctx context.Context, params *types.Params) ([]EffectivePolicy, error) {
+	policies, err := effectivePolicies(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	var result []EffectivePolicy
+	for _, key := range sortedKeys(policies) {
+		b, err := json.Marshal(policies[key])
+		if err != nil {
+			return nil, err
+		}
+		var spec map[string]interface{}
+		if err := json.Unmarshal(b, &spec); err != nil {
+			return nil, err
+		}
+		result = append(result, EffectivePolicy{
+			Resource:    key.resource,
+			Gateway:     key.gateway,
+			PolicyCrdID: key.policyCrdID,
+			Spec:        spec,
+		})
+	}
+	return result, nil
+}
+
+// DiffEffectivePolicies returns how the effective policies changed from
+// before to after, sorted by resource, Gateway and policy kind.
+func DiffEffectivePolicies(before, after []EffectivePolicy) []Diff {
+	toMap := func(policies []EffectivePolicy) map[effectivePolicyKey]map[string]interface{} {
+		result := make(map[effectivePolicyKey]map[string]interface{})
+		for _, policy := range policies {
+			result[effectivePolicyKey{policy.Resource, policy.Gateway, policy.PolicyCrdID}] = policy.Spec
+		}
+		return result
+	}
+	return diff(toMap(before), toMap(after))
+}
+
+func diff(before, after map[effectivePolicyKey]map[string]interface{}) []Diff {
+	var result []Diff
+	for _, key := range sortedKeys(union(before, after)) {
+		if !reflect.DeepEqual(before[key], after[key]) {
+			result = append(result, Diff{
+				Resource:    key.resource,
+				Gateway:     key.gateway,
+				PolicyCrdID: key.policyCrdID,
+				Before:      before[key],
+				After:       after[key],
+			})
+		}
+	}
+	return result
+}
+
+// sortedKeys returns the keys sorted by resource, Gateway and policy kind.
+func sortedKeys[V any](m map[effectivePolicyKey]V) []effectivePolicyKey {
+	var result []effectivePolicyKey
+	for key := range m {
+		result = append(result, key)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.resource != b.resource {
+			return a.resource < b.resource
+		}
+		if a.gateway != b.gateway {
+			return a.gateway < b.gateway
+		}
+		return a.policyCrdID < b.policyCrdID
+	})
+	return result
+}
+
+// AffectedResource is a resource which inherits from a policy.
+type AffectedResource struct {
+	// Resource is the "<kind>/<namespace>/<name>" of the Gateway, HTTPRoute or
+	// Backend.
+	Resource string
+	// Gateway is the "<namespace>/<name>" of the Gateway through which the
+	// HTTPRoute or Backend inherits the policy. It is empty for Gateways.
+	Gateway string
+	// Shadowed is true if the policy does not contribute to the effective
+	// policy of the resource, because policies of higher precedence override
+	// all of its values.
+	Shadowed bool
+}
+
+// Baseline is the effective policies of the cluster as is, against which
+// Affected compares. It is computed once and shared by the calls to Affected
+// for the policies of a single command.
+type Baseline struct {
+	effectivePolicies map[effectivePolicyKey]map[string]interface{}
+}
+
+// NewBaseline computes the effective policies of every Gateway, HTTPRoute and
+// Backend.
+func NewBaseline(ctx context.Context, params *types.Params) (*Baseline, error) {
+	policies, err := effectivePolicies(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &Baseline{effectivePolicies: policies}, nil
+}
+
+// Affected returns the resources affected by the policy, sorted by resource
+// and Gateway. These are the resources whose effective policy (compared to
+// the baseline) changes if the policy is removed, along with the resources
+// below the target of the policy in the hierarchy (GatewayClass, Namespace,
+// Gateway, HTTPRoute and Backend) for which the policy is shadowed.
+func Affected(ctx context.Context, params *types.Params, baseline *Baseline, policy policymanager.Policy) ([]AffectedResource, error) {
+	without := *params
+	without.PolicyManager = params.PolicyManager.Clone()
+	without.PolicyManager.RemovePolicy(*policy.Unstructured())
+
+	before := baseline.effectivePolicies
+	after, err := effectivePolicies(ctx, &without)
+	if err != nil {
+		return nil, err
+	}
+	inheriting, err := inheritingResources(ctx, params, policy.TargetRef())
+	if err != nil {
+		return nil, err
+	}
+	if policy.IsDirect() {
+		// Direct policies only apply to their exact target.
+		target := policy.TargetRef()
+		resource := fmt.Sprintf("%v/%v/%v", target.Kind, target.Namespace, target.Name)
+		inheriting = map[string]map[string]bool{resource: inheriting[resource]}
+	}
+
+	var result []AffectedResource
+	for key := range union(before, after) {
+		if key.policyCrdID != policy.PolicyCrdID() {
+			continue
+		}
+		changed := !reflect.DeepEqual(before[key], after[key])
+		inherits := inheriting[key.resource][key.gateway] || inheriting[key.resource][anyGateway]
+		if !changed && !inherits {
+			continue
+		}
+		result = append(result, AffectedResource{
+			Resource: key.resource,
+			Gateway:  key.gateway,
+			Shadowed: !changed,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		return a.Gateway < b.Gateway
+	})
+	return result, nil
+}
+
+// anyGateway is used in place of the Gateway for resources which inherit from
+// the target regardless of the Gateway they are attached to.
+const anyGateway = "*"
+
+// inheritingResources traverses downward from the target and returns the
+// "<kind>/<namespace>/<name>" of the target (if it is a Gateway, HTTPRoute or
+// Backend) and of every Gateway, HTTPRoute and Backend below it, along with
+// the Gateways through which they inherit from the target.
+func inheritingResources(ctx context.Context, params *types.Params, target policymanager.ObjRef) (map[string]map[string]bool, error) {
+	result := make(map[string]map[string]bool)
+	add := func(resource, gateway string) {
+		if result[resource] == nil {
+			result[resource] = make(map[string]bool)
+		}
+		result[resource][gateway] = true
+	}
+	addBackend := func(backendRef policymanager.ObjRef, gateway string) {
+		add(fmt.Sprintf("%v/%v/%v", backendRef.Kind, backendRef.Namespace, backendRef.Name), gateway)
+	}
+	addHTTPRoute := func(httpRoute gatewayv1beta1.HTTPRoute, gateway string) {
+		add(fmt.Sprintf("HTTPRoute/%v/%v", httpRoute.Namespace, httpRoute.Name), gateway)
+		for _, rule := range httpRoute.Spec.Rules {
+			for _, backendRef := range rule.BackendRefs {
+				objRef := policymanager.BackendRefToObjRef(backendRef.BackendObjectReference, httpRoute.Namespace)
+				if permitted, _ := params.PolicyManager.IsBackendRefPermitted(httpRoute, objRef); permitted {
+					addBackend(objRef, gateway)
+				}
+			}
+		}
+	}
+	addGateway := func(gw gatewayv1beta1.Gateway) error {
+		add(fmt.Sprintf("Gateway/%v/%v", gw.Namespace, gw.Name), "")
+		httpRoutesByListener, err := gateways.ListHTTPRoutesForListeners(ctx, params, gw)
+		if err != nil {
+			return err
+		}
+		for _, httpRoutes := range httpRoutesByListener {
+			for _, httpRoute := range httpRoutes {
+				addHTTPRoute(httpRoute, fmt.Sprintf("%v/%v", gw.Namespace, gw.Name))
+			}
+		}
+		return nil
+	}
+
+	switch {
+	case target.Group == gatewayv1beta1.GroupName && target.Kind == "GatewayClass":
+		gws, err := gatewayclasses.GetGateways(ctx, params, target.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, gw := range gws {
+			if err := addGateway(gw); err != nil {
+				return nil, err
+			}
+		}
+	case target.Group == "" && target.Kind == "Namespace":
+		gws, err := gateways.List(ctx, params, target.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, gw := range gws {
+			if err := addGateway(gw); err != nil {
+				return nil, err
+			}
+		}
+		// HTTPRoutes within the namespace, and Backends within the namespace
+		// (found through the HTTPRoutes of all namespaces), inherit through
+		// every Gateway.
+		allHTTPRoutes, err := httproutes.List(ctx, params, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, httpRoute := range allHTTPRoutes {
+			if httpRoute.Namespace == target.Name {
+				addHTTPRoute(httpRoute, anyGateway)
+				continue
+			}
+			for _, rule := range httpRoute.Spec.Rules {
+				for _, backendRef := range rule.BackendRefs {
+					objRef := policymanager.BackendRefToObjRef(backendRef.BackendObjectReference, httpRoute.Namespace)
+					if objRef.Namespace != target.Name {
+						continue
+					}
+					if permitted, _ := params.PolicyManager.IsBackendRefPermitted(httpRoute, objRef); permitted {
+						addBackend(objRef, anyGateway)
+					}
+				}
+			}
+		}
+	case target.Group == gatewayv1beta1.GroupName && target.Kind == "Gateway":
+		gw, err := gateways.Get(ctx, params, target.Namespace, target.Name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return result, nil
+			}
+			return nil, err
+		}
+		if err := addGateway(gw); err != nil {
+			return nil, err
+		}
+	case target.Group == gatewayv1beta1.GroupName && target.Kind == "HTTPRoute":
+		httpRoute, err := httproutes.Get(ctx, params, target.Namespace, target.Name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return result, nil
+			}
+			return nil, err
+		}
+		addHTTPRoute(httpRoute, anyGateway)
+	default:
+		// Any other kind is treated as a Backend.
+		addBackend(target, anyGateway)
+	}
+	return result, nil
+}
+
+type effectivePolicyKey struct {
+	resource    string
+	gateway     string
+	policyCrdID policymanager.PolicyCrdID
+}
+
+// effectivePolicies returns the effective spec of each policy kind applying to
+// every Gateway, HTTPRoute and Backend (referenced by an HTTPRoute). Direct
+// policies apply regardless of the Gateway, so they have no Gateway.
+func effectivePolicies(ctx context.Context, params *types.Params) (map[effectivePolicyKey]map[string]interface{}, error) {
+	result := make(map[effectivePolicyKey]map[string]interface{})
+	add := func(resource, gateway string, policies map[policymanager.PolicyCrdID]policymanager.Policy) error {
+		for policyCrdID, policy := range policies {
+			spec, err := policy.EffectiveSpec()
+			if err != nil {
+				return err
+			}
+			result[effectivePolicyKey{resource, gateway, policyCrdID}] = spec
+		}
+		return nil
+	}
+
+	gws, err := gateways.List(ctx, params, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, gw := range gws {
+		policies, err := gateways.GetEffectivePolicies(ctx, params, gw)
+		if err != nil {
+			return nil, err
+		}
+		if err := add(fmt.Sprintf("Gateway/%v/%v", gw.Namespace, gw.Name), "", policies); err != nil {
+			return nil, err
+		}
+		directPolicies, err := gateways.GetDirectPolicies(ctx, params, gw.Namespace, gw.Name)
+		if err != nil {
+			return nil, err
+		}
+		if err := add(fmt.Sprintf("Gateway/%v/%v", gw.Namespace, gw.Name), "", directPolicies); err != nil {
+			return nil, err
+		}
+	}
+
+	httpRoutes, err := httproutes.List(ctx, params, "")
+	if err != nil {
+		return nil, err
+	}
+	backendRefs := make(map[policymanager.ObjRef]bool)
+	for _, httpRoute := range httpRoutes {
+		policiesByGateway, err := httproutes.GetEffectivePolicies(ctx, params, httpRoute)
+		if err != nil {
+			return nil, err
+		}
+		for gateway, policies := range policiesByGateway {
+			if err := add(fmt.Sprintf("HTTPRoute/%v/%v", httpRoute.Namespace, httpRoute.Name), gateway, policies); err != nil {
+				return nil, err
+			}
+		}
+		directPolicies, err := httproutes.GetDirectPolicies(ctx, params, httpRoute.Namespace, httpRoute.Name)
+		if err != nil {
+			return nil, err
+		}
+		if err := add(fmt.Sprintf("HTTPRoute/%v/%v", httpRoute.Namespace, httpRoute.Name), "", directPolicies); err != nil {
+			return nil, err
+		}
+
+		for _, rule := range httpRoute.Spec.Rules {
+			for _, backendRef := range rule.BackendRefs {
+				objRef := policymanager.BackendRefToObjRef(backendRef.BackendObjectReference, httpRoute.Namespace)
+				if permitted, _ := params.PolicyManager.IsBackendRefPermitted(httpRoute, objRef); permitted {
+					backendRefs[objRef] = true
+				}
+			}
+		}
+	}
+
+	for backendRef := range backendRefs {
+		// Only the group, kind, namespace and name of the backend are needed to
+		// calculate its effective policies, so the backend is not fetched.
+		backend := unstructured.Unstructured{}
+		backend.SetGroupVersionKind(schema.GroupVersionKind{Group: backendRef.Group, Kind: backendRef.Kind})
+		backend.SetNamespace(backendRef.Namespace)
+		backend.SetName(backendRef.Name)
+
+		policiesByGateway, err := backends.GetEffectivePolicies(ctx, params, backend, httpRoutes)
+		if err != nil {
+			return nil, err
+		}
+		for gateway, policies := range policiesByGateway {
+			if err := add(fmt.Sprintf("%v/%v/%v", backendRef.Kind, backendRef.Namespace, backendRef.Name), gateway, policies); err != nil {
+				return nil, err
+			}
+		}
+		directPolicies, err := backends.GetDirectPolicies(ctx, params, backend)
+		if err != nil {
+			return nil, err
+		}
+		if err := add(fmt.Sprintf("%v/%v/%v", backendRef.Kind, backendRef.Namespace, backendRef.Name), "", directPolicies); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func union(a, b map[effectivePolicyKey]map[string]interface{}) map[effectivePolicyKey]bool {
+	result := make(map[effectivePolicyKey]bool)
+	for key := range a {
+		result[key] = true
+	}
+	for key := range b {
+		result[key] = true
+	}
+	return result
+}
+
+type resourceView struct {
+	Resource string           `json:",omitempty"`
+	Changes  []policyDiffView `json:",omitempty"`
+}
+
+type policyDiffView struct {
+	Gateway string                    `json:",omitempty"`
+	Policy  policymanager.PolicyCrdID `json:",omitempty"`
+	Before  map[string]interface{}    `json:",omitempty"`
+	After   map[string]interface{}    `json:",omitempty"`
+}
+
+// Print prints the diffs grouped by resource. A policy which starts (or stops)
+// applying to a resource has no Before (or After).
+func Print(params *types.Params, diffs []Diff) {
+	// The JSON output is an empty list instead of the message, such that it
+	// can always be parsed.
+	if len(diffs) == 0 && params.Output != "json" {
+		fmt.Fprintln(params.Out, "No effective policies change")
+		return
+	}
+
+	views := []resourceView{}
+	for _, diff := range diffs {
+		if len(views) == 0 || views[len(views)-1].Resource != diff.Resource {
+			views = append(views, resourceView{Resource: diff.Resource})
+		}
+		view := &views[len(views)-1]
+		view.Changes = append(view.Changes, policyDiffView{
+			Gateway: diff.Gateway,
+			Policy:  diff.PolicyCrdID,
+			Before:  diff.Before,
+			After:   diff.After,
+		})
+	}
+
+	b, err := common.Marshal(params.Output, views)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Fprint(params.Out, string(b))
+}
@@ -0,0 +1,554 @@
+package whatif
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/gauravkghildiyal/gwctl/pkg/common"
+	"github.com/gauravkghildiyal/gwctl/pkg/policymanager"
+	"github.com/gauravkghildiyal/gwctl/pkg/types"
+)
+
+func TestRun(t *testing.T) {
+	objects := []runtime.Object{
+		&gatewayv1beta1.GatewayClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "foo-gatewayclass",
+			},
+			Spec: gatewayv1beta1.GatewayClassSpec{
+				ControllerName: "example.net/gateway-controller",
+			},
+		},
+		&gatewayv1beta1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-gateway",
+				Namespace: "default",
+			},
+			Spec: gatewayv1beta1.GatewaySpec{
+				GatewayClassName: "foo-gatewayclass",
+				Listeners:        []gatewayv1beta1.Listener{{Name: "http", Port: 80, Protocol: gatewayv1beta1.HTTPProtocolType}},
+			},
+		},
+		&gatewayv1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-httproute",
+				Namespace: "default",
+			},
+			Spec: gatewayv1beta1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
+					ParentRefs: []gatewayv1beta1.ParentReference{{Name: "foo-gateway"}},
+				},
+				Rules: []gatewayv1beta1.HTTPRouteRule{{
+					BackendRefs: []gatewayv1beta1.HTTPBackendRef{{
+						BackendRef: gatewayv1beta1.BackendRef{
+							BackendObjectReference: gatewayv1beta1.BackendObjectReference{Name: "foo-svc"},
+						},
+					}},
+				}},
+			},
+		},
+
+		&apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "healthcheckpolicies.foo.com",
+				Labels: map[string]string{
+					common.GatewayPolicyLabelKey: "inherited",
+				},
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Scope:    apiextensionsv1.ClusterScoped,
+				Group:    "foo.com",
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1"}},
+				Names: apiextensionsv1.CustomResourceDefinitionNames{
+					Plural: "healthcheckpolicies",
+					Kind:   "HealthCheckPolicy",
+				},
+			},
+		},
+		&unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "foo.com/v1",
+				"kind":       "HealthCheckPolicy",
+				"metadata": map[string]interface{}{
+					"name": "health-check-gateway",
+				},
+				"spec": map[string]interface{}{
+					"default": map[string]interface{}{
+						"key1": "value-gateway-1",
+						"key2": "value-gateway-2",
+					},
+					"targetRef": map[string]interface{}{
+						"group":     "gateway.networking.k8s.io",
+						"kind":      "Gateway",
+						"name":      "foo-gateway",
+						"namespace": "default",
+					},
+				},
+			},
+		},
+
+		&apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "timeoutpolicies.bar.com",
+				Labels: map[string]string{
+					common.GatewayPolicyLabelKey: "direct",
+				},
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Scope:    apiextensionsv1.NamespaceScoped,
+				Group:    "bar.com",
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1"}},
+				Names: apiextensionsv1.CustomResourceDefinitionNames{
+					Plural: "timeoutpolicies",
+					Kind:   "TimeoutPolicy",
+				},
+			},
+		},
+		&unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "bar.com/v1",
+				"kind":       "TimeoutPolicy",
+				"metadata": map[string]interface{}{
+					"name":      "timeout-httproute",
+					"namespace": "default",
+				},
+				"spec": map[string]interface{}{
+					"seconds": int64(30),
+					"targetRef": map[string]interface{}{
+						"group": "gateway.networking.k8s.io",
+						"kind":  "HTTPRoute",
+						"name":  "foo-httproute",
+					},
+				},
+			},
+		},
+	}
+
+	params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
+
+	// The HTTPRoute overrides one of the defaults of the Gateway, and the
+	// TimeoutPolicy is moved from the HTTPRoute to the Service. The namespace
+	// of the namespaced TimeoutPolicy is defaulted.
+	created, err := ReadChanges(strings.NewReader(`
+apiVersion: foo.com/v1
+kind: HealthCheckPolicy
+metadata:
+  name: health-check-httproute
+spec:
+  override:
+    key1: value-httproute-1
+  targetRef:
+    group: gateway.networking.k8s.io
+    kind: HTTPRoute
+    name: foo-httproute
+    namespace: default
+---
+apiVersion: bar.com/v1
+kind: TimeoutPolicy
+metadata:
+  name: timeout-service
+spec:
+  seconds: 60
+  targetRef:
+    kind: Service
+    name: foo-svc
+`), params, "default", false)
+	if err != nil {
+		t.Fatalf("ReadChanges() failed: %v", err)
+	}
+	deleted, err := ReadChanges(strings.NewReader(`
+apiVersion: bar.com/v1
+kind: TimeoutPolicy
+metadata:
+  name: timeout-httproute
+`), params, "default", true)
+	if err != nil {
+		t.Fatalf("ReadChanges() failed: %v", err)
+	}
+
+	diffs, err := Run(context.Background(), params, append(created, deleted...))
+	if err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	Print(params, diffs)
+
+	got := params.Out.(*bytes.Buffer).String()
+	want := `
+- Changes:
+  - Before:
+      seconds: 30
+    Policy: TimeoutPolicy.bar.com
+  - After:
+      key1: value-httproute-1
+      key2: value-gateway-2
+    Before:
+      key1: value-gateway-1
+      key2: value-gateway-2
+    Gateway: default/foo-gateway
+    Policy: HealthCheckPolicy.foo.com
+  Resource: HTTPRoute/default/foo-httproute
+- Changes:
+  - After:
+      seconds: 60
+    Policy: TimeoutPolicy.bar.com
+  - After:
+      key1: value-httproute-1
+      key2: value-gateway-2
+    Before:
+      key1: value-gateway-1
+      key2: value-gateway-2
+    Gateway: default/foo-gateway
+    Policy: HealthCheckPolicy.foo.com
+  Resource: Service/default/foo-svc
+`
+	if diff := cmp.Diff(common.YamlString(want), common.YamlString(got), common.YamlStringTransformer); diff != "" {
+		t.Errorf("Unexpected diff\ngot=\n%v\nwant=\n%v\ndiff (-want +got)=\n%v", got, want, diff)
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	params := types.MustParamsForTest(t, common.MustClientsForTest(t))
+
+	if _, err := ReadChanges(strings.NewReader("apiVersion: foo.com/v1\nkind: UnknownPolicy\n"), params, "default", false); err == nil {
+		t.Errorf("ReadChanges() of a policy without a Policy CRD succeeded; want error")
+	}
+
+	nonExistent := unstructured.Unstructured{}
+	nonExistent.SetAPIVersion("foo.com/v1")
+	nonExistent.SetKind("HealthCheckPolicy")
+	nonExistent.SetName("foo")
+	if _, err := Run(context.Background(), params, []Change{{Policy: nonExistent, Delete: true}}); err == nil {
+		t.Errorf("Run() deleting a non-existent policy succeeded; want error")
+	}
+}
+
+func TestPrint_JSON(t *testing.T) {
+	testCases := []struct {
+		name  string
+		diffs []Diff
+		want  string
+	}{
+		{
+			name: "no changes",
+			want: "[]\n",
+		},
+		{
+			name: "changes",
+			diffs: []Diff{{
+				Resource:    "Gateway/default/foo-gateway",
+				PolicyCrdID: "TimeoutPolicy.foo.com",
+				After:       map[string]interface{}{"seconds": 60},
+			}},
+			want: `[
+  {
+    "Resource": "Gateway/default/foo-gateway",
+    "Changes": [
+      {
+        "Policy": "TimeoutPolicy.foo.com",
+        "After": {
+          "seconds": 60
+        }
+      }
+    ]
+  }
+]
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			params := types.MustParamsForTest(t, common.MustClientsForTest(t))
+			params.Output = "json"
+			Print(params, tc.diffs)
+			if diff := cmp.Diff(tc.want, params.Out.(*bytes.Buffer).String()); diff != "" {
+				t.Errorf("Print() returned unexpected diff (-want +got)=\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestRun_Precedence(t *testing.T) {
+	// policy returns a cluster scoped policy of the kind targeting
+	// foo-gateway, with the creation timestamp unless empty.
+	policy := func(kind, name, creationTimestamp string, defaults map[string]interface{}) *unstructured.Unstructured {
+		metadata := map[string]interface{}{
+			"name": name,
+		}
+		if creationTimestamp != "" {
+			metadata["creationTimestamp"] = creationTimestamp
+		}
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "foo.com/v1",
+				"kind":       kind,
+				"metadata":   metadata,
+				"spec": map[string]interface{}{
+					"default": defaults,
+					"targetRef": map[string]interface{}{
+						"group":     "gateway.networking.k8s.io",
+						"kind":      "Gateway",
+						"name":      "foo-gateway",
+						"namespace": "default",
+					},
+				},
+			},
+		}
+	}
+	policyCRD := func(plural, kind string) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: plural + ".foo.com",
+				Labels: map[string]string{
+					common.GatewayPolicyLabelKey: "inherited",
+				},
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Scope:    apiextensionsv1.ClusterScoped,
+				Group:    "foo.com",
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1"}},
+				Names: apiextensionsv1.CustomResourceDefinitionNames{
+					Plural: plural,
+					Kind:   kind,
+				},
+			},
+		}
+	}
+
+	objects := []runtime.Object{
+		&gatewayv1beta1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-gateway",
+				Namespace: "default",
+			},
+			Spec: gatewayv1beta1.GatewaySpec{
+				GatewayClassName: "foo-gatewayclass",
+			},
+		},
+		policyCRD("healthcheckpolicies", "HealthCheckPolicy"),
+		policyCRD("retrypolicies", "RetryPolicy"),
+		// The older policy takes precedence over the newer one.
+		policy("HealthCheckPolicy", "health-check-old", "2020-01-01T00:00:00Z", map[string]interface{}{"key1": "value-old"}),
+		policy("HealthCheckPolicy", "health-check-new", "2021-01-01T00:00:00Z", map[string]interface{}{"key1": "value-new"}),
+		// A policy of another kind may have the same name.
+		policy("RetryPolicy", "health-check-old", "2020-01-01T00:00:00Z", map[string]interface{}{"attempts": int64(3)}),
+	}
+	params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
+
+	testCases := []struct {
+		name    string
+		changes []Change
+		want    []Diff
+	}{
+		{
+			name: "new conflicting policy does not take precedence over existing ones",
+			changes: []Change{
+				{Policy: *policy("HealthCheckPolicy", "health-check-added", "", map[string]interface{}{"key1": "value-added"})},
+			},
+		},
+		{
+			name: "modified policy keeps its precedence",
+			changes: []Change{
+				{Policy: *policy("HealthCheckPolicy", "health-check-new", "", map[string]interface{}{"key1": "value-modified", "key2": "value-modified"})},
+			},
+			want: []Diff{{
+				Resource:    "Gateway/default/foo-gateway",
+				PolicyCrdID: "HealthCheckPolicy.foo.com",
+				Before:      map[string]interface{}{"key1": "value-old"},
+				After:       map[string]interface{}{"key1": "value-old", "key2": "value-modified"},
+			}},
+		},
+		{
+			name: "policy with the name of a policy of another kind only replaces the policy of its kind",
+			changes: []Change{
+				{Policy: *policy("RetryPolicy", "health-check-old", "", map[string]interface{}{"attempts": int64(5)})},
+			},
+			want: []Diff{{
+				Resource:    "Gateway/default/foo-gateway",
+				PolicyCrdID: "RetryPolicy.foo.com",
+				Before:      map[string]interface{}{"attempts": float64(3)},
+				After:       map[string]interface{}{"attempts": float64(5)},
+			}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			diffs, err := Run(context.Background(), params, tc.changes)
+			if err != nil {
+				t.Fatalf("Run() failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, diffs); diff != "" {
+				t.Errorf("Run() returned unexpected diff (-want +got)=\n%v", diff)
+			}
+		})
+	}
+
+}
+
+func TestAffected(t *testing.T) {
+	healthCheckPolicy := func(name string, defaults map[string]interface{}, targetRef map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "foo.com/v1",
+				"kind":       "HealthCheckPolicy",
+				"metadata": map[string]interface{}{
+					"name": name,
+				},
+				"spec": map[string]interface{}{
+					"default":   defaults,
+					"targetRef": targetRef,
+				},
+			},
+		}
+	}
+	httpRoute := func(name string, gateways ...string) *gatewayv1beta1.HTTPRoute {
+		result := &gatewayv1beta1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+		}
+		for _, gw := range gateways {
+			result.Spec.ParentRefs = append(result.Spec.ParentRefs, gatewayv1beta1.ParentReference{Name: gatewayv1beta1.ObjectName(gw)})
+		}
+		return result
+	}
+	gateway := func(name, gatewayClassName string) *gatewayv1beta1.Gateway {
+		return &gatewayv1beta1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: gatewayv1beta1.GatewaySpec{
+				GatewayClassName: gatewayv1beta1.ObjectName(gatewayClassName),
+				Listeners:        []gatewayv1beta1.Listener{{Name: "http", Port: 80, Protocol: gatewayv1beta1.HTTPProtocolType}},
+			},
+		}
+	}
+
+	fooHTTPRoute := httpRoute("foo-httproute", "foo-gateway", "bar-gateway")
+	fooHTTPRoute.Spec.Rules = []gatewayv1beta1.HTTPRouteRule{{
+		BackendRefs: []gatewayv1beta1.HTTPBackendRef{{
+			BackendRef: gatewayv1beta1.BackendRef{
+				BackendObjectReference: gatewayv1beta1.BackendObjectReference{Name: "foo-svc"},
+			},
+		}},
+	}}
+
+	objects := []runtime.Object{
+		&gatewayv1beta1.GatewayClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo-gatewayclass"},
+			Spec:       gatewayv1beta1.GatewayClassSpec{ControllerName: "example.net/gateway-controller"},
+		},
+		&gatewayv1beta1.GatewayClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "bar-gatewayclass"},
+			Spec:       gatewayv1beta1.GatewayClassSpec{ControllerName: "example.net/gateway-controller"},
+		},
+		gateway("foo-gateway", "foo-gatewayclass"),
+		gateway("bar-gateway", "bar-gatewayclass"),
+		// foo-httproute inherits from the GatewayClass only through foo-gateway.
+		fooHTTPRoute,
+		// bar-httproute has its own default, which shadows the one of the
+		// GatewayClass.
+		httpRoute("bar-httproute", "foo-gateway"),
+
+		&apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "healthcheckpolicies.foo.com",
+				Labels: map[string]string{
+					common.GatewayPolicyLabelKey: "inherited",
+				},
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Scope:    apiextensionsv1.ClusterScoped,
+				Group:    "foo.com",
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1"}},
+				Names: apiextensionsv1.CustomResourceDefinitionNames{
+					Plural: "healthcheckpolicies",
+					Kind:   "HealthCheckPolicy",
+				},
+			},
+		},
+		healthCheckPolicy("health-check-gatewayclass",
+			map[string]interface{}{"key1": "value-gatewayclass-1"},
+			map[string]interface{}{"group": "gateway.networking.k8s.io", "kind": "GatewayClass", "name": "foo-gatewayclass"},
+		),
+		healthCheckPolicy("health-check-httproute",
+			map[string]interface{}{"key1": "value-httproute-1"},
+			map[string]interface{}{"group": "gateway.networking.k8s.io", "kind": "HTTPRoute", "name": "bar-httproute", "namespace": "default"},
+		),
+
+		&apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "timeoutpolicies.bar.com",
+				Labels: map[string]string{
+					common.GatewayPolicyLabelKey: "direct",
+				},
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Scope:    apiextensionsv1.ClusterScoped,
+				Group:    "bar.com",
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1"}},
+				Names: apiextensionsv1.CustomResourceDefinitionNames{
+					Plural: "timeoutpolicies",
+					Kind:   "TimeoutPolicy",
+				},
+			},
+		},
+		// The direct policy does not apply to the HTTPRoutes of the Gateway.
+		&unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "bar.com/v1",
+				"kind":       "TimeoutPolicy",
+				"metadata": map[string]interface{}{
+					"name": "timeout-gateway",
+				},
+				"spec": map[string]interface{}{
+					"seconds":   int64(30),
+					"targetRef": map[string]interface{}{"group": "gateway.networking.k8s.io", "kind": "Gateway", "name": "foo-gateway", "namespace": "default"},
+				},
+			},
+		},
+	}
+
+	params := types.MustParamsForTest(t, common.MustClientsForTest(t, objects...))
+	policies := make(map[string]policymanager.Policy)
+	for _, p := range params.PolicyManager.GetPolicies() {
+		policies[p.Unstructured().GetName()] = p
+	}
+
+	baseline, err := NewBaseline(context.Background(), params)
+	if err != nil {
+		t.Fatalf("NewBaseline() failed: %v", err)
+	}
+	got, err := Affected(context.Background(), params, baseline, policies["timeout-gateway"])
+	if err != nil {
+		t.Fatalf("Affected() failed: %v", err)
+	}
+	want := []AffectedResource{{Resource: "Gateway/default/foo-gateway"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Affected() of direct policy returned unexpected diff (-want +got):\n%v", diff)
+	}
+
+	got, err = Affected(context.Background(), params, baseline, policies["health-check-gatewayclass"])
+	if err != nil {
+		t.Fatalf("Affected() failed: %v", err)
+	}
+	want = []AffectedResource{
+		{Resource: "Gateway/default/foo-gateway"},
+		{Resource: "HTTPRoute/default/bar-httproute", Gateway: "default/foo-gateway", Shadowed: true},
+		{Resource: "HTTPRoute/default/foo-httproute", Gateway: "default/foo-gateway"},
+		{Resource: "Service/default/foo-svc", Gateway: "default/foo-gateway"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Affected() returned unexpected diff (-want +got):\n%v", diff)
+	}
+}